@@ -0,0 +1,136 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aura-studio/lad/internal/audit"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/config"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// auditLogNone 表示不记录审计日志
+const auditLogNone = "none"
+
+// auditSinks 根据 --audit-log 创建审计日志输出
+// 优先级: --audit-log > samconfig.toml 的 [env.lad.parameters] audit_log > 默认 .lad/audit.log
+// 返回: 输出（指定 none 时为空）, 错误
+func auditSinks() ([]audit.Sink, error) {
+	specs := auditLog
+	if len(specs) == 0 {
+		if samConfig, err := config.LoadSAMConfig(samconfigPath); err == nil {
+			specs = samConfig.GetAuditLog(env)
+		}
+	}
+	if len(specs) == 0 {
+		specs = []string{"file:" + filepath.Join(recordDir, "audit.log")}
+	}
+
+	var sinks []audit.Sink
+	for _, spec := range specs {
+		if spec == auditLogNone {
+			if len(specs) > 1 {
+				return nil, fmt.Errorf("审计日志输出 none 不能与其他输出同时指定")
+			}
+			return nil, nil
+		}
+		sink, err := audit.ParseSink(spec)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// beginAudit 开始记录命令的审计日志，在获取部署锁之后、修改别名之前调用
+// 记录执行前的别名状态（lambdaClient 为 nil 时不读取别名，例如 patch），命令结束时记录执行后的别名状态和退出码
+// 命令通过 exitFunc 退出时同样会写入；命令正常结束时调用方需 defer done()
+// 返回: 结束函数, 是否成功
+func beginAudit(ctx context.Context, c *cobra.Command, lambdaClient *aws.Client, functionName string) (func(), bool) {
+	sinks, err := auditSinks()
+	if err != nil {
+		HandleParamError(err)
+		return nil, false
+	}
+	if len(sinks) == 0 {
+		return func() {}, true
+	}
+
+	record := &audit.Record{
+		StartedAt:  time.Now(),
+		Command:    strings.TrimPrefix(c.CommandPath(), rootCmd.Name()+" "),
+		Flags:      changedFlags(c),
+		Operator:   currentOperator(),
+		LadVersion: getVersion(),
+	}
+	if host, err := os.Hostname(); err == nil {
+		record.Host = host
+	}
+	if f := c.Flags().Lookup("reason"); f != nil {
+		record.Reason = f.Value.String()
+	}
+	if lambdaClient != nil {
+		record.Function = functionName
+		record.Env = env
+		record.Profile = GetProfile(env)
+		record.Region = lambdaClient.Region()
+		record.Before = auditAliases(ctx, lambdaClient, functionName)
+	}
+
+	done := deferExit(func(code int) {
+		record.Timestamp = time.Now()
+		record.ExitCode = code
+		record.Outcome = audit.Success
+		if code != exitcode.Success {
+			record.Outcome = audit.Failure
+		}
+		// 命令的 ctx 可能已被取消，使用新的 ctx 读取执行后的状态并写入
+		if lambdaClient != nil {
+			record.After = auditAliases(context.Background(), lambdaClient, functionName)
+		}
+		if err := audit.Write(context.Background(), sinks, record); err != nil {
+			output.Warning("写入审计日志失败: %v", err)
+		}
+	})
+	return done, true
+}
+
+// auditAliases 读取受管别名的状态，无法读取的别名（例如尚未创建）不包含在结果中
+func auditAliases(ctx context.Context, lambdaClient *aws.Client, functionName string) map[string]audit.Alias {
+	aliases := make(map[string]audit.Alias)
+	for _, name := range managedAliases {
+		state, err := lambdaClient.GetAliasState(ctx, functionName, name)
+		if err != nil {
+			continue
+		}
+		aliases[name] = audit.Alias{
+			Version:     state.Version,
+			Weights:     state.Weights,
+			Description: state.Description,
+		}
+	}
+	return aliases
+}
+
+// changedFlags 返回命令行中指定的选项及其值
+// --audit-log 不记录，webhook 地址中可能包含令牌
+func changedFlags(c *cobra.Command) map[string]string {
+	flags := make(map[string]string)
+	c.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "audit-log" {
+			return
+		}
+		flags[f.Name] = f.Value.String()
+	})
+	return flags
+}
@@ -3,50 +3,138 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/config"
 	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/health"
+	"github.com/aura-studio/lad/internal/lock"
 	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/state"
+	"github.com/aura-studio/lad/internal/strategy"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// auto 命令选项
-	autoPercent int
-	autoWait    time.Duration
+	autoPercent       int
+	autoWait          time.Duration
+	autoStrategy      string
+	autoDryRun        bool
+	autoResume        bool
+	autoRestart       bool
+	autoAlarms        []string
+	autoMetrics       []string
+	autoMetricPeriod  time.Duration
+	autoCheckInterval time.Duration
+	autoOnInterrupt   string
+)
+
+// auto 被中断（SIGINT、SIGTERM）时对当前灰度的处理方式
+const (
+	// interruptHold 保持当前灰度比例，之后可以通过 --resume 继续
+	interruptHold = "hold"
+	// interruptRevert 清除灰度配置（恢复为 0%），latest 保持新版本，之后可以重新执行 auto
+	interruptRevert = "revert"
+	// interruptRollback 与 lad rollback 取消灰度相同，重置 latest 并标记新版本为取消发布
+	interruptRollback = "rollback"
+)
+
+// interruptTimeout 是中断后处理灰度（修改别名、写入日志）的最长时间
+const interruptTimeout = 30 * time.Second
+
+var (
+	// errAutoAborted 表示收到 lad auto abort 请求
+	errAutoAborted = errors.New("收到 lad auto abort 请求")
+	// errAutoStopped 表示已经输出错误并退出（例如部署锁丢失），调用方直接返回
+	errAutoStopped = errors.New("auto 已停止")
+	// errAutoResumeFailed 表示收到恢复请求后写入进度失败，auto 保持暂停并停止
+	errAutoResumeFailed = errors.New("收到恢复请求，但写入 live 别名失败")
 )
 
 var autoCmd = &cobra.Command{
 	Use:   "auto",
 	Short: "自动递进灰度发布",
-	Long: `自动递进灰度发布，按指定步长逐步增加流量到新版本。
+	Long: `自动递进灰度发布，按流量切换策略逐步增加流量到新版本。
 
 该命令会执行以下操作：
-1. 获取 live 和 latest 别名的版本
-2. 按 --percent 指定的步长递增灰度比例
-3. 每个阶段等待 --wait 指定的时间
+1. 获取别名版本并检查部署状态，输出执行计划，等待 latest 版本就绪
+2. 按策略的每一步设置灰度比例，并在 live 别名的描述中记录进度
+3. 每一步等待策略指定的时间，指定了 --alarm 或 --metric 时等待期间持续检查
 4. 达到 100% 后执行 promote 完成切换
 
+流量切换策略：
+  默认按 --percent 指定的步长递增，每步等待 --wait；也可以通过 --strategy 或
+  samconfig.toml 的 auto_strategy 指定（--strategy 不能与 --percent、--wait 同时使用）：
+  1%:10m,5%:10m,25%:30m,50%:1h   步骤列表，每一步的比例和等待时间
+  linear:10%:5m                  每次增加 10%，每步等待 5 分钟
+  exponential:1%:10m[:2]         1% → 2% → 4% → ... 每步乘以倍数（默认 2）
+  Canary10Percent5Minutes        CodeDeploy 预设: 10% 等待 5 分钟后切换 100%
+  Linear10PercentEvery1Minute    CodeDeploy 预设: 每分钟增加 10%
+  AllAtOnce                      CodeDeploy 预设: 直接切换 100%
+
+中断后继续：
+  灰度中执行 auto 不会降低灰度比例: 跳过不高于当前比例的步骤，先在当前比例下等待完整时间
+  --resume 按 live 别名描述中记录的进度继续，使用记录的策略并只等待当前步骤剩余的时间
+  --restart 从第一步重新开始，可能降低当前灰度比例
+
+健康检查：
+  --alarm 指定 CloudWatch 告警，任意告警进入 ALARM 状态即失败
+  --metric 指定新版本的指标阈值，格式为 指标名[:统计方式]运算符阈值（统计方式默认 Sum），
+  查询 AWS/Lambda 命名空间中 FunctionName、Resource（函数名:live）和 ExecutedVersion 维度的数据
+  检查失败时清除灰度配置、重置 latest 并记录回退日志，以退出码 11 退出
+
+中断处理：
+  收到 SIGINT (Ctrl-C) 或 SIGTERM 时按 --on-interrupt 或 samconfig.toml 的 auto_on_interrupt 处理当前灰度：
+  hold (默认) 保持当前比例，revert 清除灰度配置，rollback 取消灰度并标记新版本为取消发布
+  处理后记录回退日志，输出继续执行的命令，以退出码 12 退出；promote 开始后不再响应中断
+
+暂停和取消：
+  在其他终端执行 lad auto pause|resume|abort，请求写入 live 别名描述中的进度，运行中的 auto
+  每个 --check-interval 读取一次。暂停期间保持当前比例并继续健康检查，暂停时间不计入等待时间；
+  abort 与 lad rollback 取消灰度相同，以退出码 12 退出
+
 示例：
   lad auto --percent 10 --wait 5m   # 每次增加 10%，每阶段等待 5 分钟
                                      # 10% → 20% → 30% → ... → 100%
   
   lad auto --percent 25 --wait 1h   # 每次增加 25%，每阶段等待 1 小时
-                                     # 25% → 50% → 75% → 100%`,
-	Run: runAuto,
+                                     # 25% → 50% → 75% → 100%
+
+  lad auto --strategy 1%:10m,5%:10m,25%:30m,50%:1h
+  lad auto --strategy Canary10Percent5Minutes --dry-run   # 只输出执行计划
+  lad auto --resume                  # 继续被中断的 auto
+  lad auto pause / lad auto resume   # 暂停、恢复运行中的 auto
+
+  lad auto --alarm api-errors --metric "Errors>0" --metric "Duration:p99>=1000"
+                                     # 每 30 秒检查告警和新版本的错误数、p99 延迟`,
+	// auto 有 pause|resume|abort 子命令，拼错的子命令不能被当作参数忽略后执行灰度
+	Args: cobra.NoArgs,
+	Run:  runAuto,
 }
 
 func init() {
 	autoCmd.Flags().IntVar(&autoPercent, "percent", 10, "每次增加的灰度百分比 (1-100)")
 	autoCmd.Flags().DurationVar(&autoWait, "wait", 5*time.Minute, "每个灰度阶段的等待时间")
+	autoCmd.Flags().StringVar(&autoStrategy, "strategy", "", "流量切换策略 (步骤列表、linear:、exponential: 或 CodeDeploy 预设)")
+	autoCmd.Flags().BoolVar(&autoDryRun, "dry-run", false, "只输出执行计划，不修改别名")
+	autoCmd.Flags().BoolVar(&autoResume, "resume", false, "按 live 别名中记录的进度继续中断的 auto")
+	autoCmd.Flags().BoolVar(&autoRestart, "restart", false, "灰度中从第一步重新开始 (可能降低当前灰度比例)")
+	autoCmd.Flags().StringSliceVar(&autoAlarms, "alarm", nil, "灰度期间检查的 CloudWatch 告警，可重复指定或以逗号分隔")
+	autoCmd.Flags().StringArrayVar(&autoMetrics, "metric", nil, "新版本的指标阈值，例如 Errors>0、Duration:p99>=1000，可重复指定")
+	autoCmd.Flags().DurationVar(&autoMetricPeriod, "metric-period", time.Minute, "指标阈值的统计周期 (1 分钟的整数倍)")
+	autoCmd.Flags().DurationVar(&autoCheckInterval, "check-interval", 30*time.Second, "等待期间检查健康状态和控制请求 (lad auto pause 等) 的间隔")
+	autoCmd.Flags().StringVar(&autoOnInterrupt, "on-interrupt", "", "被中断时对当前灰度的处理 (hold|revert|rollback，默认 hold)")
 	rootCmd.AddCommand(autoCmd)
 }
 
 func runAuto(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
+	ctx := cmd.Context()
 
 	// 1. 验证环境参数
 	if err := ValidateEnv(env); err != nil {
@@ -54,9 +142,22 @@ func runAuto(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	// 2. 验证 --percent 参数
-	if autoPercent < 1 || autoPercent > 100 {
-		HandleParamError(fmt.Errorf("无效的百分比 '%d'，有效范围为 1-100", autoPercent))
+	// 2. 确定执行计划
+	plan, source, err := autoPlan(cmd)
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 验证健康检查参数和中断处理方式
+	thresholds, err := parseHealthChecks()
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+	onInterrupt, err := autoInterruptPolicy(cmd)
+	if err != nil {
+		HandleParamError(err)
 		return
 	}
 
@@ -70,53 +171,79 @@ func runAuto(cmd *cobra.Command, args []string) {
 	// 4. 获取 AWS Profile
 	awsProfile := GetProfile(env)
 
-	// 5. 计算灰度步骤
-	var steps []int
-	for pct := autoPercent; pct < 100; pct += autoPercent {
-		steps = append(steps, pct)
-	}
-	// 确保最后一步是 100%（由 promote 完成）
-
 	output.Info("开始自动灰度发布...")
 	output.Info("环境: %s", env)
 	output.Info("函数: %s", functionName)
-	output.Info("步长: %d%%", autoPercent)
-	output.Info("等待时间: %v", autoWait)
-	output.Info("灰度步骤: %v → promote", steps)
+	if len(autoAlarms) > 0 || len(thresholds) > 0 {
+		output.Info("健康检查: 每 %v 检查一次", autoCheckInterval)
+		for _, name := range autoAlarms {
+			output.Info("  - 告警 %s", name)
+		}
+		for _, t := range thresholds {
+			output.Info("  - 指标 %s (统计周期 %v)", t, autoMetricPeriod)
+		}
+	}
+	output.Info("中断处理: %s", onInterrupt)
 	if awsProfile != "" {
 		output.Info("Profile: %s", awsProfile)
 	}
 	output.Separator()
 
-	// 6. 创建 AWS Lambda 客户端
-	lambdaClient, err := aws.NewClient(ctx, awsProfile)
+	// 5. 创建 AWS Lambda 客户端
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
 	if err != nil {
-		output.Error("创建 AWS 客户端失败: %v", err)
-		os.Exit(exitcode.AWSError)
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
 		return
 	}
 
-	// 7. 获取 live 和 latest 别名的版本
-	output.Info("获取别名版本...")
-	liveVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "live")
-	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
-		return
+	// 创建健康检查，未指定告警和指标阈值时为 nil
+	var gate *health.Gate
+	if len(autoAlarms) > 0 || len(thresholds) > 0 {
+		metricsClient, err := metricsFactory(ctx, aws.ClientOptions{Profile: awsProfile, Region: GetRegion(env)})
+		if err != nil {
+			HandleAWSError(fmt.Errorf("创建 CloudWatch 客户端失败: %w", err))
+			return
+		}
+		gate = health.NewGate(metricsClient, autoAlarms, thresholds, autoMetricPeriod)
 	}
-	output.Info("live 别名: 版本 %s", liveVersion)
 
-	latestVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "latest")
-	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+	// 获取部署锁，执行期间其他操作者不能修改别名（dry-run 不修改别名，不加锁也不记录审计日志）
+	var lease *lock.Lease
+	if !autoDryRun {
+		var release func()
+		var ok bool
+		lease, release, ok = acquireLock(ctx, lambdaClient, functionName, "auto")
+		if !ok {
+			return
+		}
+		defer release()
+
+		// 记录审计日志，命令结束时写入执行前后的别名状态和结果
+		done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+		if !ok {
+			return
+		}
+		defer done()
+	}
+
+	// 6. 获取别名版本并计算部署状态
+	snapshot, ok := getDeploymentState(ctx, lambdaClient, functionName)
+	if !ok {
 		return
 	}
-	output.Info("latest 别名: 版本 %s", latestVersion)
+	liveVersion, liveRevision := snapshot.Live.Version, snapshot.Live.RevisionID
+	latestVersion := snapshot.Latest.Version
+	previousRevision := snapshot.Previous.RevisionID
 
-	// 8. 检查 live 和 latest 是否指向同一版本
-	if liveVersion == latestVersion {
+	// 现有路由不是 lad 配置的形式时提示将被覆盖
+	if snapshot.UnexpectedRouting() {
+		output.Warning("自动灰度将覆盖当前流量分配")
+	}
+
+	// 7. 检查当前状态是否有新版本需要发布
+	if rule := snapshot.Check(state.Auto); rule.Outcome != state.Allowed {
 		output.Separator()
-		output.Warning("live 和 latest 指向同一版本 (%s)", liveVersion)
-		output.Warning("没有新版本需要灰度发布，跳过 auto 操作")
+		output.Warning("%s，跳过 auto 操作", rule.Reason)
 		output.Info("")
 		output.Info("可能的原因:")
 		output.Info("  - sam deploy 没有检测到代码变化")
@@ -126,45 +253,299 @@ func runAuto(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	// 8. 根据当前灰度比例和进度记录确定从哪一步开始，输出执行计划，之后才会修改别名
+	start, err := resolveAutoStart(cmd, plan, source, snapshot, time.Now())
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+	plan = start.plan
+	printAutoPlan(start)
+	output.Separator()
+	if autoDryRun {
+		output.Info("dry-run: 未修改任何别名")
+		return
+	}
+
 	// 9. 按顺序执行灰度
-	totalSteps := len(steps) + 1 // 包括最后的 promote
-	for i, pct := range steps {
+	if !waitForVersionReady(ctx, lambdaClient, functionName, latestVersion) {
+		return
+	}
+	// 开始灰度前确认告警存在且没有处于 ALARM 状态，此时还没有修改任何别名
+	if gate != nil && len(autoAlarms) > 0 {
+		breach, err := gate.Preflight(ctx)
+		if err != nil {
+			HandleAWSError(fmt.Errorf("健康检查失败: %w", err))
+			return
+		}
+		if breach != nil {
+			output.Error("开始灰度前健康检查失败: %s", breach)
+			output.Info("别名未修改，请确认告警恢复后重新执行")
+			exitFunc(exitcode.HealthCheckFailed)
+			return
+		}
+		output.Success("告警状态正常")
+	}
+
+	began := time.Now()
+	target := health.Target{Function: functionName, Alias: "live", Version: latestVersion}
+	current := start.current   // 当前灰度比例
+	var bakeWait time.Duration // 当前阶段的等待时间，暂停期间的锁续期覆盖该时间
+	var paused bool            // 是否处于暂停状态
+	var pausedAt time.Time     // 开始暂停的时间
+	// readControl 读取 live 描述中的进度
+	// 只有路由与 auto 设置的一致时才返回进度，并沿用写入控制请求后的 RevisionId
+	// 路由被其他操作修改或读取失败时返回 nil，下一步修改别名时会发现并停止
+	readControl := func() (*strategy.Progress, error) {
+		live, err := lambdaClient.GetAliasState(ctx, functionName, "live")
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			output.Warning("读取控制请求失败: %v", err)
+			return nil, nil
+		}
+		progress, ok := strategy.ParseProgress(live.Description)
+		version, weight, routed := live.Canary()
+		if !ok || progress.Version != latestVersion || live.Version != liveVersion ||
+			!routed || version != latestVersion || int(math.Round(weight*100)) != current {
+			return nil, nil
+		}
+		liveRevision = live.RevisionID
+		return progress, nil
+	}
+	// pollControl 读取 live 描述中的控制请求（lad auto pause|resume|abort），报告状态变化
+	// 返回: 是否暂停；收到 abort 时返回 errAutoAborted，写入恢复后的进度失败时返回包装 errAutoResumeFailed 的 *aws.Error
+	pollControl := func() (bool, error) {
+		for {
+			progress, err := readControl()
+			if err != nil {
+				return false, err
+			}
+			if progress == nil {
+				return paused, nil
+			}
+
+			switch {
+			case progress.Control == strategy.ControlAbort:
+				return false, errAutoAborted
+			case progress.Control == strategy.ControlPause && !paused:
+				paused, pausedAt = true, time.Now()
+				output.Warning("收到暂停请求，灰度保持在 %d%%，执行 lad auto resume --env %s 继续", current, env)
+			case progress.Control == "" && paused:
+				// 进度的开始时间顺延暂停的时间，中断后 --resume 不把暂停时间计入等待时间
+				// 写入成功后才恢复，否则描述中留下的暂停请求或旧的开始时间会在下一次检查时再次生效
+				pausedFor := time.Since(pausedAt).Round(time.Second)
+				progress.Started = progress.Started.Add(pausedFor)
+				weight := float64(current) / 100.0
+				revision, exitCode := lambdaClient.ConfigureCanaryWithDescription(ctx, functionName, "live", liveVersion, latestVersion, weight, progress.Description(), liveRevision)
+				if exitCode == exitcode.StateChanged {
+					// 读取后 live 又被修改（例如再次暂停），重新读取
+					continue
+				}
+				if exitCode != exitcode.Success {
+					return paused, &aws.Error{Op: "UpdateAlias", Code: exitCode, Err: errAutoResumeFailed}
+				}
+				liveRevision = revision
+				paused = false
+				output.Info("收到恢复请求，继续灰度 (暂停了 %v)", pausedFor)
+			}
+			// 暂停期间续期部署锁，恢复后锁仍覆盖剩余的等待时间
+			if paused && !renewLock(ctx, lease, autoCheckInterval+bakeWait+lockTTL) {
+				return false, errAutoStopped
+			}
+			return paused, nil
+		}
+	}
+
+	// settle 处理在 pct% 的灰度比例下等待的结果: 中断、取消请求、健康检查失败
+	// 返回: 是否继续执行
+	settle := func(pct int, breach *health.Breach, err error) bool {
+		if ctx.Err() != nil {
+			interruptAuto(ctx, lambdaClient, functionName, snapshot.Latest, onInterrupt)
+			return false
+		}
+		if errors.Is(err, errAutoStopped) {
+			return false
+		}
+		if errors.Is(err, errAutoAborted) {
+			// 与 lad rollback 取消灰度相同
+			output.Separator()
+			output.Warning("收到取消请求 (lad auto abort)")
+			live := &aws.AliasState{
+				Name:       "live",
+				Version:    liveVersion,
+				Weights:    map[string]float64{latestVersion: float64(pct) / 100.0},
+				RevisionID: liveRevision,
+			}
+			if abortRelease(ctx, lambdaClient, functionName, live, snapshot.Latest, "lad auto abort 请求取消灰度") {
+				exitFunc(exitcode.Interrupted)
+			}
+			return false
+		}
+		if errors.Is(err, errAutoResumeFailed) {
+			HandleAWSError(err)
+			output.Warning("灰度保持在 %d%%，live 别名中仍为暂停请求，执行 lad auto resume --env %s 继续", pct, env)
+			return false
+		}
+		if err != nil {
+			HandleAWSError(fmt.Errorf("健康检查失败: %w", err))
+			output.Warning("灰度保持在 %d%%，请确认新版本状态后执行 lad rollback --env %s 或 lad auto --env %s --resume", pct, env, env)
+			return false
+		}
+		if breach != nil {
+			// 检查失败: 清除灰度配置，重置 latest 并标记新版本为取消发布
+			output.Separator()
+			output.Error("健康检查失败: %s", breach)
+			live := &aws.AliasState{
+				Name:       "live",
+				Version:    liveVersion,
+				Weights:    map[string]float64{latestVersion: float64(pct) / 100.0},
+				RevisionID: liveRevision,
+			}
+			if abortRelease(ctx, lambdaClient, functionName, live, snapshot.Latest, "自动灰度健康检查失败: "+breach.String()) {
+				exitFunc(exitcode.HealthCheckFailed)
+			}
+			return false
+		}
+		return true
+	}
+
+	// bake 在 pct% 的灰度比例下等待 wait 并持续检查健康状态
+	// 检查失败时取消灰度；返回: 是否继续执行
+	bake := func(pct int, wait time.Duration) bool {
+		if ctx.Err() != nil {
+			interruptAuto(ctx, lambdaClient, functionName, snapshot.Latest, onInterrupt)
+			return false
+		}
+		// 续期部署锁，租约覆盖等待时间，等待期间锁被解除时在下一步停止
+		if !renewLock(ctx, lease, wait+lockTTL) {
+			return false
+		}
+		output.Info("等待 %v...", wait)
+		bakeWait = wait
+		breach, err := waitHealthy(ctx, gate, target, wait, pollControl)
+		return settle(pct, breach, err)
+	}
+
+	// 继续执行时先在当前比例下等待剩余时间
+	if start.hold > 0 {
+		output.Separator()
+		output.Info("保持当前灰度比例 %d%%", start.current)
+		if !bake(start.current, start.hold) {
+			return
+		}
+	}
+
+	totalSteps := len(plan.Steps) + 1 // 包括最后的 promote
+	progressSaved := true
+	var exitCode int
+	for i := start.next; i < len(plan.Steps); i++ {
+		step := plan.Steps[i]
+		pct := step.Percent
 		output.Separator()
 		output.Info("[%d/%d] 执行灰度: %d%% 流量到新版本", i+1, totalSteps, pct)
 
+		// 在 live 的描述中记录进度，进程中断后可以通过 --resume 继续
 		weight := float64(pct) / 100.0
-		exitCode = lambdaClient.ConfigureCanary(ctx, functionName, "live", liveVersion, latestVersion, weight)
+		progress := &strategy.Progress{
+			Strategy: plan.Name,
+			Step:     i + 1,
+			Steps:    len(plan.Steps),
+			Version:  latestVersion,
+			Started:  time.Now(),
+			Operator: currentOperator(),
+		}
+		description := progress.Description()
+		if len(description) > strategy.MaxDescription {
+			if progressSaved {
+				output.Warning("策略描述过长，无法在 live 别名中记录进度，中断后 --resume 将重新等待完整时间")
+				progressSaved = false
+			}
+			// 清空描述，避免留下之前步骤的进度
+			description = ""
+		}
+		// 每一步都使用上一步返回的 RevisionId，等待期间 live 被修改则停止；失败时 liveRevision 保持发送的值
+		var revision string
+		revision, exitCode = lambdaClient.ConfigureCanaryWithDescription(ctx, functionName, "live", liveVersion, latestVersion, weight, description, liveRevision)
 		if exitCode != exitcode.Success {
-			os.Exit(exitCode)
+			// 请求被中断时不确定是否已生效，按别名的实际状态处理
+			if ctx.Err() != nil {
+				interruptAuto(ctx, lambdaClient, functionName, snapshot.Latest, onInterrupt)
+				return
+			}
+			// lad auto pause|resume|abort 可能在上一次检查之后写入了控制请求，RevisionId 随之变化
+			// 路由未被修改时按控制请求处理（暂停时等待恢复）后重试这一步，路由被修改时停止
+			if exitCode == exitcode.StateChanged {
+				sent := liveRevision
+				nowPaused, err := pollControl()
+				if err == nil && liveRevision == sent {
+					exitFunc(exitCode)
+					return
+				}
+				var breach *health.Breach
+				if err == nil && nowPaused {
+					breach, err = waitHealthy(ctx, gate, target, 0, pollControl)
+				}
+				if !settle(current, breach, err) {
+					return
+				}
+				i--
+				continue
+			}
+			exitFunc(exitCode)
 			return
 		}
 
+		liveRevision = revision
+		current = pct
 		output.Success("灰度配置完成")
 		output.Info("流量分配: %d%% v%s, %d%% v%s", 100-pct, liveVersion, pct, latestVersion)
 
-		output.Info("等待 %v...", autoWait)
-		time.Sleep(autoWait)
+		if !bake(pct, step.Wait) {
+			return
+		}
 	}
 
 	// 10. 执行 promote
+	if ctx.Err() != nil {
+		interruptAuto(ctx, lambdaClient, functionName, snapshot.Latest, onInterrupt)
+		return
+	}
+	// promote 开始后不再响应中断，避免 previous 和 live 只更新了一个
+	ctx = context.WithoutCancel(ctx)
+	// 修改 previous 和 live 前确认部署锁仍由自己持有
+	if !renewLock(ctx, lease, lockTTL) {
+		return
+	}
 	output.Separator()
 	output.Info("[%d/%d] 执行 promote，完成 100%% 切换...", totalSteps, totalSteps)
 
 	// 更新 previous 别名
-	exitCode = lambdaClient.UpdateAlias(ctx, functionName, "previous", liveVersion)
+	_, exitCode = lambdaClient.UpdateAlias(ctx, functionName, "previous", liveVersion, previousRevision)
 	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+		exitFunc(exitCode)
 		return
 	}
 	output.Success("previous 别名已更新到版本 %s", liveVersion)
 
-	// 更新 live 别名
-	exitCode = lambdaClient.UpdateAlias(ctx, functionName, "live", latestVersion)
+	// 更新 live 别名，同时清除进度记录
+	_, exitCode = lambdaClient.UpdateAliasWithDescription(ctx, functionName, "live", latestVersion, "", liveRevision)
+	if exitCode == exitcode.StateChanged {
+		// 控制请求在最后一次检查之后写入时路由没有变化，promote 已开始，不再处理控制请求
+		revision := liveRevision
+		if _, err := readControl(); err == nil && liveRevision != revision {
+			output.Info("promote 已开始，忽略之后的控制请求")
+			_, exitCode = lambdaClient.UpdateAliasWithDescription(ctx, functionName, "live", latestVersion, "", liveRevision)
+		}
+	}
 	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+		warnPartialPromote(liveVersion)
+		exitFunc(exitCode)
 		return
 	}
 	output.Success("live 别名已更新到版本 %s", latestVersion)
+	recordRelease(ctx, lambdaClient, functionName, liveVersion, latestVersion)
 
 	// 11. 输出结果
 	output.Separator()
@@ -174,7 +555,298 @@ func runAuto(cmd *cobra.Command, args []string) {
 	output.Info("  - previous: -> 版本 %s", liveVersion)
 	output.Info("  - live: 版本 %s -> 版本 %s (100%%)", liveVersion, latestVersion)
 	output.Info("")
-	output.Info("总耗时: %v", time.Duration(len(steps))*autoWait)
+	output.Info("总耗时: %v", time.Since(began).Round(time.Second))
 	output.Info("")
 	output.Info("如需回退: lad rollback --env %s", env)
 }
+
+// autoPlan 返回 auto 的执行计划和策略来源
+// 优先级: --strategy > --percent/--wait > samconfig.toml 的 auto_strategy > 默认（每次 10%，每步等待 5m）
+func autoPlan(cmd *cobra.Command) (*strategy.Plan, string, error) {
+	linearChanged := cmd.Flags().Changed("percent") || cmd.Flags().Changed("wait")
+	if autoStrategy != "" {
+		if linearChanged {
+			return nil, "", fmt.Errorf("--strategy 不能与 --percent、--wait 同时使用")
+		}
+		plan, err := strategy.Parse(autoStrategy)
+		return plan, "--strategy", err
+	}
+	if !linearChanged {
+		if samConfig, err := config.LoadSAMConfig(samconfigPath); err == nil {
+			if spec := samConfig.GetAutoStrategy(env); spec != "" {
+				plan, err := strategy.Parse(spec)
+				if err != nil {
+					return nil, "", fmt.Errorf("samconfig.toml 的 auto_strategy: %w", err)
+				}
+				return plan, "samconfig.toml", nil
+			}
+		}
+	}
+	plan, err := strategy.Linear(autoPercent, autoWait)
+	return plan, "--percent/--wait", err
+}
+
+// autoInterruptPolicy 返回 auto 被中断时的处理方式
+// 优先级: --on-interrupt > samconfig.toml 的 auto_on_interrupt > hold
+func autoInterruptPolicy(cmd *cobra.Command) (string, error) {
+	policy, source := autoOnInterrupt, "--on-interrupt"
+	if !cmd.Flags().Changed("on-interrupt") {
+		policy, source = interruptHold, ""
+		if samConfig, err := config.LoadSAMConfig(samconfigPath); err == nil {
+			if configured := samConfig.GetAutoOnInterrupt(env); configured != "" {
+				policy, source = configured, "samconfig.toml 的 auto_on_interrupt"
+			}
+		}
+	}
+	switch policy {
+	case interruptHold, interruptRevert, interruptRollback:
+		return policy, nil
+	}
+	return "", fmt.Errorf("%s 的值 '%s' 无效，有效值为: %s, %s, %s", source, policy, interruptHold, interruptRevert, interruptRollback)
+}
+
+// interruptAuto 在 auto 被中断时按 policy 处理当前灰度，记录回退日志并输出继续执行的命令，然后以 Interrupted 退出
+// 命令的 ctx 已被取消，使用新的 ctx 读取和修改别名；被中断的请求可能已生效，因此重新读取 live 的实际状态
+func interruptAuto(ctx context.Context, lambdaClient *aws.Client, functionName string, latest *aws.AliasState, policy string) {
+	cause := context.Cause(ctx)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), interruptTimeout)
+	defer cancel()
+
+	output.Separator()
+	output.Warning("自动灰度被中断: %v", cause)
+	live, ok := getAliasState(ctx, lambdaClient, functionName, "live")
+	if !ok {
+		return
+	}
+	pct := 0
+	if version, weight, ok := live.Canary(); ok && version == latest.Version {
+		pct = int(math.Round(weight * 100))
+	}
+
+	logReason := fmt.Sprintf("自动灰度被中断 (%v)", cause)
+	switch {
+	case pct == 0:
+		output.Info("新版本 %s 还没有流量，别名未修改", latest.Version)
+		logReason += "，新版本还没有流量"
+	case policy == interruptRollback:
+		// 与 lad rollback 取消灰度相同，abortRelease 记录回退日志并输出下一步操作
+		if abortRelease(ctx, lambdaClient, functionName, live, latest, logReason) {
+			exitFunc(exitcode.Interrupted)
+		}
+		return
+	case policy == interruptRevert:
+		// 清除灰度配置和进度记录，latest 保持新版本
+		output.Info("清除灰度配置...")
+		if _, exitCode := lambdaClient.UpdateAliasWithDescription(ctx, functionName, "live", live.Version, "", live.RevisionID); exitCode != exitcode.Success {
+			output.Warning("灰度仍保持在 %d%%，请执行 lad rollback --env %s 取消灰度", pct, env)
+			exitFunc(exitCode)
+			return
+		}
+		output.Success("灰度配置已清除，live 100%% 流量到版本 %s", live.Version)
+		logReason += fmt.Sprintf("，灰度比例从 %d%% 恢复为 0%%", pct)
+	default:
+		output.Info("灰度保持在 %d%%: %s", pct, live.RoutingSummary())
+		logReason += fmt.Sprintf("，灰度保持在 %d%%", pct)
+	}
+	writeRollbackLog(RollbackActionInterrupt, latest.Version, live.Version, logReason)
+
+	output.Info("")
+	output.Info("下一步操作:")
+	output.Info("  继续灰度: lad auto --env %s --resume", env)
+	output.Info("  取消灰度: lad rollback --env %s", env)
+	exitFunc(exitcode.Interrupted)
+}
+
+// autoStart 是 auto 的起点
+type autoStart struct {
+	plan    *strategy.Plan
+	source  string        // 策略来源
+	current int           // 当前灰度比例，没有灰度时为 0
+	next    int           // 第一个要执行的步骤（从 0 开始），之前的步骤已完成或跳过
+	hold    time.Duration // 执行 next 之前在当前比例下继续等待的时间
+}
+
+// resolveAutoStart 根据当前灰度比例确定 auto 的起点，灰度比例不会降低（--restart 除外）
+//   - 没有灰度时从第一步开始
+//   - --resume 且 live 描述中的进度与当前灰度一致时，使用记录的策略（未在命令行指定策略时），
+//     从记录的步骤继续，先等待该步骤剩余的时间
+//   - 其他情况跳过不高于当前比例的步骤，当前比例至少达到一个步骤时先等待该步骤的完整时间
+func resolveAutoStart(cmd *cobra.Command, plan *strategy.Plan, source string, snapshot *state.Snapshot, now time.Time) (*autoStart, error) {
+	if autoResume && autoRestart {
+		return nil, fmt.Errorf("--resume 不能与 --restart 同时使用")
+	}
+	start := &autoStart{plan: plan, source: source}
+	if version, weight, ok := snapshot.Live.Canary(); ok && version == snapshot.Latest.Version {
+		start.current = int(math.Round(weight * 100))
+	}
+	if start.current == 0 {
+		if autoResume {
+			output.Info("没有进行中的灰度，从第一步开始")
+		}
+		return start, nil
+	}
+	if autoRestart {
+		if len(plan.Steps) > 0 && plan.Steps[0].Percent < start.current {
+			output.Warning("从第一步重新开始，灰度比例将从 %d%% 降低到 %d%%", start.current, plan.Steps[0].Percent)
+		}
+		return start, nil
+	}
+
+	// 按进度记录继续
+	if progress, ok := strategy.ParseProgress(snapshot.Live.Description); autoResume && ok && progress.Version == snapshot.Latest.Version {
+		explicit := autoStrategy != "" || cmd.Flags().Changed("percent") || cmd.Flags().Changed("wait")
+		if !explicit && progress.Strategy != plan.Name {
+			recorded, err := strategy.Parse(progress.Strategy)
+			if err != nil {
+				output.Warning("无法解析进度记录中的策略 '%s': %v", progress.Strategy, err)
+			} else {
+				start.plan, start.source = recorded, "进度记录"
+			}
+		}
+		steps := start.plan.Steps
+		switch {
+		case start.plan.Name != progress.Strategy:
+			output.Warning("策略与进度记录中的 %s 不同，按当前比例 %d%% 继续", progress.Strategy, start.current)
+		case progress.Step > len(steps) || steps[progress.Step-1].Percent != start.current:
+			output.Warning("进度记录与当前灰度比例 %d%% 不一致，按当前比例继续", start.current)
+		default:
+			start.next = progress.Step
+			start.hold = progress.Remaining(steps[progress.Step-1].Wait, now)
+			output.Info("继续 %s 于 %s 开始的第 %d/%d 步", progress.Operator, progress.Started.Local().Format("2006-01-02 15:04:05"), progress.Step, progress.Steps)
+			return start, nil
+		}
+	} else if autoResume {
+		output.Warning("live 别名中没有版本 %s 的进度记录，按当前比例 %d%% 继续", snapshot.Latest.Version, start.current)
+	}
+
+	// 跳过已达到的步骤，当前比例按已达到的最后一步等待完整时间
+	steps := start.plan.Steps
+	for start.next < len(steps) && steps[start.next].Percent <= start.current {
+		start.next++
+	}
+	if start.next > 0 {
+		start.hold = steps[start.next-1].Wait
+	}
+	return start, nil
+}
+
+// printAutoPlan 输出执行计划: 每一步的灰度比例、等待时间和累计时间
+func printAutoPlan(start *autoStart) {
+	plan := start.plan
+	output.Info("策略: %s (%s)", plan.Name, start.source)
+	output.Info("执行计划:")
+	total := len(plan.Steps) + 1
+	elapsed := start.hold
+	if start.current > 0 {
+		output.Info("  当前灰度 %d%%，继续等待 %v", start.current, start.hold)
+	}
+	for i, step := range plan.Steps {
+		if i < start.next {
+			output.Info("  [%d/%d] %3d%% 已达到，跳过", i+1, total, step.Percent)
+			continue
+		}
+		elapsed += step.Wait
+		output.Info("  [%d/%d] %3d%% 流量到新版本，等待 %v (累计 %v)", i+1, total, step.Percent, step.Wait, elapsed)
+	}
+	output.Info("  [%d/%d] promote，100%% 流量到新版本", total, total)
+	output.Info("预计耗时: %v", elapsed)
+}
+
+// parseHealthChecks 验证健康检查参数，返回解析后的指标阈值
+func parseHealthChecks() ([]*health.Threshold, error) {
+	if len(autoAlarms) > health.MaxAlarms {
+		return nil, fmt.Errorf("最多指定 %d 个告警", health.MaxAlarms)
+	}
+	for _, name := range autoAlarms {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("告警名称不能为空")
+		}
+	}
+	var thresholds []*health.Threshold
+	for _, spec := range autoMetrics {
+		t, err := health.ParseThreshold(spec)
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, t)
+	}
+	if len(thresholds) > 0 {
+		if err := health.ValidatePeriod(autoMetricPeriod); err != nil {
+			return nil, err
+		}
+	}
+	if autoCheckInterval <= 0 {
+		return nil, fmt.Errorf("无效的检查间隔 '%v'，必须大于 0", autoCheckInterval)
+	}
+	return thresholds, nil
+}
+
+// waitHealthy 等待 wait 时间，gate 不为 nil 时在开始、每个检查间隔和结束时执行健康检查
+// 检查失败时立即返回 Breach；单次检查出错只输出警告，整个等待期间没有一次检查成功时返回错误
+// control 不为 nil 时在每个检查间隔读取控制请求: 暂停期间不计入等待时间，返回错误时停止等待并返回该错误
+// ctx 被取消时立即返回 ctx 的错误
+func waitHealthy(ctx context.Context, gate *health.Gate, target health.Target, wait time.Duration, control func() (bool, error)) (*health.Breach, error) {
+	if gate == nil && control == nil {
+		return nil, sleepContext(ctx, wait)
+	}
+
+	deadline := time.Now().Add(wait)
+	var lastErr error
+	checked := false
+	paused, left := false, time.Duration(0) // 暂停时剩余的等待时间
+	for {
+		if gate != nil {
+			breach, err := gate.Check(ctx, target)
+			switch {
+			case err != nil:
+				lastErr = err
+				output.Warning("健康检查出错: %v", err)
+			case breach != nil:
+				return breach, nil
+			default:
+				checked = true
+			}
+		}
+		if control != nil {
+			nowPaused, err := control()
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case nowPaused && !paused:
+				left = max(time.Until(deadline), 0)
+			case !nowPaused && paused:
+				deadline = time.Now().Add(left)
+			}
+			paused = nowPaused
+		}
+
+		interval := autoCheckInterval
+		if !paused {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				break
+			}
+			interval = min(interval, remaining)
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+	if gate != nil && !checked {
+		return nil, lastErr
+	}
+	return nil, nil
+}
+
+// sleepContext 等待 d，ctx 被取消时提前返回 ctx 的错误
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,146 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/strategy"
+	"github.com/spf13/cobra"
+)
+
+var autoPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "暂停运行中的 auto",
+	Long: `暂停运行中的 auto，保持当前灰度比例。
+
+请求写入 live 别名描述中的 auto 进度，运行中的 auto 在下一次检查时（--check-interval，
+默认 30 秒）暂停。暂停期间 auto 继续健康检查并续期部署锁，暂停时间不计入等待时间。
+该命令不获取部署锁，可以在 auto 运行期间从其他终端或主机执行。
+
+示例：
+  lad auto pause --env prod    # 暂停
+  lad auto resume --env prod   # 恢复`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runAutoControl(cmd, strategy.ControlPause)
+	},
+}
+
+var autoResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "恢复被暂停的 auto",
+	Long: `恢复被 lad auto pause 暂停的 auto，继续等待当前步骤剩余的时间。
+
+与 lad auto --resume 不同：--resume 启动新的 auto 继续被中断（进程已退出）的灰度，
+该命令通知仍在运行的 auto 继续执行。`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runAutoControl(cmd, "")
+	},
+}
+
+var autoAbortCmd = &cobra.Command{
+	Use:   "abort",
+	Short: "取消运行中的 auto",
+	Long: `取消运行中的 auto。
+
+运行中的 auto 在下一次检查时按 lad rollback 取消灰度: 清除灰度配置，latest 重置为 live 的版本
+并标记新版本为取消发布，记录回退日志后以退出码 12 退出。auto 没有在运行时请使用 lad rollback。`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runAutoControl(cmd, strategy.ControlAbort)
+	},
+}
+
+func init() {
+	autoCmd.AddCommand(autoPauseCmd)
+	autoCmd.AddCommand(autoResumeCmd)
+	autoCmd.AddCommand(autoAbortCmd)
+}
+
+// runAutoControl 在 live 别名描述的 auto 进度中写入控制请求，control 为空表示恢复
+// 不获取部署锁（运行中的 auto 持有锁）；写入时携带 RevisionId，与 auto 同时修改别名时以 StateChanged 失败
+func runAutoControl(cmd *cobra.Command, control string) {
+	ctx := cmd.Context()
+
+	// 1. 验证环境参数
+	if err := ValidateEnv(env); err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 2. 获取函数名
+	functionName, err := GetFunctionName(env)
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 3. 创建 AWS Lambda 客户端
+	lambdaClient, err := newLambdaClient(ctx, GetProfile(env))
+	if err != nil {
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
+		return
+	}
+
+	// 记录审计日志
+	done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	defer done()
+
+	// 4. 读取 live 别名中的 auto 进度
+	live, ok := getAliasState(ctx, lambdaClient, functionName, "live")
+	if !ok {
+		return
+	}
+	progress, hasProgress := strategy.ParseProgress(live.Description)
+	version, weight, routed := live.Canary()
+	if !hasProgress || !routed || version != progress.Version {
+		output.Error("live 别名中没有进行中的 auto")
+		output.Info("使用 'lad status --env %s' 查看当前状态", env)
+		exitFunc(exitcode.ParamError)
+		return
+	}
+	pct := int(math.Round(weight * 100))
+	output.Info("auto 进度: 第 %d/%d 步，版本 %s 灰度 %d%%，%s 执行", progress.Step, progress.Steps, version, pct, progress.Operator)
+
+	if progress.Control == control {
+		switch control {
+		case strategy.ControlPause:
+			output.Success("auto 已处于暂停状态")
+		case strategy.ControlAbort:
+			output.Success("已请求取消，等待 auto 处理")
+		default:
+			output.Success("auto 没有被暂停，无需恢复")
+		}
+		return
+	}
+	if progress.Control == strategy.ControlAbort {
+		output.Error("已请求取消 auto，不能再暂停或恢复")
+		exitFunc(exitcode.ParamError)
+		return
+	}
+
+	// 5. 写入控制请求，保持当前的灰度配置
+	progress.Control = control
+	if _, exitCode := lambdaClient.ConfigureCanaryWithDescription(ctx, functionName, "live", live.Version, version, weight, progress.Description(), live.RevisionID); exitCode != exitcode.Success {
+		exitFunc(exitCode)
+		return
+	}
+
+	switch control {
+	case strategy.ControlPause:
+		output.Success("已请求暂停，auto 将在下一次检查时保持 %d%% 灰度", pct)
+		output.Info("恢复: lad auto resume --env %s", env)
+	case strategy.ControlAbort:
+		output.Success("已请求取消，auto 将在下一次检查时取消灰度")
+		output.Info("auto 没有在运行时请使用: lad rollback --env %s", env)
+	default:
+		output.Success("已请求恢复，auto 将在下一次检查时继续")
+	}
+}
@@ -2,13 +2,11 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
-	"os"
 
-	"github.com/aura-studio/lad/internal/aws"
 	"github.com/aura-studio/lad/internal/exitcode"
 	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -24,8 +22,8 @@ var canaryCmd = &cobra.Command{
 
 该命令会执行以下操作：
 1. 验证灰度百分比参数 (0-100)
-2. 获取 live 和 latest 别名的版本
-3. 配置 live 别名的流量路由
+2. 获取 live、previous、latest 别名并计算部署状态
+3. 等待 latest 版本就绪后配置 live 别名的流量路由
 4. 显示流量分配比例和下一步操作提示
 
 使用 --percent 参数指定新版本流量百分比：
@@ -45,7 +43,7 @@ func init() {
 }
 
 func runCanary(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
+	ctx := cmd.Context()
 
 	// 1. 验证环境参数
 	if err := ValidateEnv(env); err != nil {
@@ -79,41 +77,55 @@ func runCanary(cmd *cobra.Command, args []string) {
 	output.Separator()
 
 	// 5. 创建 AWS Lambda 客户端
-	lambdaClient, err := aws.NewClient(ctx, awsProfile)
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
 	if err != nil {
-		output.Error("创建 AWS 客户端失败: %v", err)
-		os.Exit(exitcode.AWSError)
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
 		return
 	}
 
-	// 6. 获取 live 和 latest 别名的版本
-	output.Info("获取别名版本...")
-	liveVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "live")
-	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+	// 获取部署锁，执行期间其他操作者不能修改别名
+	_, release, ok := acquireLock(ctx, lambdaClient, functionName, "canary")
+	if !ok {
 		return
 	}
-	output.Info("live 别名: 版本 %s", liveVersion)
+	defer release()
 
-	latestVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "latest")
-	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+	// 记录审计日志，命令结束时写入执行前后的别名状态和结果
+	done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+	if !ok {
 		return
 	}
-	output.Info("latest 别名: 版本 %s", latestVersion)
+	defer done()
+
+	// 6. 获取别名版本并计算部署状态
+	snapshot, ok := getDeploymentState(ctx, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	liveVersion, liveRevision := snapshot.Live.Version, snapshot.Live.RevisionID
+	latestVersion := snapshot.Latest.Version
+
+	// 现有路由不是 lad 配置的形式时提示将被覆盖
+	if snapshot.UnexpectedRouting() {
+		output.Warning("本次操作将覆盖当前流量分配")
+	}
 
-	// 7. 检查 live 和 latest 是否指向同一版本
-	// percent=0 允许在同版本时执行（用于清除灰度配置）
-	if liveVersion == latestVersion && percent != 0 {
+	// 7. 检查当前状态是否可以配置或清除灰度
+	command := state.StartCanary
+	if percent == 0 {
+		command = state.ClearCanary
+	}
+	if rule := snapshot.Check(command); rule.Outcome != state.Allowed {
 		output.Separator()
-		output.Warning("live 和 latest 指向同一版本 (%s)", liveVersion)
-		output.Warning("没有新版本需要灰度发布，跳过 canary 操作")
-		output.Info("")
-		output.Info("可能的原因:")
-		output.Info("  - sam deploy 没有检测到代码变化")
-		output.Info("  - 已经完成了 promote 操作")
-		output.Info("")
-		output.Info("如需发布新版本，请先更新代码后重新执行 sam deploy")
+		output.Warning("%s，跳过 canary 操作", rule.Reason)
+		if percent != 0 {
+			output.Info("")
+			output.Info("可能的原因:")
+			output.Info("  - sam deploy 没有检测到代码变化")
+			output.Info("  - 已经完成了 promote 操作")
+			output.Info("")
+			output.Info("如需发布新版本，请先更新代码后重新执行 sam deploy")
+		}
 		return
 	}
 
@@ -124,18 +136,24 @@ func runCanary(cmd *cobra.Command, args []string) {
 	}
 
 	// 9. 配置灰度流量
+	// 只有把流量切到 latest 时才需要等待版本就绪，清除灰度只涉及 live 当前版本
+	if percent != 0 && !waitForVersionReady(ctx, lambdaClient, functionName, latestVersion) {
+		return
+	}
 	weight := float64(percent) / 100.0
 	output.Separator()
+	// 手动修改灰度时清空 live 的描述，中断的 auto 的进度已经过时，不能再用于 --resume 或 lad auto pause|abort
+	var exitCode int
 	if percent == 0 {
 		output.Info("清除灰度配置...")
 		// percent=0 直接更新别名到 liveVersion，清除路由配置
-		exitCode = lambdaClient.UpdateAlias(ctx, functionName, "live", liveVersion)
+		_, exitCode = lambdaClient.UpdateAliasWithDescription(ctx, functionName, "live", liveVersion, "", liveRevision)
 	} else {
 		output.Info("配置灰度流量...")
-		exitCode = lambdaClient.ConfigureCanary(ctx, functionName, "live", liveVersion, latestVersion, weight)
+		_, exitCode = lambdaClient.ConfigureCanaryWithDescription(ctx, functionName, "live", liveVersion, latestVersion, weight, "", liveRevision)
 	}
 	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+		exitFunc(exitCode)
 		return
 	}
 	if percent == 0 {
@@ -167,6 +185,6 @@ func runCanary(cmd *cobra.Command, args []string) {
 			output.Info("  增加灰度比例: lad canary --env %s --percent <更高百分比>", env)
 		}
 		output.Info("  完成灰度发布: lad promote --env %s", env)
-		output.Info("  回退灰度发布: lad rollback --env %s", env)
+		output.Info("  取消灰度发布: lad rollback --env %s", env)
 	}
 }
@@ -0,0 +1,312 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/config"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// deploy 命令选项
+	deploySAMPath   string
+	deploySkipBuild bool
+)
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "执行 sam build 和 sam deploy 部署新版本",
+	Long: `执行 sam build 和 sam deploy 部署新版本，部署后 latest 别名指向新版本。
+
+该命令会执行以下操作：
+1. 检查当前状态，存在活跃灰度或 live 与 latest 不一致时阻止部署
+2. 使用 --config-env 指定环境执行 sam build 和 sam deploy
+3. 验证部署后的别名状态：live 和 previous 保持不变，latest 指向新版本
+   部署前的别名状态记录在 .lad 目录。lad patch 生成的别名资源都引用 Version 资源，
+   CloudFormation 会把 live 和 previous 也移动到新版本，此时按部署前记录自动恢复
+   （与 'lad reconcile' 相同）；记录缺失或不一致时提示使用 'lad reconcile' 恢复
+
+示例：
+  lad deploy --env test                        # 构建并部署到 test 环境
+  lad deploy --env prod --skip-build           # 使用已有的构建结果部署
+  lad deploy --env test --sam-path ./bin/sam   # 使用指定的 SAM CLI`,
+	Run: runDeploy,
+}
+
+func init() {
+	deployCmd.Flags().StringVar(&deploySAMPath, "sam-path", "", "SAM CLI 路径 (默认读取 samconfig.toml 的 sam_path 参数，否则为 sam)")
+	deployCmd.Flags().BoolVar(&deploySkipBuild, "skip-build", false, "跳过 sam build")
+	rootCmd.AddCommand(deployCmd)
+}
+
+// GetSAMPath 获取 SAM CLI 路径
+// 优先级: --sam-path > samconfig.toml 的 [env.lad.parameters] sam_path > sam
+func GetSAMPath(envValue string) string {
+	if deploySAMPath != "" {
+		return deploySAMPath
+	}
+
+	if samConfig, err := config.LoadSAMConfig(samconfigPath); err == nil {
+		if samPath := samConfig.GetSAMPath(envValue); samPath != "" {
+			return samPath
+		}
+	}
+
+	return "sam"
+}
+
+// runSAM 执行 SAM CLI，输出直接转发到终端
+func runSAM(ctx context.Context, samPath string, args ...string) error {
+	output.Info("执行: %s %s", samPath, strings.Join(args, " "))
+
+	c := exec.CommandContext(ctx, samPath, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("sam %s 失败: %w", args[0], err)
+	}
+	return nil
+}
+
+// checkReadyForNewVersion 检查是否可以让 latest 指向新版本
+// 灰度态或待验证态时输出原因并以参数错误退出，避免覆盖尚未发布的版本
+// 返回: 是否可以继续
+func checkReadyForNewVersion(snapshot *state.Snapshot, action string) bool {
+	rule := snapshot.Check(state.Deploy)
+	if rule.Outcome == state.Allowed {
+		return true
+	}
+
+	output.Separator()
+	output.Error("%s失败: %s", action, rule.Reason)
+	output.Info("")
+	if snapshot.State == state.Canary {
+		output.Info("当前流量分配: %s", snapshot.Live.RoutingSummary())
+		output.Info("")
+		output.Info("请先完成或取消灰度:")
+		output.Info("  完成灰度发布: lad promote --env %s", env)
+		output.Info("  取消灰度: lad rollback --env %s", env)
+	} else {
+		output.Info("latest 版本 %s，live 版本 %s", snapshot.Latest.Version, snapshot.Live.Version)
+		output.Info("")
+		output.Info("请先发布或回退待发布版本:")
+		output.Info("  开始灰度发布: lad canary --env %s --percent 10", env)
+		output.Info("  直接发布: lad promote --env %s --skip-canary", env)
+		output.Info("  取消待发布版本: lad rollback --env %s", env)
+	}
+	exitFunc(exitcode.ParamError)
+	return false
+}
+
+func runDeploy(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	// 1. 验证环境参数
+	if err := ValidateEnv(env); err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 2. 获取函数名
+	functionName, err := GetFunctionName(env)
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 3. 获取 AWS Profile 和 SAM CLI 路径
+	awsProfile := GetProfile(env)
+	samPath := GetSAMPath(env)
+
+	output.Info("开始 Deploy...")
+	output.Info("环境: %s", env)
+	output.Info("函数: %s", functionName)
+	output.Info("SAM CLI: %s", samPath)
+	if awsProfile != "" {
+		output.Info("Profile: %s", awsProfile)
+	}
+	output.Separator()
+
+	// 4. 创建 AWS Lambda 客户端
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
+		return
+	}
+
+	// 获取部署锁，执行期间其他操作者不能修改别名
+	_, release, ok := acquireLock(ctx, lambdaClient, functionName, "deploy")
+	if !ok {
+		return
+	}
+	defer release()
+
+	// 记录审计日志，命令结束时写入执行前后的别名状态和结果
+	done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	defer done()
+
+	// 5. 检查当前状态
+	// live 或 latest 不存在时视为首次部署，由模板创建别名
+	output.Info("检查别名状态...")
+	before, ok := getManagedAliases(ctx, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	live, latest := before["live"], before["latest"]
+	firstDeploy := live == nil || latest == nil
+
+	if firstDeploy {
+		output.Info("未找到 live 或 latest 别名，按首次部署处理")
+	} else {
+		output.Info("live 别名: 版本 %s", live.Version)
+		output.Info("latest 别名: 版本 %s", latest.Version)
+		snapshot := state.Compute(live, before["previous"], latest)
+		printDeploymentState(snapshot)
+
+		// 存在活跃灰度或有待发布版本时阻止部署
+		if !checkReadyForNewVersion(snapshot, "部署") {
+			return
+		}
+
+		// 记录部署前的别名状态，CloudFormation 移动 live 或 previous 时用于 reconcile 恢复
+		if before["previous"] != nil {
+			savePreDeployRecord(functionName, before)
+		}
+	}
+	output.Separator()
+
+	// 6. 执行 sam build 和 sam deploy
+	if !deploySkipBuild {
+		if err := runSAM(ctx, samPath, "build", "--config-env", env); err != nil {
+			output.Error("%v", err)
+			exitFunc(exitcode.AWSError)
+			return
+		}
+		output.Separator()
+	}
+
+	if err := runSAM(ctx, samPath, "deploy", "--config-env", env, "--no-confirm-changeset", "--no-fail-on-empty-changeset"); err != nil {
+		output.Error("%v", err)
+		exitFunc(exitcode.AWSError)
+		return
+	}
+	output.Separator()
+
+	// 7. 验证部署后的别名状态
+	output.Info("验证别名状态...")
+	after, ok := getManagedAliases(ctx, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	for _, name := range managedAliases {
+		if after[name] == nil {
+			output.Error("部署后 %s 别名不存在", name)
+			output.Info("请确认 template.yaml 已通过 'lad patch' 添加别名资源")
+			exitFunc(exitcode.ResourceNotFound)
+			return
+		}
+		output.Info("  - %s: 版本 %s", name, after[name].Version)
+	}
+
+	if firstDeploy {
+		output.Separator()
+		output.Success("首次部署完成! 所有别名指向版本 %s", after["latest"].Version)
+		output.Info("")
+		output.Info("查看状态: lad status --env %s", env)
+		return
+	}
+
+	// live 和 previous 应保持不变，被 CloudFormation 移动时恢复到部署前的版本
+	var moved []string
+	for _, name := range []string{"live", "previous"} {
+		if prev, ok := before[name]; ok && prev.Version != after[name].Version {
+			moved = append(moved, name)
+		}
+	}
+	if len(moved) > 0 {
+		output.Separator()
+		if !restoreMovedAliases(ctx, lambdaClient, functionName, before, after, moved) {
+			return
+		}
+		output.Separator()
+		output.Info("验证别名状态...")
+		if after, ok = getManagedAliases(ctx, lambdaClient, functionName); !ok {
+			return
+		}
+		for _, name := range managedAliases {
+			output.Info("  - %s: 版本 %s", name, after[name].Version)
+		}
+	}
+
+	// live 不应配置路由
+	if after["live"].HasRouting() {
+		output.Error("部署后 live 别名配置了流量路由 (%s)，与预期不符", after["live"].RoutingSummary())
+		output.Info("请使用 'lad status --env %s' 确认当前状态", env)
+		exitFunc(exitcode.StateChanged)
+		return
+	}
+
+	// 8. 输出结果
+	// 别名状态符合预期，部署前记录不再需要
+	removePreDeployRecord()
+	output.Separator()
+	if after["latest"].Version == latest.Version {
+		output.Warning("latest 仍指向版本 %s，sam deploy 没有检测到代码变化", latest.Version)
+		return
+	}
+
+	output.Success("部署完成!")
+	output.Info("")
+	output.Info("版本变更:")
+	output.Info("  - latest: 版本 %s -> 版本 %s", latest.Version, after["latest"].Version)
+	output.Info("  - live: 版本 %s (未变化)", after["live"].Version)
+	output.Info("")
+	output.Info("下一步操作:")
+	output.Info("  开始灰度发布: lad canary --env %s --percent 10", env)
+	output.Info("  自动灰度发布: lad auto --env %s", env)
+	output.Info("  直接发布: lad promote --env %s --skip-canary", env)
+}
+
+// restoreMovedAliases 将 sam deploy 移动的 live 和 previous 别名恢复到部署前的版本
+// 只在部署前记录与部署前读取的别名状态一致时恢复，否则提示使用 lad reconcile 并以 StateChanged 退出
+// 返回: 是否已恢复
+func restoreMovedAliases(ctx context.Context, lambdaClient *aws.Client, functionName string, before, after map[string]*aws.AliasState, moved []string) bool {
+	want := make(map[string]string)
+	for _, name := range moved {
+		output.Warning("部署后 %s 别名从版本 %s 变为 %s", name, before[name].Version, after[name].Version)
+		want[name] = before[name].Version
+	}
+
+	record, err := LoadPreDeployRecord(preDeployRecordPath(env))
+	if err != nil {
+		output.Warning("%v", err)
+	}
+	if record == nil || before["previous"] == nil || record.Function != functionName ||
+		record.Live != before["live"].Version || record.Previous != before["previous"].Version {
+		output.Error("部署前记录不存在或与部署前的别名状态不一致，无法自动恢复")
+		output.Info("使用 'lad reconcile --env %s' 恢复部署前的 live 和 previous 别名", env)
+		exitFunc(exitcode.StateChanged)
+		return false
+	}
+
+	output.Info("按部署前记录恢复 live 和 previous 别名...")
+	if exitCode := restoreAliases(ctx, lambdaClient, functionName, moved, after, want); exitCode != exitcode.Success {
+		output.Info("部署前记录已保留，使用 'lad reconcile --env %s' 重新恢复", env)
+		exitFunc(exitCode)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/emulator"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// emulator 命令选项
+	emulatorAddr      string   // 监听地址
+	emulatorStateFile string   // 状态文件路径
+	emulatorSeed      []string // 启动时部署的函数
+)
+
+var emulatorCmd = &cobra.Command{
+	Use:   "emulator",
+	Short: "启动本地 Lambda API 模拟服务",
+	Long: `启动本地 HTTP 服务，模拟 lad 使用的 Lambda REST API 子集。
+
+支持的 API：
+  PublishVersion、GetAlias、UpdateAlias、CreateAlias、
+  GetFunction、ListVersionsByFunction、ListAliases、
+  DeleteFunction、GetAccountSettings、GetFunctionConfiguration、
+  UpdateFunctionCode
+
+配合全局选项 --endpoint-url 使用，可在没有 AWS 账号的情况下演练发布流程：
+  lad emulator --seed demo-function
+  lad status --function demo-function --endpoint-url http://127.0.0.1:4575
+
+模拟 sam deploy（发布新版本并移动 latest 别名）：
+  curl -X POST http://127.0.0.1:4575/_emulator/functions/demo-function/deploy
+
+指定 --state-file 时，状态在每次修改后写入文件，重启后恢复。`,
+	Run: runEmulator,
+}
+
+func init() {
+	emulatorCmd.Flags().StringVar(&emulatorAddr, "addr", "127.0.0.1:4575", "监听地址")
+	emulatorCmd.Flags().StringVar(&emulatorStateFile, "state-file", "", "状态文件路径（为空时只保存在内存中）")
+	emulatorCmd.Flags().StringSliceVar(&emulatorSeed, "seed", nil, "启动时部署的函数名，可重复指定")
+	rootCmd.AddCommand(emulatorCmd)
+}
+
+func runEmulator(cmd *cobra.Command, args []string) {
+	// 1. 加载状态
+	store := aws.NewFakeLambda()
+	if emulatorStateFile != "" {
+		if err := loadEmulatorState(store, emulatorStateFile); err != nil {
+			HandleParamError(fmt.Errorf("读取状态文件失败: %w", err))
+			return
+		}
+	}
+
+	var persist func() error
+	if emulatorStateFile != "" {
+		persist = func() error {
+			return saveEmulatorState(store, emulatorStateFile)
+		}
+	}
+
+	// 2. 部署种子函数（已存在的函数保持不变）
+	for _, name := range emulatorSeed {
+		if store.HasFunction(name) {
+			output.Info("函数已存在: %s", name)
+			continue
+		}
+		version, err := store.Deploy(name)
+		if err != nil {
+			output.Error("部署函数 %s 失败: %v", name, err)
+			exitFunc(exitcode.AWSError)
+			return
+		}
+		output.Info("已部署函数: %s (版本 %s)", name, version)
+	}
+	if persist != nil {
+		if err := persist(); err != nil {
+			output.Error("写入状态文件失败: %v", err)
+			exitFunc(exitcode.AWSError)
+			return
+		}
+	}
+
+	// 3. 启动 HTTP 服务
+	server := &http.Server{
+		Addr:              emulatorAddr,
+		Handler:           logRequests(emulator.New(store, persist)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// 收到 SIGINT 或 SIGTERM 时 ctx 被取消，关闭服务
+	ctx := cmd.Context()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	output.Info("Lambda 模拟服务已启动: http://%s", emulatorAddr)
+	if emulatorStateFile != "" {
+		output.Info("状态文件: %s", emulatorStateFile)
+	}
+	output.Info("使用 --endpoint-url http://%s 连接", emulatorAddr)
+	output.Separator()
+
+	select {
+	case err := <-errCh:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			output.Error("模拟服务异常退出: %v", err)
+			exitFunc(exitcode.NetworkError)
+			return
+		}
+	case <-ctx.Done():
+		output.Info("正在关闭模拟服务...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			output.Warning("关闭模拟服务失败: %v", err)
+		}
+	}
+}
+
+// logRequests 打印每个请求的方法、路径和状态码
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		output.Info("%s %s -> %d", r.Method, r.URL.RequestURI(), rec.status)
+	})
+}
+
+// statusRecorder 记录响应状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// loadEmulatorState 从文件恢复模拟服务状态，文件不存在时保持空状态
+func loadEmulatorState(store *aws.FakeLambda, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, store)
+}
+
+// saveEmulatorState 将模拟服务状态写入文件
+// 先写临时文件再重命名，避免进程中断时留下不完整的文件
+func saveEmulatorState(store *aws.FakeLambda, path string) error {
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,313 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aura-studio/lad/internal/audit"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// history 命令选项
+	historyFiles    []string
+	historyCommands []string
+	historyOperator string
+	historySince    string
+	historyUntil    string
+	historyLimit    int
+	historyOutput   string
+)
+
+// HistoryReport 是 history 命令的 JSON 输出
+type HistoryReport struct {
+	Files   []string       `json:"files"`   // 读取的日志文件
+	Total   int            `json:"total"`   // 过滤前的记录数
+	Skipped int            `json:"skipped"` // 无法解析而跳过的行数
+	Records []audit.Record `json:"records"` // 按时间从早到晚排序
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "查看发布操作历史",
+	Long: `查看发布操作历史。
+
+读取审计日志（默认 .lad/audit.log，或 --audit-log / samconfig.toml 中配置的文件）和
+rollback.log（旧格式的回退日志，.lad/rollback.log 和旧版本写入的可执行文件所在目录），
+按环境输出版本变化的时间线。
+
+--env 和 --function 只有在命令行中指定时才作为过滤条件；旧格式的回退日志没有函数名，
+无法判断属于哪个函数，指定 --function 时同样显示。
+
+示例：
+  lad history                                      # 所有环境最近 50 条记录
+  lad history --env prod --since 24h               # prod 最近 24 小时的操作
+  lad history --command rollback,promote           # 只显示回退和 promote
+  lad history --operator alice --since 2026-01-01  # alice 从 2026-01-01 起的操作
+  lad history --file ci-audit.log --output json    # 读取指定文件，以 JSON 格式输出`,
+	Run: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().StringArrayVar(&historyFiles, "file", nil, "读取的日志文件，可重复指定 (默认读取审计日志和 rollback.log)")
+	historyCmd.Flags().StringSliceVar(&historyCommands, "command", nil, "只显示指定的命令 (例如 canary,rollback)")
+	historyCmd.Flags().StringVar(&historyOperator, "operator", "", "只显示指定操作人的记录")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "起始时间 (例如 24h、2026-01-01 或 RFC3339 时间)")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "结束时间，不包含 (格式同 --since)")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 50, "最多显示的记录数，保留最近的记录 (0 表示全部)")
+	historyCmd.Flags().StringVar(&historyOutput, "output", "table", "输出格式 (table|json)")
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) {
+	now := time.Now()
+
+	// 1. 验证参数并生成过滤条件
+	if historyOutput != "table" && historyOutput != "json" {
+		HandleParamError(fmt.Errorf("无效的输出格式 '%s'，有效值为: table, json", historyOutput))
+		return
+	}
+	if historyLimit < 0 {
+		HandleParamError(fmt.Errorf("无效的数量 '%d'，最小为 0", historyLimit))
+		return
+	}
+	filter := audit.Filter{Commands: historyCommands, Operator: historyOperator}
+	if cmd.Flags().Changed("env") {
+		if err := ValidateEnv(env); err != nil {
+			HandleParamError(err)
+			return
+		}
+		filter.Env = env
+	}
+	if cmd.Flags().Changed("function") {
+		filter.Function = function
+	}
+	var err error
+	if filter.Since, err = parseHistoryTime(historySince, now); err != nil {
+		HandleParamError(fmt.Errorf("无效的起始时间: %w", err))
+		return
+	}
+	if filter.Until, err = parseHistoryTime(historyUntil, now); err != nil {
+		HandleParamError(fmt.Errorf("无效的结束时间: %w", err))
+		return
+	}
+
+	// 2. 确定要读取的日志文件
+	files, explicit := historyFiles, len(historyFiles) > 0
+	if !explicit {
+		if files, err = defaultHistoryFiles(); err != nil {
+			HandleParamError(err)
+			return
+		}
+	}
+
+	// 3. 读取并过滤记录
+	report := HistoryReport{Files: []string{}}
+	var records []audit.Record
+	for _, path := range files {
+		fileRecords, skipped, err := audit.ReadFile(path)
+		if err != nil {
+			// 默认的日志文件可能还没有创建
+			if !explicit && errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			HandleParamError(fmt.Errorf("无法读取日志文件 %s: %w", path, err))
+			return
+		}
+		report.Files = append(report.Files, path)
+		report.Skipped += skipped
+		records = append(records, fileRecords...)
+	}
+	// rollback.log 中与审计日志重复的记录只显示一次
+	records = audit.Dedupe(records)
+	report.Total = len(records)
+	report.Records = audit.Select(records, filter)
+	if historyLimit > 0 && len(report.Records) > historyLimit {
+		report.Records = report.Records[len(report.Records)-historyLimit:]
+	}
+
+	// 4. 输出
+	if historyOutput == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			output.Error("无法生成 JSON 输出: %v", err)
+			exitFunc(exitcode.AWSError)
+			return
+		}
+		output.Info("%s", data)
+		return
+	}
+
+	output.Info("发布操作历史")
+	for _, path := range report.Files {
+		output.Info("日志文件: %s", path)
+	}
+	if report.Skipped > 0 {
+		output.Warning("%d 行无法解析，已跳过", report.Skipped)
+	}
+	output.Separator()
+	if len(report.Records) == 0 {
+		output.Info("没有符合条件的记录 (共 %d 条记录)", report.Total)
+		return
+	}
+	printHistoryTimeline(report.Records)
+	output.Separator()
+	if filter.Function != "" {
+		unnamed := 0
+		for _, r := range report.Records {
+			if r.Function == "" {
+				unnamed++
+			}
+		}
+		if unnamed > 0 {
+			output.Info("其中 %d 条记录没有函数名（旧格式的回退日志），无法按 --function 过滤", unnamed)
+		}
+	}
+	output.Info("显示 %d 条记录 (共 %d 条记录)", len(report.Records), report.Total)
+}
+
+// defaultHistoryFiles 返回默认读取的日志文件: 审计日志的文件输出、本地记录目录和可执行文件所在目录的 rollback.log
+// rollback.log 用于读取写入审计日志之前的旧记录，与审计日志重复的记录由 audit.Dedupe 删除；
+// 旧版本把 rollback.log 写在可执行文件所在目录
+func defaultHistoryFiles() ([]string, error) {
+	sinks, err := auditSinks()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, sink := range sinks {
+		if fileSink, ok := sink.(*audit.FileSink); ok {
+			files = append(files, fileSink.Path())
+		}
+	}
+	files = append(files, rollbackLogPath())
+	if execDir, err := getExecutablePath(); err == nil {
+		legacy := filepath.Join(execDir, "rollback.log")
+		if !sameFile(legacy, rollbackLogPath()) {
+			files = append(files, legacy)
+		}
+	}
+	return files, nil
+}
+
+// parseHistoryTime 解析 --since 和 --until
+// 支持相对时长（例如 24h，表示 now 之前）、日期（2006-01-02，本地时间）和 RFC3339 时间；空值返回零值
+func parseHistoryTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("'%s' 不能为负数", value)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("'%s' 不是时长、日期或 RFC3339 时间", value)
+}
+
+// printHistoryTimeline 按环境分组，以表格形式输出每个环境的时间线
+func printHistoryTimeline(records []audit.Record) {
+	groups := make(map[string][]audit.Record)
+	var envs []string
+	for _, r := range records {
+		key := valueOrDash(r.Env)
+		if _, ok := groups[key]; !ok {
+			envs = append(envs, key)
+		}
+		groups[key] = append(groups[key], r)
+	}
+	sort.Strings(envs)
+
+	for i, envName := range envs {
+		if i > 0 {
+			output.Info("")
+		}
+		output.Info("环境: %s", envName)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "时间\t命令\t函数\t操作人\t版本变化\t结果\t原因")
+		for _, r := range groups[envName] {
+			result := "成功"
+			if r.Outcome == audit.Failure {
+				result = fmt.Sprintf("失败 (%d)", r.ExitCode)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				r.Timestamp.Local().Format("2006-01-02 15:04:05"),
+				historyCommand(&r),
+				valueOrDash(r.Function),
+				valueOrDash(r.Operator),
+				valueOrDash(versionChanges(&r)),
+				result,
+				valueOrDash(r.Reason),
+			)
+		}
+		w.Flush()
+	}
+}
+
+// historyCommand 返回时间线中显示的命令，取消灰度的回退显示为 "rollback (abort)"，auto 被中断显示为 "auto (interrupt)"
+func historyCommand(r *audit.Record) string {
+	if r.Command == "rollback" && r.Flags["action"] == RollbackActionAbort {
+		return "rollback (abort)"
+	}
+	if r.Command == "auto" && r.Flags["action"] == RollbackActionInterrupt {
+		return "auto (interrupt)"
+	}
+	return r.Command
+}
+
+// versionChanges 返回记录中发生变化的别名，例如 "live: 1 -> 1 (90%), 2 (10%)"
+// 没有变化时返回空字符串
+func versionChanges(r *audit.Record) string {
+	var changes []string
+	for _, name := range []string{"live", "latest", "previous"} {
+		before, hadBefore := r.Before[name]
+		after, hasAfter := r.After[name]
+		from, to := describeAlias(before, hadBefore), describeAlias(after, hasAfter)
+		if !hasAfter || from == to {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, from, to))
+	}
+	return strings.Join(changes, "; ")
+}
+
+// describeAlias 返回别名状态的简短描述: 没有灰度时为版本号，有灰度时为流量分配
+func describeAlias(a audit.Alias, ok bool) string {
+	if !ok {
+		return "?"
+	}
+	state := &aws.AliasState{Version: a.Version, Weights: a.Weights}
+	if state.HasRouting() {
+		return state.RoutingSummary()
+	}
+	return a.Version
+}
+
+// sameFile 判断两个路径是否指向同一个文件（文件不存在时比较绝对路径）
+func sameFile(a, b string) bool {
+	if infoA, err := os.Stat(a); err == nil {
+		if infoB, err := os.Stat(b); err == nil {
+			return os.SameFile(infoA, infoB)
+		}
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
@@ -0,0 +1,224 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// managedAliases 是 lad 管理的别名
+var managedAliases = []string{"live", "previous", "latest"}
+
+var (
+	// init-aliases 命令选项
+	initAliasesVersion string
+	initAliasesPublish bool
+	initAliasesDryRun  bool
+)
+
+var initAliasesCmd = &cobra.Command{
+	Use:   "init-aliases",
+	Short: "为已有函数创建缺失的别名",
+	Long: `为已有函数创建缺失的 live、previous、latest 别名。
+
+用于在未通过打补丁的模板部署的函数上启用 lad。
+该命令会执行以下操作：
+1. 检查 live、previous、latest 三个别名是否存在
+2. 确定缺失别名指向的版本：
+   - --version 指定的已发布版本
+   - --publish 从 $LATEST 发布的新版本
+   - 都未指定时使用 live 别名的版本（live 必须存在）
+3. 创建缺失的别名，已存在的别名不会被修改
+
+示例：
+  lad init-aliases --env prod --version 12     # 缺失的别名指向版本 12
+  lad init-aliases --env prod --publish        # 发布新版本并让缺失的别名指向它
+  lad init-aliases --env prod --dry-run        # 只显示将创建的别名`,
+	Run: runInitAliases,
+}
+
+func init() {
+	initAliasesCmd.Flags().StringVar(&initAliasesVersion, "version", "", "缺失的别名指向的已发布版本")
+	initAliasesCmd.Flags().BoolVar(&initAliasesPublish, "publish", false, "从 $LATEST 发布新版本，缺失的别名指向该版本")
+	initAliasesCmd.Flags().BoolVar(&initAliasesDryRun, "dry-run", false, "只显示将创建的别名，不执行创建")
+	rootCmd.AddCommand(initAliasesCmd)
+}
+
+func runInitAliases(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	// 1. 验证环境参数
+	if err := ValidateEnv(env); err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 2. 验证 --version 和 --publish 参数
+	if initAliasesVersion != "" && initAliasesPublish {
+		HandleParamError(fmt.Errorf("--version 和 --publish 不能同时指定"))
+		return
+	}
+	if initAliasesVersion == "$LATEST" {
+		HandleParamError(fmt.Errorf("别名不能指向 $LATEST，请指定已发布的版本号"))
+		return
+	}
+
+	// 3. 获取函数名
+	functionName, err := GetFunctionName(env)
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 4. 获取 AWS Profile
+	awsProfile := GetProfile(env)
+
+	output.Info("初始化别名...")
+	output.Info("环境: %s", env)
+	output.Info("函数: %s", functionName)
+	if awsProfile != "" {
+		output.Info("Profile: %s", awsProfile)
+	}
+	if initAliasesDryRun {
+		output.Info("模式: dry-run（不会创建别名）")
+	}
+	output.Separator()
+
+	// 5. 创建 AWS Lambda 客户端
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
+		return
+	}
+
+	// 获取部署锁，执行期间其他操作者不能修改别名（dry-run 不修改别名，不加锁也不记录审计日志）
+	if !initAliasesDryRun {
+		_, release, ok := acquireLock(ctx, lambdaClient, functionName, "init-aliases")
+		if !ok {
+			return
+		}
+		defer release()
+
+		// 记录审计日志，命令结束时写入执行前后的别名状态和结果
+		done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+		if !ok {
+			return
+		}
+		defer done()
+	}
+
+	// 6. 验证函数是否存在，避免将函数不存在误判为别名缺失
+	if exitCode := lambdaClient.VerifyVersionExists(ctx, functionName, "$LATEST"); exitCode != exitcode.Success {
+		exitFunc(exitCode)
+		return
+	}
+
+	// 7. 检查别名是否存在
+	// 别名不存在视为缺失，其他错误（权限、网络等）直接报错退出
+	output.Info("检查别名...")
+	states, ok := getManagedAliases(ctx, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	var missing []string
+	for _, name := range managedAliases {
+		state, ok := states[name]
+		if !ok {
+			missing = append(missing, name)
+			output.Info("  - %s: 未配置", name)
+			continue
+		}
+		output.Info("  - %s: 版本 %s", name, state.Version)
+	}
+
+	if len(missing) == 0 {
+		output.Separator()
+		output.Success("所有别名均已存在，无需初始化")
+		return
+	}
+
+	// 8. 确定缺失别名指向的版本
+	output.Separator()
+	var targetVersion string
+	switch {
+	case initAliasesVersion != "":
+		output.Info("验证版本 %s 是否存在...", initAliasesVersion)
+		if exitCode := lambdaClient.VerifyVersionExists(ctx, functionName, initAliasesVersion); exitCode != exitcode.Success {
+			if exitCode == exitcode.ResourceNotFound {
+				output.Info("使用 'lad versions --env %s' 查看已发布的版本", env)
+			}
+			exitFunc(exitCode)
+			return
+		}
+		targetVersion = initAliasesVersion
+	case initAliasesPublish:
+		if initAliasesDryRun {
+			targetVersion = "(新发布的版本)"
+			break
+		}
+		output.Info("从 $LATEST 发布新版本...")
+		targetVersion, err = lambdaClient.CreateVersion(ctx, functionName, "lad init-aliases")
+		if err != nil {
+			HandleAWSError(fmt.Errorf("发布版本失败: %w", err))
+			return
+		}
+		output.Success("已发布版本 %s", targetVersion)
+	case states["live"] != nil:
+		targetVersion = states["live"].Version
+		output.Info("使用 live 别名的版本 %s", targetVersion)
+	default:
+		HandleParamError(fmt.Errorf("live 别名不存在，请通过 --version 指定版本或使用 --publish 发布新版本"))
+		return
+	}
+
+	// 9. 显示初始化计划
+	output.Info("初始化计划:")
+	for _, name := range managedAliases {
+		if state, ok := states[name]; ok {
+			output.Info("  - %s: 保留 (版本 %s)", name, state.Version)
+		} else {
+			output.Info("  - %s: 创建 -> 版本 %s", name, targetVersion)
+		}
+	}
+
+	if initAliasesDryRun {
+		output.Separator()
+		output.Info("dry-run 模式，未创建别名")
+		return
+	}
+
+	// 10. 创建缺失的别名
+	// 检查之后被其他操作创建的别名保持不变，继续创建其余别名
+	output.Separator()
+	failedCode := exitcode.Success
+	var created []string
+	for _, name := range missing {
+		err := lambdaClient.CreateAlias(ctx, functionName, name, targetVersion, "由 lad init-aliases 创建")
+		if err != nil {
+			if aws.ClassifyError(err) == exitcode.StateChanged {
+				output.Warning("%s 别名已被其他操作创建，未修改", name)
+				failedCode = exitcode.StateChanged
+				continue
+			}
+			HandleAWSError(fmt.Errorf("创建 %s 别名失败: %w", name, err))
+			return
+		}
+		created = append(created, name)
+		output.Success("%s 别名已创建，指向版本 %s", name, targetVersion)
+	}
+
+	// 11. 输出结果
+	output.Separator()
+	if failedCode != exitcode.Success {
+		output.Warning("部分别名未按计划创建，请使用 'lad status --env %s' 确认当前状态", env)
+		exitFunc(failedCode)
+		return
+	}
+	output.Success("别名初始化完成! 已创建: %v", created)
+	output.Info("")
+	output.Info("查看状态: lad status --env %s", env)
+}
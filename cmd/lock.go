@@ -0,0 +1,272 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/lock"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// 部署锁存储
+const (
+	lockBackendFile = "file" // 本地文件，适用于单主机 CI（默认）
+	lockBackendTags = "tags" // 函数标签，所有能访问函数的操作者共享，需要额外的标签权限
+	lockBackendNone = "none" // 不加锁
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "查看或解除部署锁",
+	Long: `查看或解除部署锁。
+
+修改别名的命令（deploy、publish、canary、auto、promote、rollback、switch、
+reconcile、init-aliases、prune）在执行期间持有部署锁，同一函数同一时间只能有
+一个操作者修改别名。锁被其他操作持有时命令以退出码 10 失败。
+
+锁以租约形式保存（--lock-ttl，默认 15 分钟），持有者异常退出后锁在到期后自动失效；
+auto 在每一步之间续期。锁默认保存在本地文件中（--lock file，.lad/lock-<env>-<function>.json），
+只对同一台主机上的操作生效。
+
+多人或多台 CI 共享同一个函数时使用 --lock tags，锁保存在函数标签中。执行命令的
+身份需要 lambda:TagResource、lambda:UntagResource、lambda:ListTags 权限，缺少
+权限时命令以退出码 6 失败。标签没有条件写入，获取锁时写入后等待 2 秒再读回确认，
+两个操作几乎同时获取锁且其中一个的写入被重试延迟超过等待时间时仍可能都认为自己
+持有锁。
+
+示例：
+  lad lock status --env prod   # 查看锁的持有者、命令和到期时间
+  lad lock break --env prod    # 确认持有者已退出后强制解除`,
+}
+
+var lockStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "查看部署锁",
+	Run:   runLockStatus,
+}
+
+var lockBreakCmd = &cobra.Command{
+	Use:   "break",
+	Short: "强制解除部署锁",
+	Run:   runLockBreak,
+}
+
+func init() {
+	lockCmd.AddCommand(lockStatusCmd)
+	lockCmd.AddCommand(lockBreakCmd)
+	rootCmd.AddCommand(lockCmd)
+}
+
+// lockHolder 返回部署锁的持有者标识: 用户名@主机名
+func lockHolder() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return currentOperator() + "@" + host
+}
+
+// currentOperator 返回当前操作人（USER 环境变量）
+func currentOperator() string {
+	operator := os.Getenv("USER")
+	if operator == "" {
+		operator = "unknown"
+	}
+	return operator
+}
+
+// newLockStore 根据 --lock 创建部署锁存储
+// 返回: 存储（--lock none 时为 nil）, 错误
+func newLockStore(lambdaClient *aws.Client, functionName string) (lock.Store, error) {
+	switch lockBackend {
+	case lockBackendTags:
+		return lock.NewTagStore(lambdaClient, functionName), nil
+	case lockBackendFile:
+		return lock.NewFileStore(filepath.Join(recordDir, lockFileName(env, functionName))), nil
+	case lockBackendNone:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("无效的部署锁存储 '%s'，有效值为 tags、file、none", lockBackend)
+}
+
+// lockFileName 返回 --lock file 的锁文件名 lock-<env>-<function>.json，与标签存储一样按函数加锁
+// 函数名可能是 ARN，文件名中不允许的字符替换为 '_'
+func lockFileName(env, functionName string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, functionName)
+	return fmt.Sprintf("lock-%s-%s.json", env, name)
+}
+
+// acquireLock 获取部署锁，--lock none 时不加锁
+// 锁被其他操作持有时输出持有者并以 Locked 退出
+// 获取成功后，命令通过 exitFunc 退出前会先释放锁；命令正常结束时调用方需 defer release()
+// 返回: 租约（不加锁时为 nil）, 释放函数, 是否成功
+func acquireLock(ctx context.Context, lambdaClient *aws.Client, functionName, command string) (*lock.Lease, func(), bool) {
+	store, err := newLockStore(lambdaClient, functionName)
+	if err != nil {
+		HandleParamError(err)
+		return nil, nil, false
+	}
+	if store == nil {
+		return nil, func() {}, true
+	}
+
+	lease, err := lock.Acquire(ctx, store, lockHolder(), command, env, lockTTL)
+	if err != nil {
+		// 首次部署时函数还不存在，无法在函数标签中加锁
+		if lockBackend == lockBackendTags && aws.ClassifyError(err) == exitcode.ResourceNotFound {
+			output.Warning("函数 %s 不存在，本次操作不加部署锁", functionName)
+			return nil, func() {}, true
+		}
+		handleLockError(fmt.Errorf("获取部署锁失败: %w", err))
+		return nil, nil, false
+	}
+	held := lease.Lock()
+	output.Info("已获取部署锁 (到期: %s)", held.ExpiresAt.Local().Format(time.RFC3339))
+
+	release := deferExit(func(int) {
+		if err := lease.Release(context.Background()); err != nil {
+			output.Warning("释放部署锁失败: %v，锁将在 %s 到期", err, held.ExpiresAt.Local().Format(time.RFC3339))
+		}
+	})
+	return lease, release, true
+}
+
+// renewLock 延长部署锁租约，租约到期时间为当前时间加上 d
+// 锁已被解除或被其他操作获取时输出错误并以 Locked 退出
+// 返回: 是否成功
+func renewLock(ctx context.Context, lease *lock.Lease, d time.Duration) bool {
+	if lease == nil {
+		return true
+	}
+	if err := lease.Renew(ctx, d); err != nil {
+		handleLockError(fmt.Errorf("续期部署锁失败: %w", err))
+		return false
+	}
+	return true
+}
+
+// handleLockError 处理部署锁错误
+// 锁被其他操作持有或已被解除时以 Locked 退出，其他错误按 AWS 错误处理
+func handleLockError(err error) {
+	var held *lock.HeldError
+	if errors.As(err, &held) || errors.Is(err, lock.ErrLost) {
+		output.Error("%s", err.Error())
+		output.Info("请等待其他操作完成；确认持有者已退出时可使用 'lad lock break --env %s' 解除", env)
+		exitFunc(exitcode.Locked)
+		return
+	}
+	HandleAWSError(err)
+}
+
+// setupLockCommand 验证参数并创建部署锁存储
+// 返回: 存储, 是否成功
+func setupLockCommand(ctx context.Context) (lock.Store, bool) {
+	if err := ValidateEnv(env); err != nil {
+		HandleParamError(err)
+		return nil, false
+	}
+	functionName, err := GetFunctionName(env)
+	if err != nil {
+		HandleParamError(err)
+		return nil, false
+	}
+	if lockBackend == lockBackendNone {
+		HandleParamError(fmt.Errorf("--lock none 时没有部署锁"))
+		return nil, false
+	}
+
+	output.Info("环境: %s", env)
+	output.Info("函数: %s", functionName)
+	output.Info("锁存储: %s", lockBackend)
+	output.Separator()
+
+	lambdaClient, err := newLambdaClient(ctx, GetProfile(env))
+	if err != nil {
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
+		return nil, false
+	}
+	store, err := newLockStore(lambdaClient, functionName)
+	if err != nil {
+		HandleParamError(err)
+		return nil, false
+	}
+	return store, true
+}
+
+// printLock 输出锁的内容
+func printLock(l *lock.Lock) {
+	output.Info("  - 持有者: %s", l.Holder)
+	output.Info("  - 命令: %s", l.Command)
+	output.Info("  - 环境: %s", l.Env)
+	output.Info("  - 开始时间: %s", l.StartedAt.Local().Format(time.RFC3339))
+	if l.Expired(time.Now()) {
+		output.Info("  - 到期时间: %s (已到期，下一个操作会直接获取锁)", l.ExpiresAt.Local().Format(time.RFC3339))
+	} else {
+		output.Info("  - 到期时间: %s", l.ExpiresAt.Local().Format(time.RFC3339))
+	}
+}
+
+func runLockStatus(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	// 1. 验证参数并创建锁存储
+	store, ok := setupLockCommand(ctx)
+	if !ok {
+		return
+	}
+
+	// 2. 读取并显示锁
+	current, err := store.Load(ctx)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("读取部署锁失败: %w", err))
+		return
+	}
+	if current == nil {
+		output.Success("部署锁: 未加锁")
+		return
+	}
+	output.Info("部署锁:")
+	printLock(current)
+}
+
+func runLockBreak(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	// 1. 验证参数并创建锁存储
+	store, ok := setupLockCommand(ctx)
+	if !ok {
+		return
+	}
+
+	// 2. 删除锁
+	removed, err := lock.Break(ctx, store)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("解除部署锁失败: %w", err))
+		return
+	}
+	if removed == nil {
+		output.Success("部署锁: 未加锁，无需解除")
+		return
+	}
+
+	output.Info("已解除的部署锁:")
+	printLock(removed)
+	if !removed.Expired(time.Now()) {
+		output.Warning("锁尚未到期，如果持有者仍在执行，它会在下一次续期时停止")
+	}
+	output.Success("部署锁已解除")
+}
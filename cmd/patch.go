@@ -2,8 +2,6 @@
 package cmd
 
 import (
-	"os"
-
 	"github.com/aura-studio/lad/internal/patcher"
 	"github.com/spf13/cobra"
 )
@@ -41,6 +39,15 @@ func init() {
 }
 
 func runPatch(cmd *cobra.Command, args []string) {
+	// 记录审计日志，dry-run 不修改模板，不记录
+	if !patchDryRun {
+		done, ok := beginAudit(cmd.Context(), cmd, nil, "")
+		if !ok {
+			return
+		}
+		defer done()
+	}
+
 	opts := patcher.PatchOptions{
 		TemplatePath: patchTemplate,
 		FunctionName: patchFunctionName,
@@ -49,5 +56,5 @@ func runPatch(cmd *cobra.Command, args []string) {
 	}
 
 	result := patcher.Patch(opts)
-	os.Exit(result.ExitCode)
+	exitFunc(result.ExitCode)
 }
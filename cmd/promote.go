@@ -2,12 +2,11 @@
 package cmd
 
 import (
-	"context"
-	"os"
+	"fmt"
 
-	"github.com/aura-studio/lad/internal/aws"
 	"github.com/aura-studio/lad/internal/exitcode"
 	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -22,9 +21,9 @@ var promoteCmd = &cobra.Command{
 	Long: `完成灰度发布，将流量完全切换到新版本。
 
 该命令会执行以下操作：
-1. 获取 live 和 latest 别名的版本
+1. 获取别名版本并检查部署状态
 2. 检查是否有活跃的灰度配置（可通过 --skip-canary 跳过）
-3. 更新 previous 别名指向原 live 版本
+3. 等待 latest 版本就绪后更新 previous 别名指向原 live 版本
 4. 更新 live 别名指向 latest 版本并清除灰度配置
 5. 显示版本变更信息`,
 	Run: runPromote,
@@ -36,7 +35,7 @@ func init() {
 }
 
 func runPromote(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
+	ctx := cmd.Context()
 
 	// 1. 验证环境参数
 	if err := ValidateEnv(env); err != nil {
@@ -63,34 +62,38 @@ func runPromote(cmd *cobra.Command, args []string) {
 	output.Separator()
 
 	// 4. 创建 AWS Lambda 客户端
-	lambdaClient, err := aws.NewClient(ctx, awsProfile)
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
 	if err != nil {
-		output.Error("创建 AWS 客户端失败: %v", err)
-		os.Exit(exitcode.AWSError)
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
 		return
 	}
 
-	// 5. 获取 live 和 latest 别名的版本 (需求 6.1)
-	output.Info("获取别名版本...")
-	liveVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "live")
-	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+	// 获取部署锁，执行期间其他操作者不能修改别名
+	_, release, ok := acquireLock(ctx, lambdaClient, functionName, "promote")
+	if !ok {
 		return
 	}
-	output.Info("live 别名: 版本 %s", liveVersion)
+	defer release()
 
-	latestVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "latest")
-	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+	// 记录审计日志，命令结束时写入执行前后的别名状态和结果
+	done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	defer done()
+
+	// 5. 获取别名版本并计算部署状态 (需求 6.1)
+	snapshot, ok := getDeploymentState(ctx, lambdaClient, functionName)
+	if !ok {
 		return
 	}
-	output.Info("latest 别名: 版本 %s", latestVersion)
+	live, previous := snapshot.Live, snapshot.Previous
+	liveVersion, latestVersion := live.Version, snapshot.Latest.Version
 
-	// 6. 检查 live 和 latest 是否指向同一版本 (需求 6.2)
-	if liveVersion == latestVersion {
+	// 6. 检查当前状态是否有新版本需要切换 (需求 6.2)
+	if rule := snapshot.Check(state.Promote); rule.Outcome != state.Allowed {
 		output.Separator()
-		output.Warning("live 和 latest 已指向同一版本 (%s)", liveVersion)
-		output.Warning("没有新版本需要切换，跳过 promote 操作")
+		output.Warning("%s，跳过 promote 操作", rule.Reason)
 		output.Info("")
 		output.Info("可能的原因:")
 		output.Info("  - sam deploy 没有检测到代码变化")
@@ -102,12 +105,15 @@ func runPromote(cmd *cobra.Command, args []string) {
 
 	// 7. 检查灰度状态 (需求 6.3, 6.6)
 	if !skipCanary {
-		active, canaryVersion, weight := lambdaClient.CheckCanaryActive(ctx, functionName, "live")
-		if !active {
+		if snapshot.State != state.Canary {
 			// 没有活跃灰度，显示警告但继续执行 (需求 6.3)
 			output.Warning("没有活跃的灰度配置，建议先执行 canary 命令进行灰度验证")
-		} else {
+		} else if !snapshot.UnexpectedRouting() {
+			canaryVersion, weight, _ := live.Canary()
 			output.Info("检测到活跃灰度配置: 版本 %s, 权重 %.0f%%", canaryVersion, weight*100)
+		} else {
+			// 灰度验证的不是 latest 版本，继续执行会发布未经灰度验证的版本
+			output.Warning("promote 将把 live 切换到版本 %s 并清除上述流量分配", latestVersion)
 		}
 	} else {
 		// 跳过灰度状态检查 (需求 6.6)
@@ -115,23 +121,29 @@ func runPromote(cmd *cobra.Command, args []string) {
 	}
 
 	// 8. 更新 previous 别名指向原 live 版本 (需求 6.4)
+	// 先确认 latest 版本就绪，避免别名更新到一半时失败
+	if !waitForVersionReady(ctx, lambdaClient, functionName, latestVersion) {
+		return
+	}
 	output.Separator()
 	output.Info("更新 previous 别名...")
-	exitCode = lambdaClient.UpdateAlias(ctx, functionName, "previous", liveVersion)
+	_, exitCode := lambdaClient.UpdateAlias(ctx, functionName, "previous", liveVersion, previous.RevisionID)
 	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+		exitFunc(exitCode)
 		return
 	}
 	output.Success("previous 别名已更新到版本 %s", liveVersion)
 
-	// 9. 更新 live 别名指向 latest 版本并清除灰度配置 (需求 6.5)
+	// 9. 更新 live 别名指向 latest 版本并清除灰度配置和 auto 进度 (需求 6.5)
 	output.Info("更新 live 别名...")
-	exitCode = lambdaClient.UpdateAlias(ctx, functionName, "live", latestVersion)
+	_, exitCode = lambdaClient.UpdateAliasWithDescription(ctx, functionName, "live", latestVersion, "", live.RevisionID)
 	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+		warnPartialPromote(liveVersion)
+		exitFunc(exitCode)
 		return
 	}
 	output.Success("live 别名已更新到版本 %s", latestVersion)
+	recordRelease(ctx, lambdaClient, functionName, liveVersion, latestVersion)
 
 	// 10. 显示版本变更信息 (需求 6.7)
 	output.Separator()
@@ -145,3 +157,10 @@ func runPromote(cmd *cobra.Command, args []string) {
 	output.Info("  部署新版本: lad deploy --env %s", env)
 	output.Info("  回退到上一版本: lad rollback --env %s", env)
 }
+
+// warnPartialPromote 报告 previous 已更新、live 更新失败的状态（promote 和 auto 的 promote 步骤）
+// 任何失败（修改冲突、限流、网络、权限）都会留下只更新了一半的别名，需要确认后重新 promote
+func warnPartialPromote(liveVersion string) {
+	output.Warning("previous 别名已更新到版本 %s，live 别名未更新", liveVersion)
+	output.Info("请使用 'lad status --env %s' 确认状态后重新执行 'lad promote --env %s'", env, env)
+}
@@ -0,0 +1,289 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/releases"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// prune 命令选项
+	pruneKeepLast  int
+	pruneKeepNewer time.Duration
+	pruneDryRun    bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "清理不再使用的旧版本",
+	Long: `按保留规则删除不再使用的旧版本，释放 Lambda 代码存储空间。
+
+每次 sam deploy 都会发布新版本，旧版本不会被自动删除。
+满足以下任一条件的版本会被保留：
+1. 被任何别名引用（包括灰度路由配置中的版本）
+2. 在发布历史中（rollback --steps/--to 可以回退到的版本）
+3. 属于最近 --keep-last 个版本
+4. 创建时间在 --keep-newer 指定的时间内
+
+示例：
+  lad prune --env prod --dry-run                  # 只显示将被删除的版本
+  lad prune --env prod --keep-last 5              # 保留最近 5 个版本
+  lad prune --env prod --keep-newer 720h          # 同时保留最近 30 天创建的版本`,
+	Run: runPrune,
+}
+
+func init() {
+	pruneCmd.Flags().IntVar(&pruneKeepLast, "keep-last", 10, "保留最近的版本数")
+	pruneCmd.Flags().DurationVar(&pruneKeepNewer, "keep-newer", 0, "保留在指定时间内创建的版本 (例如 720h，0 表示不按时间保留)")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "只显示将被删除的版本，不执行删除")
+	rootCmd.AddCommand(pruneCmd)
+}
+
+// prunePlan 是按保留规则计算出的清理计划
+type prunePlan struct {
+	Keep      []aws.VersionInfo
+	Delete    []aws.VersionInfo
+	Reasons   map[string]string // 保留版本的保留原因
+	Reclaimed int64             // 删除后释放的代码存储（字节）
+}
+
+// planPrune 按保留规则计算需要删除的版本
+// versions 需按版本号降序排列，history 是发布历史，其中的版本是 rollback --steps/--to 的回退目标
+func planPrune(versions []aws.VersionInfo, aliases []*aws.AliasState, history releases.History, keepLast int, keepNewer time.Duration, now time.Time) *prunePlan {
+	// 被别名引用的版本（包括路由配置中的附加版本）
+	referencedBy := make(map[string][]string)
+	for _, alias := range aliases {
+		referencedBy[alias.Version] = append(referencedBy[alias.Version], alias.Name)
+		for version := range alias.Weights {
+			referencedBy[version] = append(referencedBy[version], alias.Name)
+		}
+	}
+
+	plan := &prunePlan{Reasons: make(map[string]string)}
+	for i, v := range versions {
+		var reason string
+		switch {
+		case len(referencedBy[v.Version]) > 0:
+			reason = "被别名引用: " + strings.Join(referencedBy[v.Version], ", ")
+		case history.Index(v.Version) >= 0:
+			reason = "发布历史"
+		case i < keepLast:
+			reason = fmt.Sprintf("最近 %d 个版本", keepLast)
+		case keepNewer > 0 && (v.Created.IsZero() || now.Sub(v.Created) < keepNewer):
+			// 无法确定创建时间的版本按新版本处理，避免误删
+			reason = fmt.Sprintf("创建于 %v 内", keepNewer)
+		}
+
+		if reason != "" {
+			plan.Keep = append(plan.Keep, v)
+			plan.Reasons[v.Version] = reason
+			continue
+		}
+		plan.Delete = append(plan.Delete, v)
+		plan.Reclaimed += v.CodeSize
+	}
+	return plan
+}
+
+func runPrune(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	// 1. 验证参数
+	if err := ValidateEnv(env); err != nil {
+		HandleParamError(err)
+		return
+	}
+	if pruneKeepLast < 1 {
+		HandleParamError(fmt.Errorf("无效的保留数量 '%d'，最小为 1", pruneKeepLast))
+		return
+	}
+	if pruneKeepNewer < 0 {
+		HandleParamError(fmt.Errorf("无效的保留时间 '%v'", pruneKeepNewer))
+		return
+	}
+
+	// 2. 获取函数名
+	functionName, err := GetFunctionName(env)
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 3. 获取 AWS Profile
+	awsProfile := GetProfile(env)
+
+	output.Info("开始清理旧版本...")
+	output.Info("环境: %s", env)
+	output.Info("函数: %s", functionName)
+	output.Info("保留规则: 被别名引用的版本、发布历史中的版本、最近 %d 个版本", pruneKeepLast)
+	if pruneKeepNewer > 0 {
+		output.Info("          以及 %v 内创建的版本", pruneKeepNewer)
+	}
+	if pruneDryRun {
+		output.Info("模式: dry-run（不会删除任何版本）")
+	}
+	if awsProfile != "" {
+		output.Info("Profile: %s", awsProfile)
+	}
+
+	// 4. 创建 AWS Lambda 客户端
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
+		return
+	}
+
+	// 获取部署锁，执行期间其他操作者不能修改别名（dry-run 不修改别名，不加锁也不记录审计日志）
+	if !pruneDryRun {
+		_, release, ok := acquireLock(ctx, lambdaClient, functionName, "prune")
+		if !ok {
+			return
+		}
+		defer release()
+
+		// 记录审计日志，命令结束时写入执行前后的别名状态和结果
+		done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+		if !ok {
+			return
+		}
+		defer done()
+	}
+	output.Separator()
+
+	// 5. 获取版本、别名和代码存储用量
+	output.Info("获取版本和别名...")
+	versions, err := lambdaClient.ListVersions(ctx, functionName)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("获取版本列表失败: %w", err))
+		return
+	}
+	aliases, err := lambdaClient.ListAliases(ctx, functionName)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("获取别名列表失败: %w", err))
+		return
+	}
+	// 无法确定回退需要的版本时不删除任何版本
+	history, err := releases.Load(ctx, lambdaClient, functionName)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("读取发布历史失败: %w", err))
+		return
+	}
+
+	// 代码存储用量只用于展示，获取失败不影响清理
+	storage, err := lambdaClient.GetCodeStorage(ctx)
+	if err != nil {
+		output.Warning("无法获取账户代码存储用量: %v", err)
+	}
+
+	// 6. 计算清理计划
+	plan := planPrune(versions, aliases, history, pruneKeepLast, pruneKeepNewer, time.Now())
+	output.Info("共 %d 个版本，保留 %d 个，删除 %d 个", len(versions), len(plan.Keep), len(plan.Delete))
+
+	output.Separator()
+	output.Info("保留的版本:")
+	for _, v := range plan.Keep {
+		output.Info("  - 版本 %s: %s", v.Version, plan.Reasons[v.Version])
+	}
+
+	if len(plan.Delete) == 0 {
+		output.Separator()
+		output.Success("没有需要删除的版本")
+		printCodeStorage(storage, 0)
+		return
+	}
+
+	output.Separator()
+	if pruneDryRun {
+		output.Info("将删除的版本:")
+	} else {
+		output.Info("删除的版本:")
+	}
+	printPruneTable(plan.Delete)
+
+	// 7. dry-run 只显示结果
+	if pruneDryRun {
+		output.Separator()
+		output.Success("dry-run 完成，预计释放 %s", formatSize(plan.Reclaimed))
+		printCodeStorage(storage, plan.Reclaimed)
+		output.Info("")
+		output.Info("去掉 --dry-run 选项重新执行即可删除上述版本")
+		return
+	}
+
+	// 8. 删除版本，单个版本失败不影响其他版本
+	output.Separator()
+	var reclaimed int64
+	var failed []string
+	failCode := exitcode.Success
+	for _, v := range plan.Delete {
+		if err := lambdaClient.DeleteVersion(ctx, functionName, v.Version); err != nil {
+			output.Error("删除版本 %s 失败: %v", v.Version, err)
+			failed = append(failed, v.Version)
+			failCode = aws.ClassifyError(err)
+			continue
+		}
+		reclaimed += v.CodeSize
+		output.Success("已删除版本 %s", v.Version)
+	}
+
+	// 9. 显示结果
+	output.Separator()
+	if storage != nil {
+		// 重新获取删除后的实际用量
+		if after, err := lambdaClient.GetCodeStorage(ctx); err == nil {
+			storage, reclaimed = after, 0
+		}
+	}
+	if len(failed) > 0 {
+		output.Warning("删除了 %d 个版本，%d 个版本删除失败: %s", len(plan.Delete)-len(failed), len(failed), strings.Join(failed, ", "))
+		printCodeStorage(storage, reclaimed)
+		exitFunc(failCode)
+		return
+	}
+	output.Success("清理完成，删除 %d 个版本，释放 %s", len(plan.Delete), formatSize(plan.Reclaimed))
+	printCodeStorage(storage, reclaimed)
+}
+
+// printPruneTable 以表格形式输出将被删除的版本
+func printPruneTable(versions []aws.VersionInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  版本\t创建时间\t大小\t描述")
+	for _, v := range versions {
+		created := "-"
+		if !v.Created.IsZero() {
+			created = v.Created.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", v.Version, created, formatSize(v.CodeSize), valueOrDash(v.Description))
+	}
+	w.Flush()
+}
+
+// printCodeStorage 输出账户代码存储用量
+// pending 为尚未反映在 storage 中的释放量（dry-run 时的预计值）
+func printCodeStorage(storage *aws.CodeStorage, pending int64) {
+	if storage == nil {
+		return
+	}
+
+	usage := func(used int64) string {
+		if storage.Limit <= 0 {
+			return formatSize(used)
+		}
+		return fmt.Sprintf("%s / %s (%.1f%%)", formatSize(used), formatSize(storage.Limit), float64(used)/float64(storage.Limit)*100)
+	}
+
+	if pending > 0 {
+		output.Info("账户代码存储: %s，删除后约 %s", usage(storage.Used), usage(storage.Used-pending))
+		return
+	}
+	output.Info("账户代码存储: %s", usage(storage.Used))
+}
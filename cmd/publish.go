@@ -0,0 +1,216 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// publish 命令选项
+	publishZipFile     string
+	publishImageURI    string
+	publishDescription string
+	publishWaitTimeout time.Duration
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "从 $LATEST 发布新版本并移动 latest 别名",
+	Long: `从 $LATEST 发布新版本并让 latest 别名指向该版本，用于不通过 SAM 更新代码的场景。
+
+该命令会执行以下操作：
+1. 检查当前状态，存在活跃灰度或 live 与 latest 不一致时阻止发布
+2. 如果指定了 --zip-file 或 --image-uri，先更新 $LATEST 的代码
+3. 等待函数更新完成后发布新版本
+4. 更新 latest 别名指向新版本（live 和 previous 保持不变）
+
+如果 $LATEST 自上次发布后没有变化，Lambda 会返回已有的版本，此时不会移动 latest。
+
+示例：
+  lad publish --env test --zip-file build/function.zip --description $(git rev-parse --short HEAD)
+  lad publish --env prod --image-uri 123456789012.dkr.ecr.us-east-1.amazonaws.com/app:v2
+  lad publish --env test --description "hotfix"   # 发布已通过 update-function-code 更新的代码`,
+	Run: runPublish,
+}
+
+func init() {
+	publishCmd.Flags().StringVar(&publishZipFile, "zip-file", "", "上传的 zip 部署包路径")
+	publishCmd.Flags().StringVar(&publishImageURI, "image-uri", "", "容器镜像 URI")
+	publishCmd.Flags().StringVar(&publishDescription, "description", "", "版本描述 (例如 git SHA)")
+	publishCmd.Flags().DurationVar(&publishWaitTimeout, "wait-timeout", 5*time.Minute, "等待函数更新完成的最长时间")
+	rootCmd.AddCommand(publishCmd)
+}
+
+func runPublish(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	// 1. 验证环境参数
+	if err := ValidateEnv(env); err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 2. 验证代码来源参数
+	if publishZipFile != "" && publishImageURI != "" {
+		HandleParamError(fmt.Errorf("--zip-file 和 --image-uri 不能同时指定"))
+		return
+	}
+	if publishWaitTimeout <= 0 {
+		HandleParamError(fmt.Errorf("无效的等待时间 '%v'", publishWaitTimeout))
+		return
+	}
+	var source *aws.CodeSource
+	switch {
+	case publishZipFile != "":
+		data, err := os.ReadFile(publishZipFile)
+		if err != nil {
+			HandleParamError(fmt.Errorf("无法读取部署包: %w", err))
+			return
+		}
+		source = &aws.CodeSource{ZipFile: data}
+	case publishImageURI != "":
+		source = &aws.CodeSource{ImageURI: publishImageURI}
+	}
+
+	// 3. 获取函数名
+	functionName, err := GetFunctionName(env)
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 4. 获取 AWS Profile
+	awsProfile := GetProfile(env)
+
+	output.Info("开始 Publish...")
+	output.Info("环境: %s", env)
+	output.Info("函数: %s", functionName)
+	switch {
+	case publishZipFile != "":
+		output.Info("部署包: %s (%s)", publishZipFile, formatSize(int64(len(source.ZipFile))))
+	case publishImageURI != "":
+		output.Info("镜像: %s", publishImageURI)
+	}
+	if publishDescription != "" {
+		output.Info("描述: %s", publishDescription)
+	}
+	if awsProfile != "" {
+		output.Info("Profile: %s", awsProfile)
+	}
+	output.Separator()
+
+	// 5. 创建 AWS Lambda 客户端
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
+		return
+	}
+
+	// 获取部署锁，执行期间其他操作者不能修改别名
+	_, release, ok := acquireLock(ctx, lambdaClient, functionName, "publish")
+	if !ok {
+		return
+	}
+	defer release()
+
+	// 记录审计日志，命令结束时写入执行前后的别名状态和结果
+	done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	defer done()
+
+	// 6. 检查当前状态，存在活跃灰度或有待发布版本时阻止发布
+	snapshot, ok := getDeploymentState(ctx, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	live, latest := snapshot.Live, snapshot.Latest
+
+	if !checkReadyForNewVersion(snapshot, "发布") {
+		return
+	}
+
+	// 记录已有版本，用于识别 PublishVersion 返回已有版本的情况
+	versions, err := lambdaClient.ListVersions(ctx, functionName)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("获取版本列表失败: %w", err))
+		return
+	}
+	existing := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		existing[v.Version] = true
+	}
+	output.Separator()
+
+	// 7. 更新 $LATEST 的代码
+	if source != nil {
+		output.Info("更新函数代码...")
+		codeSha256, err := lambdaClient.UpdateFunctionCode(ctx, functionName, *source)
+		if err != nil {
+			HandleAWSError(fmt.Errorf("更新函数代码失败: %w", err))
+			return
+		}
+		output.Info("CodeSha256: %s", codeSha256)
+	}
+
+	// 8. 等待函数更新完成（包括在 lad 之外发起的更新）
+	output.Info("等待函数更新完成...")
+	if err := lambdaClient.WaitForUpdate(ctx, functionName, publishWaitTimeout); err != nil {
+		HandleAWSError(err)
+		return
+	}
+	output.Success("函数更新已完成")
+
+	// 9. 发布新版本
+	output.Info("发布新版本...")
+	version, err := lambdaClient.CreateVersion(ctx, functionName, publishDescription)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("发布版本失败: %w", err))
+		return
+	}
+
+	// $LATEST 没有变化时 PublishVersion 返回已有版本
+	if existing[version] {
+		output.Separator()
+		output.Warning("$LATEST 自上次发布后没有变化，PublishVersion 返回已有版本 %s", version)
+		if version != latest.Version {
+			output.Warning("latest 别名保持版本 %s 不变", latest.Version)
+		}
+		output.Info("")
+		output.Info("如需发布新版本，请先更新代码（--zip-file 或 --image-uri）")
+		return
+	}
+	output.Success("已发布版本 %s", version)
+
+	// 10. 更新 latest 别名
+	_, exitCode := lambdaClient.UpdateAlias(ctx, functionName, "latest", version, latest.RevisionID)
+	if exitCode != exitcode.Success {
+		if exitCode == exitcode.StateChanged {
+			output.Warning("版本 %s 已发布，latest 别名未更新", version)
+		}
+		exitFunc(exitCode)
+		return
+	}
+	output.Success("latest 别名已更新到版本 %s", version)
+
+	// 11. 输出结果
+	output.Separator()
+	output.Success("发布完成!")
+	output.Info("")
+	output.Info("版本变更:")
+	output.Info("  - latest: 版本 %s -> 版本 %s", latest.Version, version)
+	output.Info("  - live: 版本 %s (未变化)", live.Version)
+	output.Info("")
+	output.Info("下一步操作:")
+	output.Info("  开始灰度发布: lad canary --env %s --percent 10", env)
+	output.Info("  自动灰度发布: lad auto --env %s", env)
+	output.Info("  直接发布: lad promote --env %s --skip-canary", env)
+}
@@ -0,0 +1,370 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/patcher"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// reconcile 命令选项
+	reconcileLive     string
+	reconcilePrevious string
+	reconcileTemplate string
+	reconcileRecord   bool
+	reconcileDryRun   bool
+)
+
+// PreDeployRecord 部署前的别名状态
+// lad deploy 在执行 sam deploy 前保存，reconcile 据此恢复被 CloudFormation 移动的 live 和 previous 别名
+type PreDeployRecord struct {
+	Function  string    `json:"function"`
+	Env       string    `json:"env"`
+	Live      string    `json:"live"`
+	Previous  string    `json:"previous"`
+	Latest    string    `json:"latest"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Save 保存记录，目录不存在时自动创建
+func (r *PreDeployRecord) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("无法序列化部署前记录: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("无法创建记录目录: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("无法写入部署前记录: %w", err)
+	}
+	return nil
+}
+
+// LoadPreDeployRecord 读取部署前记录
+// 返回: 记录（文件不存在时为 nil）, 错误
+func LoadPreDeployRecord(path string) (*PreDeployRecord, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取部署前记录: %w", err)
+	}
+
+	var record PreDeployRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("无法解析部署前记录 %s: %w", path, err)
+	}
+	return &record, nil
+}
+
+// preDeployRecordPath 返回环境的部署前记录路径
+func preDeployRecordPath(envValue string) string {
+	return filepath.Join(recordDir, "pre-deploy-"+envValue+".json")
+}
+
+// savePreDeployRecord 保存当前别名状态作为部署前记录
+// 保存失败不影响部署，只输出警告
+func savePreDeployRecord(functionName string, aliases map[string]*aws.AliasState) {
+	record := &PreDeployRecord{
+		Function:  functionName,
+		Env:       env,
+		Live:      aliases["live"].Version,
+		Previous:  aliases["previous"].Version,
+		Latest:    aliases["latest"].Version,
+		Timestamp: time.Now(),
+	}
+	path := preDeployRecordPath(env)
+	if err := record.Save(path); err != nil {
+		output.Warning("%v，部署后将无法使用 'lad reconcile' 自动恢复别名", err)
+		return
+	}
+	output.Info("部署前别名状态已记录到: %s", path)
+}
+
+// removePreDeployRecord 删除部署前记录，记录已使用或不再需要时调用
+func removePreDeployRecord() {
+	if err := os.Remove(preDeployRecordPath(env)); err != nil && !os.IsNotExist(err) {
+		output.Warning("无法删除部署前记录: %v", err)
+	}
+}
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "恢复被 sam deploy 移动的 live 和 previous 别名",
+	Long: `恢复被 sam deploy 移动的 live 和 previous 别名。
+
+lad patch 生成的 LiveAlias 和 PreviousAlias 引用 Version 资源的版本号，
+CloudFormation 更新时可能把 live 和 previous 移动到新发布的版本，跳过灰度发布。
+lad deploy 在执行 sam deploy 前记录 live、previous 的版本，该命令会执行以下操作：
+1. 读取部署前记录（或 --live、--previous 指定的版本）
+2. 报告模板声明的别名指向、当前指向和 lad 流程期望的指向之间的差异
+3. 将 live 和 previous 恢复到期望的版本，latest 保持指向新版本
+
+直接执行 sam deploy 时，可以先使用 --record 记录当前状态。
+
+示例：
+  lad reconcile --env prod                         # 按部署前记录恢复
+  lad reconcile --env prod --dry-run               # 只报告差异
+  lad reconcile --env prod --live 12 --previous 11 # 恢复到指定版本
+  lad reconcile --env prod --record                # 在 sam deploy 前记录当前状态`,
+	Run: runReconcile,
+}
+
+func init() {
+	reconcileCmd.Flags().StringVar(&reconcileLive, "live", "", "live 别名期望的版本 (默认使用部署前记录)")
+	reconcileCmd.Flags().StringVar(&reconcilePrevious, "previous", "", "previous 别名期望的版本 (默认使用部署前记录)")
+	reconcileCmd.Flags().StringVar(&reconcileTemplate, "template", "template.yaml", "用于检查别名声明的模板文件路径")
+	reconcileCmd.Flags().BoolVar(&reconcileRecord, "record", false, "记录当前的别名状态，供 sam deploy 之后恢复")
+	reconcileCmd.Flags().BoolVar(&reconcileDryRun, "dry-run", false, "只报告差异，不修改别名")
+	rootCmd.AddCommand(reconcileCmd)
+}
+
+func runReconcile(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	// 1. 验证环境参数
+	if err := ValidateEnv(env); err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 2. 验证版本参数
+	for _, version := range []string{reconcileLive, reconcilePrevious} {
+		if version == "$LATEST" {
+			HandleParamError(fmt.Errorf("别名不能指向 $LATEST，请指定已发布的版本号"))
+			return
+		}
+	}
+	if reconcileRecord && (reconcileLive != "" || reconcilePrevious != "") {
+		HandleParamError(fmt.Errorf("--record 不能与 --live、--previous 同时指定"))
+		return
+	}
+
+	// 3. 获取函数名
+	functionName, err := GetFunctionName(env)
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 4. 获取 AWS Profile
+	awsProfile := GetProfile(env)
+
+	output.Info("开始 Reconcile...")
+	output.Info("环境: %s", env)
+	output.Info("函数: %s", functionName)
+	if awsProfile != "" {
+		output.Info("Profile: %s", awsProfile)
+	}
+	output.Separator()
+
+	// 5. 创建 AWS Lambda 客户端
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
+		return
+	}
+
+	// 获取部署锁，执行期间其他操作者不能修改别名（dry-run 不修改别名，不加锁也不记录审计日志）
+	if !reconcileDryRun {
+		_, release, ok := acquireLock(ctx, lambdaClient, functionName, "reconcile")
+		if !ok {
+			return
+		}
+		defer release()
+
+		// 记录审计日志，命令结束时写入执行前后的别名状态和结果
+		done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+		if !ok {
+			return
+		}
+		defer done()
+	}
+
+	// 6. 获取别名状态
+	output.Info("获取别名版本...")
+	aliases := make(map[string]*aws.AliasState)
+	for _, name := range managedAliases {
+		state, ok := getAliasState(ctx, lambdaClient, functionName, name)
+		if !ok {
+			return
+		}
+		aliases[name] = state
+	}
+	live, previous, latest := aliases["live"], aliases["previous"], aliases["latest"]
+
+	// 7. --record 只记录当前状态
+	if reconcileRecord {
+		output.Info("live 别名: 版本 %s", live.Version)
+		output.Info("previous 别名: 版本 %s", previous.Version)
+		output.Info("latest 别名: 版本 %s", latest.Version)
+		if live.HasRouting() {
+			output.Warning("live 别名存在活跃的灰度配置 (%s)，恢复时将被清除", live.RoutingSummary())
+		}
+		savePreDeployRecord(functionName, aliases)
+		return
+	}
+
+	// 8. 确定 live 和 previous 期望的版本
+	// 命令行参数优先，其次是部署前记录
+	record, err := LoadPreDeployRecord(preDeployRecordPath(env))
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+	if record != nil && record.Function != functionName {
+		HandleParamError(fmt.Errorf("部署前记录属于函数 %s，与当前函数 %s 不一致", record.Function, functionName))
+		return
+	}
+
+	want := map[string]string{"live": reconcileLive, "previous": reconcilePrevious}
+	if record != nil {
+		output.Info("部署前记录 (%s): live 版本 %s, previous 版本 %s", record.Timestamp.Local().Format(time.RFC3339), record.Live, record.Previous)
+		if want["live"] == "" {
+			want["live"] = record.Live
+		}
+		if want["previous"] == "" {
+			want["previous"] = record.Previous
+		}
+	}
+	if want["live"] == "" || want["previous"] == "" {
+		output.Error("没有找到部署前记录: %s", preDeployRecordPath(env))
+		output.Info("请使用 --live 和 --previous 指定期望的版本，或在 sam deploy 前执行 'lad reconcile --env %s --record'", env)
+		exitFunc(exitcode.ParamError)
+		return
+	}
+
+	// 9. 报告模板声明、当前指向和期望指向之间的差异
+	output.Separator()
+	declared := loadDeclaredAliases(reconcileTemplate)
+	var drifted []string
+	for _, name := range managedAliases {
+		current := aliases[name]
+		line := fmt.Sprintf("  - %s: 当前版本 %s", name, current.Version)
+		if name == "latest" {
+			line += " (跟随新版本)"
+		} else if current.Version != want[name] {
+			line += fmt.Sprintf("，期望版本 %s", want[name])
+			drifted = append(drifted, name)
+		} else {
+			line += " (与期望一致)"
+		}
+		if target, ok := declared[name]; ok {
+			line += fmt.Sprintf("，模板声明 %s", target.FunctionVersion)
+		}
+		output.Info("%s", line)
+	}
+
+	// 模板让 live 或 previous 跟随新版本时，每次部署都会再次移动它们
+	for _, name := range []string{"live", "previous"} {
+		target, ok := declared[name]
+		if !ok || !target.FollowsNewVersion() {
+			continue
+		}
+		output.Warning("模板中 %s 的 FunctionVersion 为 %s，sam deploy 发布新版本时 CloudFormation 会把 %s 移动到新版本", target.Resource, target.FunctionVersion, name)
+		if aliases[name].Version != latest.Version {
+			output.Warning("CloudFormation 认为 %s 指向版本 %s，实际为版本 %s（堆栈漂移）", name, latest.Version, aliases[name].Version)
+		}
+	}
+
+	if len(drifted) == 0 {
+		output.Separator()
+		output.Success("live 和 previous 与期望一致，无需恢复")
+		if !reconcileDryRun {
+			removePreDeployRecord()
+		}
+		return
+	}
+
+	// 10. 验证期望的版本仍然存在（可能已被 prune 删除）
+	for _, name := range drifted {
+		if exitCode := lambdaClient.VerifyVersionExists(ctx, functionName, want[name]); exitCode != exitcode.Success {
+			exitFunc(exitCode)
+			return
+		}
+	}
+
+	if reconcileDryRun {
+		output.Separator()
+		output.Info("Dry-run 完成，未修改任何别名")
+		output.Info("执行恢复: lad reconcile --env %s", env)
+		return
+	}
+
+	// 11. 恢复别名
+	output.Separator()
+	if exitCode := restoreAliases(ctx, lambdaClient, functionName, drifted, aliases, want); exitCode != exitcode.Success {
+		exitFunc(exitCode)
+		return
+	}
+	removePreDeployRecord()
+
+	// 12. 输出结果
+	output.Separator()
+	output.Success("Reconcile 完成!")
+	output.Info("")
+	output.Info("版本变更:")
+	for _, name := range drifted {
+		output.Info("  - %s: 版本 %s -> 版本 %s", name, aliases[name].Version, want[name])
+	}
+	output.Info("  - latest: 版本 %s (未变化)", latest.Version)
+	output.Info("")
+	output.Info("下一步操作:")
+	if want["live"] != latest.Version {
+		output.Info("  开始灰度发布: lad canary --env %s --percent 10", env)
+	}
+	output.Info("  查看当前状态: lad status --env %s", env)
+}
+
+// restoreAliases 将 names 中的别名恢复到 want 中的版本，names 中 live 在前，先恢复承载生产流量的 live
+// 使用 aliases 中读取到的 RevisionId，读取后别名被修改时返回 StateChanged
+// 返回: 退出码（失败时已输出错误，由调用方退出）
+func restoreAliases(ctx context.Context, lambdaClient *aws.Client, functionName string, names []string, aliases map[string]*aws.AliasState, want map[string]string) int {
+	var restored []string
+	for _, name := range names {
+		output.Info("恢复 %s 别名...", name)
+		var exitCode int
+		if name == "live" {
+			// 同时清除 live 描述中的 auto 进度，恢复后进度已经过时
+			_, exitCode = lambdaClient.UpdateAliasWithDescription(ctx, functionName, name, want[name], "", aliases[name].RevisionID)
+		} else {
+			_, exitCode = lambdaClient.UpdateAlias(ctx, functionName, name, want[name], aliases[name].RevisionID)
+		}
+		if exitCode != exitcode.Success {
+			if exitCode == exitcode.StateChanged && len(restored) > 0 {
+				output.Warning("%s 别名已恢复，%s 别名未恢复", restored[0], name)
+			}
+			return exitCode
+		}
+		output.Success("%s 别名已恢复到版本 %s", name, want[name])
+		restored = append(restored, name)
+	}
+	return exitcode.Success
+}
+
+// loadDeclaredAliases 读取模板中声明的 lad 别名，按别名名称索引
+// 模板不存在或无法读取时返回空结果（reconcile 可以在没有模板的目录中执行）
+func loadDeclaredAliases(path string) map[string]patcher.AliasTarget {
+	declared := make(map[string]patcher.AliasTarget)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		output.Info("未读取模板文件 %s，跳过别名声明检查", path)
+		return declared
+	}
+	for _, target := range patcher.ParseAliasTargets(string(content)) {
+		declared[target.Name] = target
+	}
+	return declared
+}
@@ -6,17 +6,32 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/aura-studio/lad/internal/aws"
 	"github.com/aura-studio/lad/internal/exitcode"
 	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/releases"
+	"github.com/aura-studio/lad/internal/state"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// rollback 命令选项
-	reason string
+	reason             string
+	rollbackToPrevious bool
+	rollbackSteps      int
+	rollbackTo         string
+)
+
+const (
+	// RollbackActionRollback 表示 live 回退到 previous 版本
+	RollbackActionRollback = "rollback"
+	// RollbackActionAbort 表示取消灰度，live 保持当前稳定版本
+	RollbackActionAbort = "abort"
+	// RollbackActionInterrupt 表示 auto 被中断，FROM_VERSION 为灰度版本，TO_VERSION 为 live 的稳定版本
+	RollbackActionInterrupt = "interrupt"
 )
 
 // RollbackLog 回退日志条目
@@ -27,12 +42,13 @@ type RollbackLog struct {
 	ToVersion   string
 	Reason      string
 	Operator    string // 从 USER 环境变量获取
+	Action      string // RollbackActionRollback、RollbackActionAbort 或 RollbackActionInterrupt，为空时不输出
 }
 
 // Format 格式化日志条目
-// 格式: [timestamp] ENV=env FROM_VERSION=from TO_VERSION=to REASON="reason" OPERATOR=operator
+// 格式: [timestamp] ENV=env FROM_VERSION=from TO_VERSION=to REASON="reason" OPERATOR=operator [ACTION=action]
 func (l *RollbackLog) Format() string {
-	return fmt.Sprintf("[%s] ENV=%s FROM_VERSION=%s TO_VERSION=%s REASON=\"%s\" OPERATOR=%s",
+	line := fmt.Sprintf("[%s] ENV=%s FROM_VERSION=%s TO_VERSION=%s REASON=\"%s\" OPERATOR=%s",
 		l.Timestamp.Format(time.RFC3339),
 		l.Env,
 		l.FromVersion,
@@ -40,6 +56,10 @@ func (l *RollbackLog) Format() string {
 		l.Reason,
 		l.Operator,
 	)
+	if l.Action != "" {
+		line += " ACTION=" + l.Action
+	}
+	return line
 }
 
 // AppendToFile 追加到日志文件
@@ -60,7 +80,12 @@ func (l *RollbackLog) AppendToFile(path string) error {
 	return nil
 }
 
-// getExecutablePath 获取可执行文件所在目录
+// rollbackLogPath 返回 rollback.log 的路径，与默认的审计日志一样保存在本地记录目录
+func rollbackLogPath() string {
+	return filepath.Join(recordDir, "rollback.log")
+}
+
+// getExecutablePath 获取可执行文件所在目录（旧版本在这里写入 rollback.log）
 func getExecutablePath() (string, error) {
 	execPath, err := os.Executable()
 	if err != nil {
@@ -74,28 +99,51 @@ var rollbackCmd = &cobra.Command{
 	Short: "紧急回退到上一个稳定版本",
 	Long: `紧急回退到上一个稳定版本。
 
-该命令会执行以下操作：
+存在活跃灰度或待发布版本时（live 与 latest 不一致），默认取消发布：
+1. 清除 live 别名的灰度配置，live 保持当前稳定版本
+2. latest 别名重置为 live 的版本，并标记被取消发布的版本
+3. 记录回退日志到 rollback.log 文件
+
+没有待发布版本，或指定 --to-previous 时，回退到 previous：
 1. 获取 live 和 previous 别名的版本
 2. 检查是否需要回退（版本是否相同）
-3. 更新 live 别名指向 previous 版本并清除灰度配置
-4. 记录回退日志到 rollback.log 文件
-5. 显示回退结果和下一步操作提示`,
+3. 更新 live 和 latest 别名指向 previous 版本并清除灰度配置
+4. 更新 previous 别名指向发布历史中更早的版本，可以继续回退
+5. 记录回退日志到 rollback.log 文件
+
+lad 在函数标签 lad:releases 中记录依次晋升到 live 的版本（发布历史）。
+--steps N 沿发布历史回退 N 个版本，--to 回退到发布历史中的指定版本，
+在任何状态下都会回退（清除灰度配置），比目标版本新的版本从发布历史中移除。
+
+示例：
+  lad rollback --env prod --reason "错误率上升"   # 灰度中：取消灰度；稳定态：回退到 previous
+  lad rollback --env prod --to-previous          # 无论当前状态都回退到 previous
+  lad rollback --env prod --steps 2              # 回退到两次发布之前的版本
+  lad rollback --env prod --to 12                # 回退到发布历史中的版本 12`,
 	Run: runRollback,
 }
 
 func init() {
 	rollbackCmd.Flags().StringVar(&reason, "reason", "", "回退原因")
+	rollbackCmd.Flags().BoolVar(&rollbackToPrevious, "to-previous", false, "回退到 previous 版本（即使当前存在活跃灰度或待发布版本）")
+	rollbackCmd.Flags().IntVar(&rollbackSteps, "steps", 0, "沿发布历史回退的版本数")
+	rollbackCmd.Flags().StringVar(&rollbackTo, "to", "", "回退到发布历史中的指定版本")
 	rootCmd.AddCommand(rollbackCmd)
 }
 
 func runRollback(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
+	ctx := cmd.Context()
 
-	// 1. 验证环境参数
+	// 1. 验证参数
 	if err := ValidateEnv(env); err != nil {
 		HandleParamError(err)
 		return
 	}
+	if err := validateRollbackTarget(cmd); err != nil {
+		HandleParamError(err)
+		return
+	}
+	walkHistory := rollbackSteps > 0 || rollbackTo != ""
 
 	// 2. 获取函数名
 	functionName, err := GetFunctionName(env)
@@ -116,36 +164,81 @@ func runRollback(cmd *cobra.Command, args []string) {
 	output.Separator()
 
 	// 4. 创建 AWS Lambda 客户端
-	lambdaClient, err := aws.NewClient(ctx, awsProfile)
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
 	if err != nil {
-		output.Error("创建 AWS 客户端失败: %v", err)
-		os.Exit(exitcode.AWSError)
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
 		return
 	}
 
-	// 5. 获取 live 和 previous 别名的版本 (需求 7.1)
-	output.Info("获取别名版本...")
-	liveVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "live")
-	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+	// 获取部署锁，执行期间其他操作者不能修改别名
+	_, release, ok := acquireLock(ctx, lambdaClient, functionName, "rollback")
+	if !ok {
 		return
 	}
-	output.Info("live 别名: 版本 %s", liveVersion)
+	defer release()
 
-	previousVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "previous")
-	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+	// 记录审计日志，命令结束时写入执行前后的别名状态和结果
+	done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	defer done()
+
+	// 5. 获取别名版本并计算部署状态 (需求 7.1)
+	snapshot, ok := getDeploymentState(ctx, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	live := snapshot.Live
+	liveVersion, previousVersion := live.Version, snapshot.Previous.Version
+
+	// 读取发布历史，读取失败时只能回退到 previous
+	history, err := releases.Load(ctx, lambdaClient, functionName)
+	if err != nil {
+		output.Warning("读取发布历史失败: %v，只能回退到 previous", err)
+	}
+	chain := history.Chain(liveVersion, previousVersion)
+
+	// 6. 指定 --steps 或 --to 时沿发布历史回退
+	if walkHistory {
+		target, err := historyTarget(chain)
+		if err != nil {
+			output.Separator()
+			output.Error("回退失败: %v", err)
+			output.Info("发布历史: %s", formatChain(chain))
+			output.Info("")
+			output.Info("建议操作:")
+			output.Info("  - 使用 'lad switch --version <版本号>' 切换到不在发布历史中的版本")
+			output.Info("  - 使用 'lad versions --env %s' 查看已发布的版本", env)
+			exitFunc(exitcode.ParamError)
+			return
+		}
+		output.Info("发布历史: %s", formatChain(chain))
+		rollbackToVersion(ctx, lambdaClient, functionName, snapshot, chain, target)
 		return
 	}
-	output.Info("previous 别名: 版本 %s", previousVersion)
 
-	// 6. 检查 live 和 previous 是否指向同一版本 (需求 7.2)
-	if liveVersion == previousVersion {
+	// 7. 灰度态或待验证态时取消发布，live 保持当前稳定版本
+	if !rollbackToPrevious && snapshot.Check(state.Abort).Outcome == state.Allowed {
+		abortRelease(ctx, lambdaClient, functionName, live, snapshot.Latest, rollbackReason())
+		return
+	}
+
+	// 8. 检查是否有可回退的历史版本 (需求 7.2)
+	if rule := snapshot.Check(state.Rollback); rule.Outcome != state.Allowed {
 		output.Separator()
-		output.Error("回退失败: live 和 previous 已指向同一版本 (%s)", liveVersion)
+		output.Error("回退失败: %s", rule.Reason)
 		output.Info("")
 		output.Info("无法执行回退的原因:")
 		output.Info("  - live 和 previous 别名都指向版本 %s", liveVersion)
+		if len(chain) > 1 {
+			output.Info("")
+			output.Info("发布历史: %s", formatChain(chain))
+			output.Info("建议操作:")
+			output.Info("  - 使用 'lad rollback --env %s --steps 1' 回退到版本 %s", env, chain[1])
+			exitFunc(exitcode.ParamError)
+			return
+		}
 		output.Info("  - 没有可回退的历史版本")
 		output.Info("")
 		output.Info("可能的情况:")
@@ -155,76 +248,250 @@ func runRollback(cmd *cobra.Command, args []string) {
 		output.Info("建议操作:")
 		output.Info("  - 使用 'lad switch --version <版本号>' 切换到指定版本")
 		output.Info("  - 使用 'lad status' 查看当前别名状态")
-		os.Exit(exitcode.ParamError)
+		exitFunc(exitcode.ParamError)
 		return
 	}
 
-	// 7. 更新 live 别名指向 previous 版本并清除灰度配置 (需求 7.3)
+	// 9. 回退到 previous
+	rollbackToVersion(ctx, lambdaClient, functionName, snapshot, chain, previousVersion)
+}
+
+// validateRollbackTarget 验证 --steps、--to 和 --to-previous 的组合
+func validateRollbackTarget(cmd *cobra.Command) error {
+	stepsSet := cmd.Flags().Changed("steps")
+	toSet := cmd.Flags().Changed("to")
+	if stepsSet && rollbackSteps < 1 {
+		return fmt.Errorf("无效的回退步数 '%d'，最小为 1", rollbackSteps)
+	}
+	if toSet && rollbackTo == "" {
+		return fmt.Errorf("--to 不能为空")
+	}
+	if stepsSet && toSet {
+		return fmt.Errorf("--steps 和 --to 不能同时指定")
+	}
+	if (stepsSet || toSet) && rollbackToPrevious {
+		return fmt.Errorf("--to-previous 不能与 --steps 或 --to 同时指定")
+	}
+	return nil
+}
+
+// historyTarget 根据 --steps 或 --to 返回回退链中的目标版本
+func historyTarget(chain releases.History) (string, error) {
+	if rollbackSteps > 0 {
+		return chain.Step(rollbackSteps)
+	}
+	switch i := chain.Index(rollbackTo); {
+	case i == 0:
+		return "", fmt.Errorf("live 已指向版本 %s", rollbackTo)
+	case i < 0:
+		return "", fmt.Errorf("版本 %s 不在发布历史中", rollbackTo)
+	}
+	return rollbackTo, nil
+}
+
+// formatChain 格式化回退链，例如 "5 (live) -> 4 -> 3"
+func formatChain(chain releases.History) string {
+	if len(chain) == 0 {
+		return "-"
+	}
+	return chain[0] + " (live)" + strings.TrimPrefix(strings.Join(chain, " -> "), chain[0])
+}
+
+// rollbackToVersion 将 live 和 latest 回退到 target 并清除灰度配置
+// previous 更新为回退链中 target 之前的版本，发布历史中比 target 新的版本被移除
+func rollbackToVersion(ctx context.Context, lambdaClient *aws.Client, functionName string, snapshot *state.Snapshot, chain releases.History, target string) {
+	live, previous, latest := snapshot.Live, snapshot.Previous, snapshot.Latest
+	liveVersion := live.Version
+	remaining := chain.RollbackTo(target)
+	nextPrevious := remaining.Previous()
+
+	if live.HasRouting() {
+		output.Info("live 别名的灰度配置将在回退后清除")
+	}
+
+	// 确认回退目标和回退后的 previous 仍然存在（可能已被 lad prune 等删除），避免只修改了部分别名
+	versions, err := lambdaClient.ListVersions(ctx, functionName)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("获取版本列表失败，别名未修改: %w", err))
+		return
+	}
+	published := make(map[string]bool)
+	for _, v := range versions {
+		published[v.Version] = true
+	}
+	if !published[target] {
+		output.Error("回退目标版本 %s 已不存在，别名未修改", target)
+		output.Info("使用 'lad versions --env %s' 查看已发布的版本，'lad switch --version <版本号>' 切换到其他版本", env)
+		exitFunc(exitcode.ResourceNotFound)
+		return
+	}
+	if !published[nextPrevious] {
+		output.Warning("回退链中的版本 %s 已不存在，previous 别名将指向版本 %s", nextPrevious, target)
+		nextPrevious = target
+	}
+
+	// 1. 更新 live 别名指向目标版本并清除灰度配置和 auto 进度 (需求 7.3)
 	output.Separator()
 	output.Info("更新 live 别名...")
-	exitCode = lambdaClient.UpdateAlias(ctx, functionName, "live", previousVersion)
+	_, exitCode := lambdaClient.UpdateAliasWithDescription(ctx, functionName, "live", target, "", live.RevisionID)
 	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+		exitFunc(exitCode)
 		return
 	}
-	output.Success("live 别名已更新到版本 %s", previousVersion)
+	output.Success("live 别名已更新到版本 %s", target)
 
-	// 8. 同时更新 latest 别名，防止下次 promote 又推上问题版本
+	// 2. 同时更新 latest 别名，防止下次 promote 又推上问题版本
 	output.Info("更新 latest 别名...")
-	exitCode = lambdaClient.UpdateAlias(ctx, functionName, "latest", previousVersion)
+	_, exitCode = lambdaClient.UpdateAlias(ctx, functionName, "latest", target, latest.RevisionID)
 	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+		if exitCode == exitcode.StateChanged {
+			output.Warning("live 别名已回退到版本 %s，latest 别名未更新", target)
+		}
+		exitFunc(exitCode)
 		return
 	}
-	output.Success("latest 别名已更新到版本 %s", previousVersion)
+	output.Success("latest 别名已更新到版本 %s", target)
 
-	// 8. 记录回退日志 (需求 7.4, 7.5, 7.6, 7.7)
-	rollbackReason := reason
-	if rollbackReason == "" {
-		rollbackReason = "未指定原因" // 需求 7.7
+	// 3. 更新 previous 别名指向更早的版本，之后可以继续回退
+	if nextPrevious != previous.Version {
+		output.Info("更新 previous 别名...")
+		_, exitCode = lambdaClient.UpdateAlias(ctx, functionName, "previous", nextPrevious, previous.RevisionID)
+		if exitCode != exitcode.Success {
+			if exitCode == exitcode.StateChanged {
+				output.Warning("live 和 latest 别名已回退到版本 %s，previous 别名未更新", target)
+			}
+			exitFunc(exitCode)
+			return
+		}
+		output.Success("previous 别名已更新到版本 %s", nextPrevious)
 	}
 
-	operator := os.Getenv("USER")
-	if operator == "" {
-		operator = "unknown"
+	// 4. 保存发布历史，写入失败不影响回退结果
+	if err := releases.Save(ctx, lambdaClient, functionName, remaining); err != nil {
+		output.Warning("保存发布历史失败: %v", err)
 	}
 
-	rollbackLog := &RollbackLog{
-		Timestamp:   time.Now(),
-		Env:         env,
-		FromVersion: liveVersion,
-		ToVersion:   previousVersion,
-		Reason:      rollbackReason,
-		Operator:    operator,
+	// 5. 记录回退日志 (需求 7.4, 7.5, 7.6, 7.7)
+	rollbackLog := writeRollbackLog(RollbackActionRollback, liveVersion, target, rollbackReason())
+
+	// 6. 显示回退结果和下一步操作提示 (需求 7.8)
+	output.Separator()
+	output.Success("Rollback 完成!")
+	output.Info("")
+	output.Info("版本变更:")
+	output.Info("  - live: 版本 %s -> 版本 %s", liveVersion, target)
+	output.Info("  - latest: -> 版本 %s", target)
+	if nextPrevious != previous.Version {
+		output.Info("  - previous: 版本 %s -> 版本 %s", previous.Version, nextPrevious)
 	}
+	output.Info("")
+	output.Info("回退信息:")
+	output.Info("  - 原因: %s", rollbackLog.Reason)
+	output.Info("  - 操作人: %s", rollbackLog.Operator)
+	output.Info("")
+	output.Info("下一步操作:")
+	output.Info("  查看当前状态: lad status --env %s", env)
+	output.Info("  部署新版本: lad deploy --env %s", env)
+	if nextPrevious != target {
+		output.Info("  继续回退到版本 %s: lad rollback --env %s", nextPrevious, env)
+	}
+}
 
-	// 获取可执行文件所在目录
-	execDir, err := getExecutablePath()
-	if err != nil {
-		output.Warning("无法获取可执行文件路径，日志将写入当前目录: %v", err)
-		execDir = "."
+// abortRelease 取消灰度或待发布版本，rejectReason 记录在 latest 的描述和回退日志中
+// 清除 live 的灰度配置（live 保持当前稳定版本），latest 重置为 live 的版本并标记被取消的版本
+// 返回: 是否成功，失败时已调用 exitFunc
+func abortRelease(ctx context.Context, lambdaClient *aws.Client, functionName string, live, latest *aws.AliasState, rejectReason string) bool {
+	// 被取消的版本通常是 latest；latest 已与 live 相同时取灰度路由中的版本
+	rejected := latest.Version
+	if rejected == live.Version {
+		if versions := live.RoutedVersions(); len(versions) > 0 {
+			rejected = versions[0]
+		}
 	}
-	logPath := filepath.Join(execDir, "rollback.log")
 
-	if err := rollbackLog.AppendToFile(logPath); err != nil {
-		output.Warning("无法写入回退日志: %v", err)
+	output.Separator()
+	if live.HasRouting() {
+		output.Info("检测到活跃的灰度配置，取消灰度发布: 版本 %s", rejected)
 	} else {
-		output.Info("回退日志已记录到: %s", logPath)
+		output.Info("检测到待发布版本，取消发布: 版本 %s", rejected)
+	}
+	output.Info("live 保持稳定版本 %s (如需回退到 previous，请使用 --to-previous)", live.Version)
+
+	// 1. 清除灰度配置和 auto 进度，尽快停止把流量路由到问题版本
+	cleared := false
+	if live.HasRouting() {
+		output.Info("清除灰度配置...")
+		_, exitCode := lambdaClient.UpdateAliasWithDescription(ctx, functionName, "live", live.Version, "", live.RevisionID)
+		if exitCode != exitcode.Success {
+			exitFunc(exitCode)
+			return false
+		}
+		cleared = true
+		output.Success("灰度配置已清除，live 100%% 流量到版本 %s", live.Version)
+	}
+
+	// 2. 重置 latest 并标记被取消的版本，防止之后 canary 或 promote 再次发布它
+	output.Info("重置 latest 别名...")
+	_, exitCode := lambdaClient.UpdateAliasWithDescription(ctx, functionName, "latest", live.Version, aws.RejectedDescription(rejected, rejectReason), latest.RevisionID)
+	if exitCode != exitcode.Success {
+		if exitCode == exitcode.StateChanged && cleared {
+			output.Warning("灰度配置已清除，latest 别名未重置")
+		}
+		exitFunc(exitCode)
+		return false
 	}
+	output.Success("latest 别名已重置到版本 %s，版本 %s 已标记为取消发布", live.Version, rejected)
 
-	// 10. 显示回退结果和下一步操作提示 (需求 7.8)
+	// 3. 记录回退日志
+	rollbackLog := writeRollbackLog(RollbackActionAbort, rejected, live.Version, rejectReason)
+
+	// 4. 显示结果和下一步操作提示
 	output.Separator()
-	output.Success("Rollback 完成!")
+	output.Success("灰度已取消!")
 	output.Info("")
 	output.Info("版本变更:")
-	output.Info("  - live: 版本 %s -> 版本 %s", liveVersion, previousVersion)
-	output.Info("  - latest: -> 版本 %s", previousVersion)
+	output.Info("  - live: 版本 %s (100%%)", live.Version)
+	output.Info("  - latest: 版本 %s -> 版本 %s", latest.Version, live.Version)
+	output.Info("  - 取消发布: 版本 %s", rejected)
 	output.Info("")
 	output.Info("回退信息:")
-	output.Info("  - 原因: %s", rollbackReason)
-	output.Info("  - 操作人: %s", operator)
+	output.Info("  - 原因: %s", rollbackLog.Reason)
+	output.Info("  - 操作人: %s", rollbackLog.Operator)
 	output.Info("")
 	output.Info("下一步操作:")
-	output.Info("  查看当前状态: lad status --env %s", env)
-	output.Info("  部署新版本: lad deploy --env %s", env)
+	output.Info("  部署修复后的版本: lad deploy --env %s", env)
+	output.Info("  回退到上一版本: lad rollback --env %s --to-previous", env)
+	return true
+}
+
+// rollbackReason 返回回退原因，未指定时为默认值 (需求 7.7)
+func rollbackReason() string {
+	if reason == "" {
+		return "未指定原因"
+	}
+	return reason
+}
+
+// writeRollbackLog 记录回退日志到本地记录目录的 rollback.log
+// 审计日志中有相同的记录，目录不可写时（例如 CI 中只读的工作目录）直接跳过，不影响回退结果
+func writeRollbackLog(action, fromVersion, toVersion, logReason string) *RollbackLog {
+	rollbackLog := &RollbackLog{
+		Timestamp:   time.Now(),
+		Env:         env,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Reason:      logReason,
+		Operator:    currentOperator(),
+		Action:      action,
+	}
+
+	logPath := rollbackLogPath()
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return rollbackLog
+	}
+	if err := rollbackLog.AppendToFile(logPath); err != nil {
+		return rollbackLog
+	}
+	output.Info("回退日志已记录到: %s", logPath)
+	return rollbackLog
 }
@@ -2,14 +2,24 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime/debug"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/aura-studio/lad/internal/aws"
 	"github.com/aura-studio/lad/internal/config"
 	"github.com/aura-studio/lad/internal/exitcode"
 	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/releases"
+	"github.com/aura-studio/lad/internal/state"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // getVersion 获取版本信息
@@ -24,14 +34,71 @@ func getVersion() string {
 
 var (
 	// 全局选项
-	env      string // 环境 (test/prod)，默认 test
-	profile  string // AWS Profile
-	function string // Lambda 函数名
+	env         string // 环境 (test/prod)，默认 test
+	profile     string // AWS Profile
+	region      string // AWS Region
+	function    string // Lambda 函数名
+	endpointURL string // Lambda API 地址（可指向 lad emulator）
+
+	// 重试选项
+	retryMaxAttempts int           // 最大尝试次数
+	retryMaxElapsed  time.Duration // 最长重试时间
+
+	readyTimeout time.Duration // 等待版本就绪的最长时间
+
+	// 部署锁选项
+	lockBackend string        // 部署锁存储 (tags|file|none)
+	lockTTL     time.Duration // 部署锁租约时长
+
+	auditLog []string // 审计日志输出
 )
 
 // samconfigPath 是 samconfig.toml 的路径，可在测试中覆盖
 var samconfigPath = "samconfig.toml"
 
+// recordDir 是 lad 在本地保存记录（例如部署前的别名状态）的目录，可在测试中覆盖
+var recordDir = ".lad"
+
+// ClientFactory 创建 Lambda 客户端的函数
+type ClientFactory func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error)
+
+// clientFactory 创建 Lambda 客户端，可在测试中替换为内存实现
+var clientFactory ClientFactory = aws.NewClient
+
+// MetricsFactory 创建 CloudWatch 客户端的函数
+type MetricsFactory func(ctx context.Context, opts aws.ClientOptions) (aws.MetricsAPI, error)
+
+// metricsFactory 创建 auto 健康检查使用的 CloudWatch 客户端，可在测试中替换为内存实现
+var metricsFactory MetricsFactory = newCloudWatch
+
+// newCloudWatch 是 metricsFactory 的默认实现
+func newCloudWatch(ctx context.Context, opts aws.ClientOptions) (aws.MetricsAPI, error) {
+	return aws.NewCloudWatch(ctx, opts)
+}
+
+// exitFunc 是命令失败时的退出函数，可在测试中覆盖
+// 调用方在调用后必须立即 return，因为测试中的实现不会终止进程
+var exitFunc = os.Exit
+
+// deferExit 注册命令结束时执行的 fn（例如释放部署锁、写入审计日志），fn 只执行一次
+// 命令通过 exitFunc 退出时先以退出码调用 fn 再退出；命令正常结束时调用方需 defer 返回的函数，此时退出码为 Success
+// 多次注册时后注册的先执行，与 defer 的顺序一致
+func deferExit(fn func(code int)) func() {
+	var once sync.Once
+	exit := exitFunc
+	run := func(code int) {
+		once.Do(func() {
+			exitFunc = exit
+			fn(code)
+		})
+	}
+	exitFunc = func(code int) {
+		run(code)
+		exit(code)
+	}
+	return func() { run(exitcode.Success) }
+}
+
 var rootCmd = &cobra.Command{
 	Use:     "lad",
 	Short:   "Lambda Alias Deployment - Lambda 函数灰度发布工具",
@@ -47,12 +114,215 @@ var rootCmd = &cobra.Command{
 func init() {
 	rootCmd.PersistentFlags().StringVar(&env, "env", "test", "指定环境 (test|prod)")
 	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "AWS Profile 名称")
+	rootCmd.PersistentFlags().StringVar(&region, "region", "", "AWS Region (默认读取 samconfig.toml 或 AWS 配置)")
 	rootCmd.PersistentFlags().StringVar(&function, "function", "", "Lambda 函数名称")
+	rootCmd.PersistentFlags().StringVar(&endpointURL, "endpoint-url", "", "Lambda API 地址 (例如 lad emulator 的 http://127.0.0.1:4575)")
+	defaultRetry := aws.DefaultRetryPolicy()
+	rootCmd.PersistentFlags().IntVar(&retryMaxAttempts, "retry-max-attempts", defaultRetry.MaxAttempts, "限流或冲突时的最大尝试次数 (1 表示不重试)")
+	rootCmd.PersistentFlags().DurationVar(&retryMaxElapsed, "retry-max-elapsed", defaultRetry.MaxElapsed, "限流或冲突时的最长重试时间 (0 表示不限制)")
+	rootCmd.PersistentFlags().DurationVar(&readyTimeout, "ready-timeout", 5*time.Minute, "切换流量前等待版本就绪的最长时间 (0 表示只检查一次)")
+	rootCmd.PersistentFlags().StringVar(&lockBackend, "lock", lockBackendFile, "部署锁存储 (file: 本地文件, tags: 函数标签，需要 lambda:TagResource、UntagResource、ListTags 权限, none: 不加锁)")
+	rootCmd.PersistentFlags().DurationVar(&lockTTL, "lock-ttl", 15*time.Minute, "部署锁租约时长，持有者异常退出后锁在到期后自动失效")
+	rootCmd.PersistentFlags().StringArrayVar(&auditLog, "audit-log", nil, "审计日志输出，可重复指定 (文件路径、stdout、webhook:<URL>、none；默认 .lad/audit.log)")
 }
 
 // Execute 执行根命令
+// 收到 SIGINT 或 SIGTERM 时取消命令的 ctx，命令在正在执行的 AWS 调用或等待处停止并收尾（auto 按 --on-interrupt 处理灰度）
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := notifyInterrupt(context.Background())
+	defer stop()
+	return rootCmd.ExecuteContext(ctx)
+}
+
+// notifyInterrupt 返回收到 SIGINT 或 SIGTERM 时取消的 ctx，context.Cause 为收到的信号
+// 第一次收到信号后恢复默认的信号处理，收尾时间过长时再次发送信号可以立即终止进程
+func notifyInterrupt(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			output.Warning("收到信号 %v，正在停止 (再次发送信号立即终止)", sig)
+			cancel(fmt.Errorf("收到信号 %v", sig))
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
+
+// ExecuteArgs 使用指定参数执行根命令（用于测试）
+// 执行前会将所有选项恢复为默认值，避免上一次执行的选项残留
+func ExecuteArgs(args []string) error {
+	return ExecuteArgsContext(context.Background(), args)
+}
+
+// ExecuteArgsContext 使用指定参数和 ctx 执行根命令（用于测试）
+// 取消 ctx 与收到 SIGINT 或 SIGTERM 的效果相同
+func ExecuteArgsContext(ctx context.Context, args []string) error {
+	resetFlags(rootCmd)
+	resetContext(rootCmd)
+	rootCmd.SetArgs(args)
+	return rootCmd.ExecuteContext(ctx)
+}
+
+// resetContext 递归地清除子命令保存的 ctx
+// cobra 只在子命令没有 ctx 时从根命令继承，不清除时会沿用上一次执行（可能已取消）的 ctx
+func resetContext(c *cobra.Command) {
+	for _, sub := range c.Commands() {
+		sub.SetContext(nil)
+		resetContext(sub)
+	}
+}
+
+// resetFlags 递归地将命令及其子命令的选项恢复为默认值
+func resetFlags(c *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			sv.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	c.Flags().VisitAll(reset)
+	c.PersistentFlags().VisitAll(reset)
+	for _, sub := range c.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// newLambdaClient 创建 Lambda 客户端，并输出实际访问的 region 和 endpoint
+func newLambdaClient(ctx context.Context, awsProfile string) (*aws.Client, error) {
+	lambdaClient, err := createLambdaClient(ctx, awsProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	// 输出实际访问的 region 和 endpoint，便于确认操作对象
+	if lambdaClient.Region() != "" {
+		output.Info("Region: %s", lambdaClient.Region())
+	}
+	if lambdaClient.Endpoint() != "" {
+		output.Info("Endpoint: %s", lambdaClient.Endpoint())
+	}
+	return lambdaClient, nil
+}
+
+// createLambdaClient 创建 Lambda 客户端，不输出任何信息（用于 JSON 输出）
+func createLambdaClient(ctx context.Context, awsProfile string) (*aws.Client, error) {
+	retry, err := GetRetryPolicy(env)
+	if err != nil {
+		return nil, &aws.Error{Op: "LoadConfig", Code: exitcode.ParamError, Err: err}
+	}
+
+	return clientFactory(ctx, aws.ClientOptions{
+		Profile:     awsProfile,
+		Region:      GetRegion(env),
+		EndpointURL: endpointURL,
+		Retry:       retry,
+	})
+}
+
+// getAliasState 获取别名状态，失败时输出错误并以对应退出码退出
+// 返回: 别名状态, 是否成功
+func getAliasState(ctx context.Context, lambdaClient *aws.Client, functionName, aliasName string) (*aws.AliasState, bool) {
+	state, err := lambdaClient.GetAliasState(ctx, functionName, aliasName)
+	if err != nil {
+		err = fmt.Errorf("获取 %s 别名失败: %w", aliasName, err)
+		// 别名不存在时提示使用 init-aliases 创建
+		if aws.ClassifyError(err) == exitcode.ResourceNotFound {
+			output.Error("%s", err.Error())
+			output.Info("使用 'lad init-aliases --env %s' 创建缺失的别名", env)
+			exitFunc(exitcode.ResourceNotFound)
+			return nil, false
+		}
+		HandleAWSError(err)
+		return nil, false
+	}
+	return state, true
+}
+
+// getManagedAliases 获取 lad 管理的别名状态，不存在的别名不包含在结果中
+// 其他错误（权限、网络等）输出错误并以对应退出码退出
+// 返回: 别名状态, 是否成功
+func getManagedAliases(ctx context.Context, lambdaClient *aws.Client, functionName string) (map[string]*aws.AliasState, bool) {
+	states := make(map[string]*aws.AliasState)
+	for _, name := range managedAliases {
+		state, err := lambdaClient.GetAliasState(ctx, functionName, name)
+		if err != nil {
+			if aws.ClassifyError(err) != exitcode.ResourceNotFound {
+				HandleAWSError(fmt.Errorf("获取 %s 别名失败: %w", name, err))
+				return nil, false
+			}
+			continue
+		}
+		states[name] = state
+	}
+	return states, true
+}
+
+// waitForVersionReady 等待版本就绪（State 为 Active 且 LastUpdateStatus 为 Successful）
+// 版本为 Failed 或超时时输出错误并以 VersionNotReady 退出，调用方不应再修改 live 别名
+// 返回: 是否就绪
+func waitForVersionReady(ctx context.Context, lambdaClient *aws.Client, functionName, version string) bool {
+	output.Info("检查版本 %s 状态...", version)
+	if err := lambdaClient.WaitForVersionReady(ctx, functionName, version, readyTimeout); err != nil {
+		HandleAWSError(fmt.Errorf("检查版本状态失败，live 别名未修改: %w", err))
+		return false
+	}
+	output.Info("版本 %s 已就绪", version)
+	return true
+}
+
+// recordRelease 在发布历史中记录 live 从 from 切换到 to，供 rollback --steps/--to 使用
+// 还没有发布历史时先记录 from；写入失败只输出警告，不影响命令结果
+func recordRelease(ctx context.Context, lambdaClient *aws.Client, functionName, from, to string) {
+	history, err := releases.Load(ctx, lambdaClient, functionName)
+	if err == nil {
+		if history.Index(from) < 0 {
+			history = history.Push(from)
+		}
+		err = releases.Save(ctx, lambdaClient, functionName, history.Push(to))
+	}
+	if err != nil {
+		output.Warning("记录发布历史失败: %v，rollback --steps/--to 将无法回退到版本 %s", err, from)
+	}
+}
+
+// getDeploymentState 获取 live、previous、latest 别名并计算部署状态
+// 任一别名获取失败时输出错误并以对应退出码退出
+// 返回: 部署状态, 是否成功
+func getDeploymentState(ctx context.Context, lambdaClient *aws.Client, functionName string) (*state.Snapshot, bool) {
+	output.Info("获取别名版本...")
+	aliases := make(map[string]*aws.AliasState)
+	for _, name := range []string{"live", "previous", "latest"} {
+		alias, ok := getAliasState(ctx, lambdaClient, functionName, name)
+		if !ok {
+			return nil, false
+		}
+		output.Info("%s 别名: 版本 %s", name, alias.Version)
+		aliases[name] = alias
+	}
+
+	snapshot := state.Compute(aliases["live"], aliases["previous"], aliases["latest"])
+	printDeploymentState(snapshot)
+	return snapshot, true
+}
+
+// printDeploymentState 输出部署状态和检测到的异常
+func printDeploymentState(snapshot *state.Snapshot) {
+	output.Info("部署状态: %s (%s)", snapshot.State.Label(), snapshot.State)
+	if snapshot.Live.HasRouting() {
+		output.Info("live 别名当前流量分配: %s", snapshot.Live.RoutingSummary())
+	}
+	for _, anomaly := range snapshot.Anomalies {
+		output.Warning("状态异常: %s", anomaly)
+	}
 }
 
 // ValidateEnv 验证环境参数
@@ -105,6 +375,61 @@ func GetProfile(envValue string) string {
 	return samConfig.GetProfile(envValue)
 }
 
+// GetRegion 获取 AWS Region
+// 优先级: --region > samconfig.toml
+// 如果都未指定，返回空字符串（使用 AWS Profile 或环境变量中的配置）
+func GetRegion(envValue string) string {
+	// 优先使用 --region 选项
+	if region != "" {
+		return region
+	}
+
+	// 尝试从 samconfig.toml 读取
+	samConfig, err := config.LoadSAMConfig(samconfigPath)
+	if err != nil {
+		return ""
+	}
+
+	return samConfig.GetRegion(envValue)
+}
+
+// GetRetryPolicy 获取重试策略
+// 优先级: --retry-max-attempts/--retry-max-elapsed > samconfig.toml 的 [env.lad.parameters] > 默认值
+func GetRetryPolicy(envValue string) (aws.RetryPolicy, error) {
+	policy := aws.DefaultRetryPolicy()
+
+	// 尝试从 samconfig.toml 读取
+	if samConfig, err := config.LoadSAMConfig(samconfigPath); err == nil {
+		if attempts := samConfig.GetRetryMaxAttempts(envValue); attempts > 0 {
+			policy.MaxAttempts = attempts
+		}
+		if elapsed := samConfig.GetRetryMaxElapsed(envValue); elapsed != "" {
+			d, err := time.ParseDuration(elapsed)
+			if err != nil || d < 0 {
+				return policy, fmt.Errorf("samconfig.toml 中的 retry_max_elapsed '%s' 无效", elapsed)
+			}
+			policy.MaxElapsed = d
+		}
+	}
+
+	// 命令行选项优先
+	flags := rootCmd.PersistentFlags()
+	if flags.Changed("retry-max-attempts") {
+		if retryMaxAttempts < 1 {
+			return policy, fmt.Errorf("无效的重试次数 '%d'，最小为 1", retryMaxAttempts)
+		}
+		policy.MaxAttempts = retryMaxAttempts
+	}
+	if flags.Changed("retry-max-elapsed") {
+		if retryMaxElapsed < 0 {
+			return policy, fmt.Errorf("无效的重试时间 '%v'", retryMaxElapsed)
+		}
+		policy.MaxElapsed = retryMaxElapsed
+	}
+
+	return policy, nil
+}
+
 // GetEnv 获取当前环境值
 func GetEnv() string {
 	return env
@@ -113,7 +438,7 @@ func GetEnv() string {
 // handleError 处理错误并退出
 func handleError(err error, code int) {
 	output.Error("%s", err.Error())
-	os.Exit(code)
+	exitFunc(code)
 }
 
 // HandleParamError 处理参数错误
@@ -121,6 +446,31 @@ func HandleParamError(err error) {
 	handleError(err, exitcode.ParamError)
 }
 
+// HandleAWSError 处理 AWS 错误
+// 退出码取自 *aws.Error，其他错误视为 AWS 错误；限流、权限和冲突错误会附带处理建议
+func HandleAWSError(err error) {
+	code := exitcode.AWSError
+	var awsErr *aws.Error
+	if errors.As(err, &awsErr) {
+		code = awsErr.Code
+	}
+
+	output.Error("%s", err.Error())
+	switch code {
+	case exitcode.Throttled:
+		output.Info("请求被限流，请稍后重试")
+	case exitcode.Forbidden:
+		output.Info("权限不足或凭证无效，请检查 AWS Profile 和 IAM 权限")
+	case exitcode.Conflict:
+		output.Info("资源正在被其他操作修改，请稍后重试")
+	case exitcode.VersionNotReady:
+		output.Info("Failed 的版本需要修复后重新部署；仍在初始化时可使用 --ready-timeout 延长等待时间")
+	case exitcode.Interrupted:
+		output.Info("命令被中断，请使用 lad status --env %s 确认别名状态", env)
+	}
+	exitFunc(code)
+}
+
 // SetFunction 设置函数名（用于测试）
 func SetFunction(f string) {
 	function = f
@@ -131,7 +481,54 @@ func SetProfile(p string) {
 	profile = p
 }
 
+// SetRegion 设置 AWS Region（用于测试）
+func SetRegion(r string) {
+	region = r
+}
+
+// SetSAMPath 设置 SAM CLI 路径（用于测试）
+func SetSAMPath(path string) {
+	deploySAMPath = path
+}
+
 // SetSamconfigPath 设置 samconfig.toml 路径（用于测试）
 func SetSamconfigPath(path string) {
 	samconfigPath = path
 }
+
+// SetRecordDir 设置本地记录目录（用于测试）
+func SetRecordDir(dir string) {
+	recordDir = dir
+}
+
+// RecordDir 返回本地记录目录（用于测试）
+func RecordDir() string {
+	return recordDir
+}
+
+// SetClientFactory 设置 Lambda 客户端工厂（用于测试）
+// 传入 nil 恢复为使用 AWS 的默认实现
+func SetClientFactory(f ClientFactory) {
+	if f == nil {
+		f = aws.NewClient
+	}
+	clientFactory = f
+}
+
+// SetMetricsFactory 设置 CloudWatch 客户端工厂（用于测试）
+// 传入 nil 恢复为使用 AWS 的默认实现
+func SetMetricsFactory(f MetricsFactory) {
+	if f == nil {
+		f = newCloudWatch
+	}
+	metricsFactory = f
+}
+
+// SetExitFunc 设置退出函数（用于测试）
+// 传入 nil 恢复为 os.Exit
+func SetExitFunc(f func(int)) {
+	if f == nil {
+		f = os.Exit
+	}
+	exitFunc = f
+}
@@ -2,12 +2,11 @@
 package cmd
 
 import (
-	"context"
-	"os"
+	"fmt"
 
-	"github.com/aura-studio/lad/internal/aws"
-	"github.com/aura-studio/lad/internal/exitcode"
 	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/state"
+	"github.com/aura-studio/lad/internal/strategy"
 	"github.com/spf13/cobra"
 )
 
@@ -18,8 +17,9 @@ var statusCmd = &cobra.Command{
 
 该命令会显示：
 1. 三个别名（live、previous、latest）的版本
-2. 如果存在活跃的灰度配置，显示灰度状态和流量分配比例
-3. 根据当前状态提示可用的操作`,
+2. 部署状态（稳定态、待验证态、灰度态、回退态）和检测到的状态异常
+3. 如果存在活跃的灰度配置，显示灰度状态和流量分配比例
+4. 根据当前状态提示可用的操作`,
 	Run: runStatus,
 }
 
@@ -28,7 +28,7 @@ func init() {
 }
 
 func runStatus(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
+	ctx := cmd.Context()
 
 	// 1. 验证环境参数
 	if err := ValidateEnv(env); err != nil {
@@ -55,90 +55,115 @@ func runStatus(cmd *cobra.Command, args []string) {
 	output.Separator()
 
 	// 4. 创建 AWS Lambda 客户端
-	lambdaClient, err := aws.NewClient(ctx, awsProfile)
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
 	if err != nil {
-		output.Error("创建 AWS 客户端失败: %v", err)
-		os.Exit(exitcode.AWSError)
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
 		return
 	}
 
-	// 5. 获取三个别名版本 (需求 9.1, 9.2)
-	output.Info("别名版本:")
-
-	// 获取 live 别名版本
-	liveVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "live")
-	if exitCode != exitcode.Success {
-		// 获取失败显示"未配置" (需求 9.2)
-		liveVersion = "未配置"
+	// 5. 获取三个别名状态 (需求 9.1, 9.2)
+	// 别名不存在显示"未配置"，其他错误（权限、网络等）直接报错退出
+	states, ok := getManagedAliases(ctx, lambdaClient, functionName)
+	if !ok {
+		return
 	}
-	output.Info("  - live: %s", liveVersion)
 
-	// 获取 previous 别名版本
-	previousVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "previous")
-	if exitCode != exitcode.Success {
-		// 获取失败显示"未配置" (需求 9.2)
-		previousVersion = "未配置"
+	aliasVersion := func(name string) string {
+		if alias, ok := states[name]; ok {
+			return alias.Version
+		}
+		return "未配置"
 	}
+	liveVersion := aliasVersion("live")
+	previousVersion := aliasVersion("previous")
+	latestVersion := aliasVersion("latest")
+
+	output.Info("别名版本:")
+	output.Info("  - live: %s", liveVersion)
 	output.Info("  - previous: %s", previousVersion)
+	output.Info("  - latest: %s", latestVersion)
+	if latest, ok := states["latest"]; ok {
+		if rejected, ok := latest.RejectedVersion(); ok {
+			output.Info("  - 已取消发布: %s", rejected)
+		}
+	}
 
-	// 获取 latest 别名版本
-	latestVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "latest")
-	if exitCode != exitcode.Success {
-		// 获取失败显示"未配置" (需求 9.2)
-		latestVersion = "未配置"
+	// 有别名未配置时无法计算部署状态
+	live, previous, latest := states["live"], states["previous"], states["latest"]
+	if live == nil || previous == nil || latest == nil {
+		output.Separator()
+		output.Warning("部分别名未配置")
+		output.Info("")
+		output.Info("可用操作:")
+		output.Info("  创建缺失的别名: lad init-aliases --env %s", env)
+		output.Info("  部署新版本: lad deploy --env %s", env)
+		return
 	}
-	output.Info("  - latest: %s", latestVersion)
 
+	// 6. 计算部署状态 (需求 9.3)
+	snapshot := state.Compute(live, previous, latest)
 	output.Separator()
+	output.Info("部署状态: %s (%s)", snapshot.State.Label(), snapshot.State)
+	for _, anomaly := range snapshot.Anomalies {
+		output.Warning("状态异常: %s", anomaly)
+	}
+	if snapshot.UnexpectedRouting() || len(snapshot.Anomalies) > 0 {
+		output.Warning("别名可能在 lad 之外被修改，请确认后再执行发布操作")
+	}
 
-	// 6. 检查灰度配置 (需求 9.3)
-	active, canaryVersion, weight := lambdaClient.CheckCanaryActive(ctx, functionName, "live")
-
-	if active {
+	// 7. 根据状态显示详情和可用操作 (需求 9.4, 9.5)
+	output.Separator()
+	switch snapshot.State {
+	case state.Canary:
 		// 存在活跃的灰度配置 (需求 9.3)
 		output.Info("灰度状态: 活跃")
-		output.Info("  - 主版本: %s (%.0f%%)", liveVersion, (1-weight)*100)
-		output.Info("  - 灰度版本: %s (%.0f%%)", canaryVersion, weight*100)
-		output.Separator()
+		output.Info("  - 主版本: %s (%.0f%%)", liveVersion, live.PrimaryWeight()*100)
+		for _, version := range live.RoutedVersions() {
+			output.Info("  - 灰度版本: %s (%.0f%%)", version, live.Weights[version]*100)
+		}
+		// auto 在 live 的描述中记录的进度
+		progress, hasProgress := strategy.ParseProgress(live.Description)
+		hasProgress = hasProgress && progress.Version == latestVersion
+		if hasProgress {
+			output.Info("  - auto 进度: 第 %d/%d 步，%s 于 %s 开始 (策略 %s)",
+				progress.Step, progress.Steps, progress.Operator, progress.Started.Local().Format("2006-01-02 15:04:05"), progress.Strategy)
+			switch progress.Control {
+			case strategy.ControlPause:
+				output.Info("  - auto 控制: 已请求暂停 (lad auto resume --env %s 恢复)", env)
+			case strategy.ControlAbort:
+				output.Info("  - auto 控制: 已请求取消")
+			}
+		}
+		output.Info("")
 		output.Info("可用操作:")
 		output.Info("  完成灰度发布: lad promote --env %s", env)
-		output.Info("  回退灰度: lad rollback --env %s", env)
-		output.Info("  调整灰度比例: lad canary --env %s --strategy <strategy>", env)
-	} else {
-		// 不存在灰度配置
-		output.Info("灰度状态: 无")
-
-		// 判断 live 和 latest 是否相同 (需求 9.4, 9.5)
-		// 注意：如果版本是"未配置"，则不进行比较
-		if liveVersion != "未配置" && latestVersion != "未配置" {
-			if liveVersion == latestVersion {
-				// live 等于 latest，系统处于稳定状态 (需求 9.4)
-				output.Separator()
-				output.Success("系统处于稳定状态")
-				output.Info("")
-				output.Info("可用操作:")
-				output.Info("  部署新版本: lad deploy --env %s", env)
-				if previousVersion != "未配置" && previousVersion != liveVersion {
-					output.Info("  回退到上一版本: lad rollback --env %s", env)
-				}
-			} else {
-				// live 不等于 latest，有新版本待发布 (需求 9.5)
-				output.Separator()
-				output.Warning("有新版本待发布")
-				output.Info("  当前版本: %s", liveVersion)
-				output.Info("  待发布版本: %s", latestVersion)
-				output.Info("")
-				output.Info("可用操作:")
-				output.Info("  开始灰度发布: lad canary --env %s --strategy canary10", env)
-				output.Info("  直接发布: lad promote --env %s --skip-canary", env)
-			}
+		output.Info("  取消灰度: lad rollback --env %s", env)
+		output.Info("  调整灰度比例: lad canary --env %s --percent <百分比>", env)
+		if hasProgress {
+			output.Info("  继续中断的 auto: lad auto --env %s --resume", env)
+		}
+	case state.Pending:
+		// live 不等于 latest，有新版本待发布 (需求 9.5)
+		output.Warning("有新版本待发布")
+		output.Info("  当前版本: %s", liveVersion)
+		output.Info("  待发布版本: %s", latestVersion)
+		output.Info("")
+		output.Info("可用操作:")
+		output.Info("  开始灰度发布: lad canary --env %s --percent 10", env)
+		output.Info("  直接发布: lad promote --env %s --skip-canary", env)
+		output.Info("  取消待发布版本: lad rollback --env %s", env)
+	default:
+		// live 等于 latest，系统处于稳定状态 (需求 9.4)
+		if snapshot.State == state.RolledBack {
+			output.Success("系统处于稳定状态 (live 与 previous 相同，没有可回退的历史版本)")
 		} else {
-			// 有别名未配置的情况
-			output.Separator()
-			output.Warning("部分别名未配置")
-			output.Info("")
-			output.Info("可用操作:")
-			output.Info("  部署新版本: lad deploy --env %s", env)
+			output.Success("系统处于稳定状态")
+		}
+		output.Info("")
+		output.Info("可用操作:")
+		output.Info("  部署新版本: lad deploy --env %s", env)
+		if snapshot.Check(state.Rollback).Outcome == state.Allowed {
+			output.Info("  回退到上一版本: lad rollback --env %s", env)
 		}
 	}
 }
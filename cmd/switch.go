@@ -2,13 +2,11 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
-	"os"
 
-	"github.com/aura-studio/lad/internal/aws"
 	"github.com/aura-studio/lad/internal/exitcode"
 	"github.com/aura-studio/lad/internal/output"
+	"github.com/aura-studio/lad/internal/state"
 	"github.com/spf13/cobra"
 )
 
@@ -27,7 +25,7 @@ var switchCmd = &cobra.Command{
 该命令会执行以下操作：
 1. 验证指定版本是否存在
 2. 检查 live 是否已指向目标版本
-3. 更新 live 别名指向指定版本并清除灰度配置
+3. 等待版本就绪后更新 live 别名指向指定版本并清除灰度配置
 4. 注意：此命令不会更新 previous 别名`,
 	Run: runSwitch,
 }
@@ -39,7 +37,7 @@ func init() {
 }
 
 func runSwitch(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
+	ctx := cmd.Context()
 
 	// 1. 验证 --version 参数 (需求 8.1)
 	if switchVersion == "" {
@@ -78,32 +76,65 @@ func runSwitch(cmd *cobra.Command, args []string) {
 	output.Separator()
 
 	// 6. 创建 AWS Lambda 客户端
-	lambdaClient, err := aws.NewClient(ctx, awsProfile)
+	lambdaClient, err := newLambdaClient(ctx, awsProfile)
 	if err != nil {
-		output.Error("创建 AWS 客户端失败: %v", err)
-		os.Exit(exitcode.AWSError)
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
 		return
 	}
 
+	// 获取部署锁，执行期间其他操作者不能修改别名
+	_, release, ok := acquireLock(ctx, lambdaClient, functionName, "switch")
+	if !ok {
+		return
+	}
+	defer release()
+
+	// 记录审计日志，命令结束时写入执行前后的别名状态和结果
+	done, ok := beginAudit(ctx, cmd, lambdaClient, functionName)
+	if !ok {
+		return
+	}
+	defer done()
+
 	// 7. 验证指定版本是否存在 (需求 8.3, 8.4)
 	output.Info("验证版本 %s 是否存在...", switchVersion)
 	exitCode := lambdaClient.VerifyVersionExists(ctx, functionName, switchVersion)
 	if exitCode != exitcode.Success {
 		// 需求 8.4: 如果版本不存在，返回资源不存在错误
-		os.Exit(exitCode)
+		if exitCode == exitcode.ResourceNotFound {
+			output.Info("使用 'lad versions --env %s' 查看已发布的版本", env)
+		}
+		exitFunc(exitCode)
 		return
 	}
 	output.Success("版本 %s 存在", switchVersion)
 
+	// 目标版本曾被 rollback 取消发布时提示（读取失败不影响切换）
+	latest, _ := lambdaClient.GetAliasState(ctx, functionName, "latest")
+	if latest != nil {
+		if rejected, ok := latest.RejectedVersion(); ok && rejected == switchVersion {
+			output.Warning("版本 %s 曾被取消发布: %s", switchVersion, latest.Description)
+		}
+	}
+
 	// 8. 获取 live 别名当前版本
 	output.Info("获取 live 别名当前版本...")
-	liveVersion, exitCode := lambdaClient.GetAliasVersion(ctx, functionName, "live")
-	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+	live, ok := getAliasState(ctx, lambdaClient, functionName, "live")
+	if !ok {
 		return
 	}
+	liveVersion := live.Version
 	output.Info("live 别名: 版本 %s", liveVersion)
 
+	// 显示当前部署状态，switch 在任何状态下都可以执行（previous 或 latest 读取失败不影响切换）
+	if latest != nil {
+		previous, _ := lambdaClient.GetAliasState(ctx, functionName, "previous")
+		printDeploymentState(state.Compute(live, previous, latest))
+	}
+	if live.HasRouting() {
+		output.Info("live 别名的灰度配置将在切换后清除")
+	}
+
 	// 9. 检查 live 是否已指向目标版本 (需求 8.5)
 	if liveVersion == switchVersion {
 		output.Separator()
@@ -116,20 +147,24 @@ func runSwitch(cmd *cobra.Command, args []string) {
 		output.Info("建议操作:")
 		output.Info("  - 使用 'lad status' 查看当前别名状态")
 		output.Info("  - 如需切换到其他版本，请指定不同的 --version 参数")
-		os.Exit(exitcode.ParamError)
+		exitFunc(exitcode.ParamError)
 		return
 	}
 
-	// 10. 更新 live 别名指向指定版本并清除灰度配置 (需求 8.6, 8.7)
+	// 10. 更新 live 别名指向指定版本并清除灰度配置和 auto 进度 (需求 8.6, 8.7)
 	// 注意：不更新 previous 别名 (需求 8.7)
+	if !waitForVersionReady(ctx, lambdaClient, functionName, switchVersion) {
+		return
+	}
 	output.Separator()
 	output.Info("更新 live 别名...")
-	exitCode = lambdaClient.UpdateAlias(ctx, functionName, "live", switchVersion)
+	_, exitCode = lambdaClient.UpdateAliasWithDescription(ctx, functionName, "live", switchVersion, "", live.RevisionID)
 	if exitCode != exitcode.Success {
-		os.Exit(exitCode)
+		exitFunc(exitCode)
 		return
 	}
 	output.Success("live 别名已更新到版本 %s", switchVersion)
+	recordRelease(ctx, lambdaClient, functionName, liveVersion, switchVersion)
 
 	// 11. 显示注意事项 (需求 8.8)
 	output.Separator()
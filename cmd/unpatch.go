@@ -2,8 +2,6 @@
 package cmd
 
 import (
-	"os"
-
 	"github.com/aura-studio/lad/internal/patcher"
 	"github.com/spf13/cobra"
 )
@@ -39,6 +37,15 @@ func init() {
 }
 
 func runUnpatch(cmd *cobra.Command, args []string) {
+	// 记录审计日志，dry-run 不修改模板，不记录
+	if !unpatchDryRun {
+		done, ok := beginAudit(cmd.Context(), cmd, nil, "")
+		if !ok {
+			return
+		}
+		defer done()
+	}
+
 	opts := patcher.UnpatchOptions{
 		TemplatePath: unpatchTemplate,
 		DryRun:       unpatchDryRun,
@@ -47,5 +54,5 @@ func runUnpatch(cmd *cobra.Command, args []string) {
 	}
 
 	result := patcher.Unpatch(opts)
-	os.Exit(result.ExitCode)
+	exitFunc(result.ExitCode)
 }
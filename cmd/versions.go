@@ -0,0 +1,286 @@
+// Package cmd implements the command line interface for the lad tool.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// versions 命令选项
+	versionsLimit   int
+	versionsAliased bool
+	versionsSince   time.Duration
+	versionsOutput  string
+)
+
+// VersionEntry 是 versions 命令输出的一个版本
+type VersionEntry struct {
+	Version       string         `json:"version"`
+	Description   string         `json:"description"`
+	Created       string         `json:"created"`
+	CodeSha256    string         `json:"code_sha256"`
+	CodeSize      int64          `json:"code_size"`
+	Runtime       string         `json:"runtime"`
+	Architectures []string       `json:"architectures"`
+	Aliases       []AliasPointer `json:"aliases"`
+}
+
+// AliasPointer 表示一个别名指向某个版本
+type AliasPointer struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"` // 该别名流量中分配给此版本的比例 (0.0-1.0)
+}
+
+// String 返回别名标记，例如 "live" 或 "live (10%)"
+func (p AliasPointer) String() string {
+	if p.Weight >= 1 {
+		return p.Name
+	}
+	return fmt.Sprintf("%s (%.0f%%)", p.Name, p.Weight*100)
+}
+
+// VersionsReport 是 versions 命令的 JSON 输出
+type VersionsReport struct {
+	Function string         `json:"function"`
+	Region   string         `json:"region,omitempty"`
+	Endpoint string         `json:"endpoint,omitempty"`
+	Total    int            `json:"total"` // 过滤前的版本总数
+	Versions []VersionEntry `json:"versions"`
+}
+
+var versionsCmd = &cobra.Command{
+	Use:   "versions",
+	Short: "列出已发布的版本",
+	Long: `列出函数已发布的版本（按版本号从新到旧）。
+
+每个版本显示：
+1. 版本号、描述和创建时间
+2. CodeSha256、代码大小、运行时和架构
+3. 指向该版本的别名（live、previous、latest 等）及灰度权重
+
+示例：
+  lad versions --env prod                 # 显示最近 20 个版本
+  lad versions --env prod --limit 0       # 显示全部版本
+  lad versions --env prod --aliased       # 只显示被别名引用的版本
+  lad versions --env prod --since 72h     # 只显示最近 3 天创建的版本
+  lad versions --env prod --output json   # 以 JSON 格式输出`,
+	Run: runVersions,
+}
+
+func init() {
+	versionsCmd.Flags().IntVar(&versionsLimit, "limit", 20, "最多显示的版本数 (0 表示全部)")
+	versionsCmd.Flags().BoolVar(&versionsAliased, "aliased", false, "只显示被别名引用的版本")
+	versionsCmd.Flags().DurationVar(&versionsSince, "since", 0, "只显示在指定时间内创建的版本 (例如 72h)")
+	versionsCmd.Flags().StringVar(&versionsOutput, "output", "table", "输出格式 (table|json)")
+	rootCmd.AddCommand(versionsCmd)
+}
+
+func runVersions(cmd *cobra.Command, args []string) {
+	ctx := cmd.Context()
+
+	// 1. 验证参数
+	if err := ValidateEnv(env); err != nil {
+		HandleParamError(err)
+		return
+	}
+	if versionsOutput != "table" && versionsOutput != "json" {
+		HandleParamError(fmt.Errorf("无效的输出格式 '%s'，有效值为: table, json", versionsOutput))
+		return
+	}
+	if versionsLimit < 0 {
+		HandleParamError(fmt.Errorf("无效的数量 '%d'，最小为 0", versionsLimit))
+		return
+	}
+	if versionsSince < 0 {
+		HandleParamError(fmt.Errorf("无效的时间范围 '%v'", versionsSince))
+		return
+	}
+	jsonOutput := versionsOutput == "json"
+
+	// 2. 获取函数名
+	functionName, err := GetFunctionName(env)
+	if err != nil {
+		HandleParamError(err)
+		return
+	}
+
+	// 3. 获取 AWS Profile
+	awsProfile := GetProfile(env)
+
+	// 4. 创建 AWS Lambda 客户端
+	// JSON 输出时不输出其他信息，保证 stdout 可以直接被解析
+	var lambdaClient *aws.Client
+	if jsonOutput {
+		lambdaClient, err = createLambdaClient(ctx, awsProfile)
+	} else {
+		output.Info("Lambda 版本列表")
+		output.Info("环境: %s", env)
+		output.Info("函数: %s", functionName)
+		if awsProfile != "" {
+			output.Info("Profile: %s", awsProfile)
+		}
+		lambdaClient, err = newLambdaClient(ctx, awsProfile)
+	}
+	if err != nil {
+		HandleAWSError(fmt.Errorf("创建 AWS 客户端失败: %w", err))
+		return
+	}
+
+	// 5. 获取版本和别名
+	versions, err := lambdaClient.ListVersions(ctx, functionName)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("获取版本列表失败: %w", err))
+		return
+	}
+	aliases, err := lambdaClient.ListAliases(ctx, functionName)
+	if err != nil {
+		HandleAWSError(fmt.Errorf("获取别名列表失败: %w", err))
+		return
+	}
+
+	// 6. 过滤并生成输出
+	entries := buildVersionEntries(versions, aliases, time.Now())
+
+	if jsonOutput {
+		report := VersionsReport{
+			Function: functionName,
+			Region:   lambdaClient.Region(),
+			Endpoint: lambdaClient.Endpoint(),
+			Total:    len(versions),
+			Versions: entries,
+		}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			output.Error("无法生成 JSON 输出: %v", err)
+			exitFunc(exitcode.AWSError)
+			return
+		}
+		output.Info("%s", data)
+		return
+	}
+
+	output.Separator()
+	if len(entries) == 0 {
+		output.Info("没有符合条件的版本 (共 %d 个版本)", len(versions))
+		return
+	}
+	printVersionTable(entries)
+	output.Separator()
+	output.Info("显示 %d 个版本 (共 %d 个版本)", len(entries), len(versions))
+	output.Info("")
+	output.Info("下一步操作:")
+	output.Info("  切换到指定版本: lad switch --env %s --version <版本号>", env)
+}
+
+// buildVersionEntries 合并版本和别名信息，并按 --aliased、--since、--limit 过滤
+// versions 需按版本号降序排列
+func buildVersionEntries(versions []aws.VersionInfo, aliases []*aws.AliasState, now time.Time) []VersionEntry {
+	// 计算每个版本被哪些别名引用
+	pointers := make(map[string][]AliasPointer)
+	for _, alias := range aliases {
+		pointers[alias.Version] = append(pointers[alias.Version], AliasPointer{Name: alias.Name, Weight: alias.PrimaryWeight()})
+		for _, version := range alias.RoutedVersions() {
+			pointers[version] = append(pointers[version], AliasPointer{Name: alias.Name, Weight: alias.Weights[version]})
+		}
+	}
+
+	entries := []VersionEntry{}
+	for _, v := range versions {
+		if versionsAliased && len(pointers[v.Version]) == 0 {
+			continue
+		}
+		if versionsSince > 0 && !v.Created.IsZero() && now.Sub(v.Created) > versionsSince {
+			continue
+		}
+		if versionsLimit > 0 && len(entries) >= versionsLimit {
+			break
+		}
+
+		entry := VersionEntry{
+			Version:       v.Version,
+			Description:   v.Description,
+			CodeSha256:    v.CodeSha256,
+			CodeSize:      v.CodeSize,
+			Runtime:       v.Runtime,
+			Architectures: v.Architectures,
+			Aliases:       pointers[v.Version],
+		}
+		if !v.Created.IsZero() {
+			entry.Created = v.Created.Format(time.RFC3339)
+		}
+		if entry.Architectures == nil {
+			entry.Architectures = []string{}
+		}
+		if entry.Aliases == nil {
+			entry.Aliases = []AliasPointer{}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// printVersionTable 以表格形式输出版本列表
+func printVersionTable(entries []VersionEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "版本\t别名\t创建时间\tCodeSha256\t大小\t运行时\t架构\t描述")
+	for _, e := range entries {
+		names := make([]string, 0, len(e.Aliases))
+		for _, p := range e.Aliases {
+			names = append(names, p.String())
+		}
+
+		created := "-"
+		if t, err := time.Parse(time.RFC3339, e.Created); err == nil {
+			created = t.Local().Format("2006-01-02 15:04:05")
+		}
+
+		sha := e.CodeSha256
+		if len(sha) > 12 {
+			sha = sha[:12]
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Version,
+			valueOrDash(strings.Join(names, ", ")),
+			created,
+			valueOrDash(sha),
+			formatSize(e.CodeSize),
+			valueOrDash(e.Runtime),
+			valueOrDash(strings.Join(e.Architectures, ",")),
+			valueOrDash(e.Description),
+		)
+	}
+	w.Flush()
+}
+
+// valueOrDash 空值显示为 "-"
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// formatSize 格式化代码大小
+func formatSize(size int64) string {
+	switch {
+	case size >= 1024*1024*1024:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1024*1024*1024))
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
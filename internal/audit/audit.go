@@ -0,0 +1,67 @@
+// Package audit 记录修改别名和模板的命令的审计日志
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Outcome 是命令的执行结果
+type Outcome string
+
+const (
+	// Success 表示命令成功结束（包括无需操作的情况）
+	Success Outcome = "success"
+	// Failure 表示命令以非零退出码结束
+	Failure Outcome = "failure"
+)
+
+// Alias 是别名在某一时刻的状态
+type Alias struct {
+	Version     string             `json:"version"`
+	Weights     map[string]float64 `json:"weights,omitempty"` // 附加版本及其流量权重 (0.0-1.0)
+	Description string             `json:"description,omitempty"`
+}
+
+// Record 是一条审计记录，序列化为一行 JSON
+type Record struct {
+	Timestamp  time.Time         `json:"timestamp"`  // 命令结束时间
+	StartedAt  time.Time         `json:"started_at"` // 命令开始时间
+	Command    string            `json:"command"`
+	Flags      map[string]string `json:"flags,omitempty"` // 命令行中指定的选项
+	Function   string            `json:"function,omitempty"`
+	Env        string            `json:"env,omitempty"`
+	Profile    string            `json:"profile,omitempty"`
+	Region     string            `json:"region,omitempty"`
+	Operator   string            `json:"operator"`
+	Host       string            `json:"host,omitempty"`
+	Reason     string            `json:"reason,omitempty"`
+	Before     map[string]Alias  `json:"before,omitempty"` // 执行前的别名状态
+	After      map[string]Alias  `json:"after,omitempty"`  // 执行后的别名状态
+	Outcome    Outcome           `json:"outcome"`
+	ExitCode   int               `json:"exit_code"`
+	LadVersion string            `json:"lad_version,omitempty"`
+	Legacy     bool              `json:"-"` // 从 rollback.log 格式解析的记录
+}
+
+// Sink 是审计记录的输出
+type Sink interface {
+	// Write 写入一条记录
+	Write(ctx context.Context, record *Record) error
+	// String 返回输出的描述，用于错误信息
+	String() string
+}
+
+// Write 将记录写入所有输出
+// 某个输出失败不影响其他输出，返回所有失败的合并错误
+func Write(ctx context.Context, sinks []Sink, record *Record) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Write(ctx, record); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,206 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// rollbackLinePattern 匹配 rollback.log 的一行
+// 格式: [timestamp] ENV=env FROM_VERSION=from TO_VERSION=to REASON="reason" OPERATOR=operator [ACTION=action]
+// 原因没有转义，可能包含引号，因此匹配到最后一个 `" OPERATOR=`
+var rollbackLinePattern = regexp.MustCompile(`^\[([^\]]+)\] ENV=(\S*) FROM_VERSION=(\S*) TO_VERSION=(\S*) REASON="(.*)" OPERATOR=(\S*)(?: ACTION=(\S+))?$`)
+
+// ParseRollbackLine 将 rollback.log 的一行转换为审计记录
+// 取消灰度（ACTION=abort）记录为 latest 从被取消的版本重置到 live 的版本；
+// auto 被中断（ACTION=interrupt）记录为 auto 命令，live 保持 TO_VERSION；其他记录为 live 从 FROM_VERSION 回退到 TO_VERSION
+// 旧格式没有记录函数名和其他别名的状态
+func ParseRollbackLine(line string) (*Record, error) {
+	m := rollbackLinePattern.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return nil, fmt.Errorf("无法解析回退日志: %s", line)
+	}
+	ts, err := time.Parse(time.RFC3339, m[1])
+	if err != nil {
+		return nil, fmt.Errorf("无法解析回退日志的时间 '%s': %w", m[1], err)
+	}
+	from, to, action := m[3], m[4], m[7]
+
+	record := &Record{
+		Timestamp: ts,
+		StartedAt: ts,
+		Command:   "rollback",
+		Env:       m[2],
+		Operator:  m[6],
+		Reason:    m[5],
+		Outcome:   Success,
+		Legacy:    true,
+	}
+	switch action {
+	case "abort":
+		record.Flags = map[string]string{"action": action}
+		record.Before = map[string]Alias{"live": {Version: to}, "latest": {Version: from}}
+		record.After = map[string]Alias{"live": {Version: to}, "latest": {Version: to}}
+	case "interrupt":
+		// 灰度比例只记录在原因中
+		record.Command = "auto"
+		record.Outcome = Failure
+		record.Flags = map[string]string{"action": action}
+		record.Before = map[string]Alias{"live": {Version: to}, "latest": {Version: from}}
+		record.After = map[string]Alias{"live": {Version: to}, "latest": {Version: from}}
+	default:
+		record.Before = map[string]Alias{"live": {Version: from}}
+		record.After = map[string]Alias{"live": {Version: to}}
+	}
+	return record, nil
+}
+
+// legacyWindow 是 rollback.log 记录与对应的 JSON 记录之间允许的时间差
+// rollback.log 在命令结束前写入且只精确到秒，JSON 记录的时间是命令结束时间
+const legacyWindow = 5 * time.Second
+
+// Dedupe 删除与 JSON 记录重复的 rollback.log 记录
+// rollback、取消灰度和 auto 被中断时同时写入审计日志和 rollback.log，rollback.log 只用于读取旧记录；
+// 环境、操作人和别名版本都相同且时间相差不超过 legacyWindow 的 rollback.log 记录视为重复
+// rollback.log 记录的命令可能与 JSON 记录不同（auto 取消灰度时记录为 rollback），因此不比较命令
+func Dedupe(records []Record) []Record {
+	deduped := make([]Record, 0, len(records))
+	for i := range records {
+		if !records[i].Legacy || !hasDuplicate(records, &records[i]) {
+			deduped = append(deduped, records[i])
+		}
+	}
+	return deduped
+}
+
+// hasDuplicate 判断 legacy 是否有对应的 JSON 记录
+func hasDuplicate(records []Record, legacy *Record) bool {
+	for i := range records {
+		r := &records[i]
+		if r.Legacy || r.Env != legacy.Env || r.Operator != legacy.Operator {
+			continue
+		}
+		if d := r.Timestamp.Sub(legacy.Timestamp); d < -legacyWindow || d > legacyWindow {
+			continue
+		}
+		if sameVersions(legacy.Before, r.Before) && sameVersions(legacy.After, r.After) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameVersions 判断 want 中的别名在 got 中的版本都相同
+func sameVersions(want, got map[string]Alias) bool {
+	for name, alias := range want {
+		if g, ok := got[name]; !ok || g.Version != alias.Version {
+			return false
+		}
+	}
+	return true
+}
+
+// Read 读取审计日志
+// 每行是一条 JSON 记录或 rollback.log 格式的记录，空行忽略
+// 返回: 记录, 无法解析而跳过的行数, 错误
+func Read(r io.Reader) ([]Record, int, error) {
+	var records []Record
+	skipped := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "{"):
+			var record Record
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				skipped++
+				continue
+			}
+			records = append(records, record)
+		default:
+			record, err := ParseRollbackLine(line)
+			if err != nil {
+				skipped++
+				continue
+			}
+			records = append(records, *record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return records, skipped, err
+	}
+	return records, skipped, nil
+}
+
+// ReadFile 读取审计日志文件，见 Read
+func ReadFile(path string) ([]Record, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Filter 是查询审计记录的条件，零值字段不过滤
+type Filter struct {
+	Env      string
+	Function string   // 没有函数名的记录同样匹配
+	Commands []string // 命令名，"auto" 同时匹配其子命令
+	Operator string
+	Since    time.Time // 包含
+	Until    time.Time // 不包含
+}
+
+// Match 判断记录是否满足条件
+func (f Filter) Match(r *Record) bool {
+	if f.Env != "" && r.Env != f.Env {
+		return false
+	}
+	// 没有函数名的记录（旧格式的回退日志）无法判断属于哪个函数，按匹配处理
+	if f.Function != "" && r.Function != "" && r.Function != f.Function {
+		return false
+	}
+	if f.Operator != "" && r.Operator != f.Operator {
+		return false
+	}
+	if !f.Since.IsZero() && r.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !r.Timestamp.Before(f.Until) {
+		return false
+	}
+	if len(f.Commands) == 0 {
+		return true
+	}
+	for _, command := range f.Commands {
+		if r.Command == command || strings.HasPrefix(r.Command, command+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Select 返回满足条件的记录，按时间从早到晚排序
+func Select(records []Record, filter Filter) []Record {
+	selected := []Record{}
+	for i := range records {
+		if filter.Match(&records[i]) {
+			selected = append(selected, records[i])
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].Timestamp.Before(selected[j].Timestamp)
+	})
+	return selected
+}
@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webhookTimeout 是 webhook 请求的超时时间
+const webhookTimeout = 10 * time.Second
+
+// ParseSink 根据描述创建输出
+// 支持 stdout、file:<路径>、webhook:<URL>；以 http:// 或 https:// 开头的值视为 webhook，其他值视为文件路径
+func ParseSink(spec string) (Sink, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return nil, fmt.Errorf("审计日志输出不能为空")
+	case spec == "stdout":
+		return NewWriterSink("stdout", os.Stdout), nil
+	case strings.HasPrefix(spec, "file:"):
+		path := strings.TrimPrefix(spec, "file:")
+		if path == "" {
+			return nil, fmt.Errorf("审计日志输出 '%s' 缺少文件路径", spec)
+		}
+		return NewFileSink(path), nil
+	case strings.HasPrefix(spec, "webhook:"):
+		return NewWebhookSink(strings.TrimPrefix(spec, "webhook:"))
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return NewWebhookSink(spec)
+	}
+	return NewFileSink(spec), nil
+}
+
+// marshal 将记录序列化为一行 JSON
+func marshal(record *Record) ([]byte, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// FileSink 将记录追加到 JSON lines 文件
+type FileSink struct {
+	path string
+}
+
+// NewFileSink 创建追加到 path 的文件输出，目录不存在时自动创建
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Write 实现 Sink
+// 每条记录以一次追加写入完成，多个进程同时写入同一文件时记录不会交错
+func (s *FileSink) Write(ctx context.Context, record *Record) error {
+	data, err := marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("创建审计日志目录失败: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("无法打开审计日志: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("无法写入审计日志: %w", err)
+	}
+	return nil
+}
+
+// Path 返回日志文件路径
+func (s *FileSink) Path() string {
+	return s.path
+}
+
+// String 实现 Sink
+func (s *FileSink) String() string {
+	return s.path
+}
+
+// WriterSink 将记录写入 io.Writer（例如标准输出）
+type WriterSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+// NewWriterSink 创建写入 w 的输出，name 用于错误信息
+func NewWriterSink(name string, w io.Writer) *WriterSink {
+	return &WriterSink{name: name, w: w}
+}
+
+// Write 实现 Sink
+func (s *WriterSink) Write(ctx context.Context, record *Record) error {
+	data, err := marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(data)
+	return err
+}
+
+// String 实现 Sink
+func (s *WriterSink) String() string {
+	return s.name
+}
+
+// WebhookSink 将每条记录以 JSON 请求体 POST 到指定 URL
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink 创建 POST 到 rawURL 的 webhook 输出
+func NewWebhookSink(rawURL string) (*WebhookSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("无效的审计日志 webhook 地址 '%s'", rawURL)
+	}
+	return &WebhookSink{url: rawURL, client: &http.Client{Timeout: webhookTimeout}}, nil
+}
+
+// Write 实现 Sink
+// 响应状态码不是 2xx 时返回错误
+func (s *WebhookSink) Write(ctx context.Context, record *Record) error {
+	data, err := marshal(record)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return redactURL(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return redactURL(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// 只输出状态，不输出请求地址和响应内容
+		return fmt.Errorf("webhook 返回 %s", resp.Status)
+	}
+	return nil
+}
+
+// redactURL 去掉 *url.Error 中的完整 URL，只保留底层错误，避免泄露 URL 中的令牌
+// 错误由 Write 加上 String() 的前缀，其中只有 scheme 和 host
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s 请求失败: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+// String 实现 Sink
+// 只输出 scheme 和 host，避免在日志中泄露 URL 中的令牌
+func (s *WebhookSink) String() string {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return "webhook"
+	}
+	return "webhook " + u.Scheme + "://" + u.Host
+}
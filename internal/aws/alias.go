@@ -0,0 +1,108 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// AliasState 是别名的完整状态
+type AliasState struct {
+	Name        string             // 别名名称
+	Version     string             // 主版本
+	Weights     map[string]float64 // 附加版本及其流量权重 (0.0-1.0)，不包含主版本
+	Description string             // 别名描述
+	RevisionID  string             // 修改别名时用于检测并发修改
+}
+
+// HasRouting 判断别名是否配置了附加版本路由
+func (s *AliasState) HasRouting() bool {
+	return len(s.Weights) > 0
+}
+
+// Canary 返回灰度版本和权重
+// 只有恰好配置了一个附加版本时 ok 为 true
+func (s *AliasState) Canary() (version string, weight float64, ok bool) {
+	if len(s.Weights) != 1 {
+		return "", 0, false
+	}
+	for v, w := range s.Weights {
+		return v, w, true
+	}
+	return "", 0, false
+}
+
+// RoutedVersions 返回附加版本列表（按版本号排序）
+func (s *AliasState) RoutedVersions() []string {
+	versions := make([]string, 0, len(s.Weights))
+	for v := range s.Weights {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versionLess(versions[i], versions[j])
+	})
+	return versions
+}
+
+// PrimaryWeight 返回主版本的流量权重
+func (s *AliasState) PrimaryWeight() float64 {
+	weight := 1.0
+	for _, w := range s.Weights {
+		weight -= w
+	}
+	return weight
+}
+
+// RoutingSummary 返回流量分配的文字描述，例如 "1 (90%), 2 (10%)"
+func (s *AliasState) RoutingSummary() string {
+	parts := []string{fmt.Sprintf("%s (%.0f%%)", s.Version, s.PrimaryWeight()*100)}
+	for _, v := range s.RoutedVersions() {
+		parts = append(parts, fmt.Sprintf("%s (%.0f%%)", v, s.Weights[v]*100))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// rejectedPrefix 是被取消发布的版本在 latest 别名描述中的标记前缀
+const rejectedPrefix = "lad rejected: "
+
+// maxAliasDescription 是 Lambda 别名描述的最大长度
+const maxAliasDescription = 256
+
+// RejectedDescription 生成标记版本被取消发布的别名描述
+// 原因过长时截断（例如 CloudWatch 告警的 StateReason），保证描述不超过 Lambda 的长度限制
+func RejectedDescription(version, reason string) string {
+	description := fmt.Sprintf("%s%s (%s)", rejectedPrefix, version, reason)
+	if len(description) <= maxAliasDescription {
+		return description
+	}
+
+	const ellipsis = "...)"
+	limit := maxAliasDescription - len(ellipsis)
+	// 按字符截断，避免截断多字节字符
+	for limit > 0 && !utf8.RuneStart(description[limit]) {
+		limit--
+	}
+	return description[:limit] + ellipsis
+}
+
+// RejectedVersion 返回别名描述中标记为取消发布的版本
+func (s *AliasState) RejectedVersion() (string, bool) {
+	rest, ok := strings.CutPrefix(s.Description, rejectedPrefix)
+	if !ok {
+		return "", false
+	}
+	version, _, _ := strings.Cut(rest, " ")
+	return version, version != ""
+}
+
+// versionLess 按数字比较版本号，非数字版本（如 $LATEST）按字符串比较
+func versionLess(a, b string) bool {
+	var na, nb int
+	_, errA := fmt.Sscanf(a, "%d", &na)
+	_, errB := fmt.Sscanf(b, "%d", &nb)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return a < b
+}
@@ -0,0 +1,226 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// cloudWatchAPIVersion 是 CloudWatch Query API 的版本
+	cloudWatchAPIVersion = "2010-08-01"
+	// cloudWatchSigningName 是 CloudWatch 请求签名使用的服务名
+	cloudWatchSigningName = "monitoring"
+	// cloudWatchTimeout 是单个 CloudWatch 请求的超时时间
+	cloudWatchTimeout = 30 * time.Second
+)
+
+// 告警状态，见 CloudWatch 的 StateValue
+const (
+	AlarmStateOK               = "OK"
+	AlarmStateAlarm            = "ALARM"
+	AlarmStateInsufficientData = "INSUFFICIENT_DATA"
+)
+
+// MetricsAPI 是灰度健康检查依赖的 CloudWatch API 子集
+// *CloudWatch 和 FakeCloudWatch 都实现了该接口
+type MetricsAPI interface {
+	// DescribeAlarms 返回指定告警（指标告警和复合告警）的状态，不存在的告警不包含在结果中
+	DescribeAlarms(ctx context.Context, names []string) ([]AlarmState, error)
+	// GetMetricValues 返回指标在查询时间范围内每个周期的统计值，从新到旧排列，没有数据时为空
+	GetMetricValues(ctx context.Context, query MetricQuery) ([]float64, error)
+}
+
+// AlarmState 是告警的当前状态
+type AlarmState struct {
+	Name   string // 告警名称
+	State  string // OK、ALARM 或 INSUFFICIENT_DATA
+	Reason string // 状态变化的原因
+}
+
+// MetricQuery 描述一次指标查询
+type MetricQuery struct {
+	Namespace  string            // 命名空间，例如 AWS/Lambda
+	MetricName string            // 指标名称，例如 Errors
+	Dimensions map[string]string // 维度
+	Stat       string            // 统计方式，例如 Sum、Average、p99
+	Period     time.Duration     // 统计周期，必须是 1 分钟的整数倍
+	Start      time.Time         // 起始时间（包含）
+	End        time.Time         // 结束时间（不包含）
+}
+
+// CloudWatch 通过 CloudWatch Query API 读取告警状态和指标
+type CloudWatch struct {
+	creds    aws.CredentialsProvider
+	signer   *v4.Signer
+	client   *http.Client
+	region   string
+	endpoint string
+}
+
+// NewCloudWatch 创建 CloudWatch 客户端
+// Profile 和 Region 的处理与 NewClient 相同；EndpointURL 只用于 Lambda，CloudWatch 始终访问 AWS 默认地址
+func NewCloudWatch(ctx context.Context, clientOpts ClientOptions) (*CloudWatch, error) {
+	awsCfg, err := loadConfig(ctx, clientOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := "https://monitoring." + awsCfg.Region + ".amazonaws.com"
+	if strings.HasPrefix(awsCfg.Region, "cn-") {
+		endpoint += ".cn"
+	}
+	return &CloudWatch{
+		creds:    awsCfg.Credentials,
+		signer:   v4.NewSigner(),
+		client:   &http.Client{Timeout: cloudWatchTimeout},
+		region:   awsCfg.Region,
+		endpoint: endpoint,
+	}, nil
+}
+
+// DescribeAlarms 实现 MetricsAPI
+// 一次最多查询 100 个告警
+func (c *CloudWatch) DescribeAlarms(ctx context.Context, names []string) ([]AlarmState, error) {
+	params := url.Values{}
+	params.Set("AlarmTypes.member.1", "MetricAlarm")
+	params.Set("AlarmTypes.member.2", "CompositeAlarm")
+	params.Set("MaxRecords", "100")
+	for i, name := range names {
+		params.Set(fmt.Sprintf("AlarmNames.member.%d", i+1), name)
+	}
+
+	var resp struct {
+		MetricAlarms    []cloudWatchAlarm `xml:"DescribeAlarmsResult>MetricAlarms>member"`
+		CompositeAlarms []cloudWatchAlarm `xml:"DescribeAlarmsResult>CompositeAlarms>member"`
+	}
+	if err := c.call(ctx, "DescribeAlarms", params, &resp); err != nil {
+		return nil, err
+	}
+
+	var states []AlarmState
+	for _, a := range append(resp.MetricAlarms, resp.CompositeAlarms...) {
+		states = append(states, AlarmState{Name: a.AlarmName, State: a.StateValue, Reason: a.StateReason})
+	}
+	return states, nil
+}
+
+// cloudWatchAlarm 是 DescribeAlarms 响应中的告警
+type cloudWatchAlarm struct {
+	AlarmName   string `xml:"AlarmName"`
+	StateValue  string `xml:"StateValue"`
+	StateReason string `xml:"StateReason"`
+}
+
+// GetMetricValues 实现 MetricsAPI
+func (c *CloudWatch) GetMetricValues(ctx context.Context, query MetricQuery) ([]float64, error) {
+	const prefix = "MetricDataQueries.member.1."
+	params := url.Values{}
+	params.Set("StartTime", query.Start.UTC().Format(time.RFC3339))
+	params.Set("EndTime", query.End.UTC().Format(time.RFC3339))
+	params.Set("ScanBy", "TimestampDescending")
+	params.Set(prefix+"Id", "m0")
+	params.Set(prefix+"MetricStat.Metric.Namespace", query.Namespace)
+	params.Set(prefix+"MetricStat.Metric.MetricName", query.MetricName)
+	params.Set(prefix+"MetricStat.Period", strconv.Itoa(int(query.Period/time.Second)))
+	params.Set(prefix+"MetricStat.Stat", query.Stat)
+	names := make([]string, 0, len(query.Dimensions))
+	for name := range query.Dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		params.Set(fmt.Sprintf("%sMetricStat.Metric.Dimensions.member.%d.Name", prefix, i+1), name)
+		params.Set(fmt.Sprintf("%sMetricStat.Metric.Dimensions.member.%d.Value", prefix, i+1), query.Dimensions[name])
+	}
+
+	var resp struct {
+		Results []struct {
+			Values []float64 `xml:"Values>member"`
+		} `xml:"GetMetricDataResult>MetricDataResults>member"`
+	}
+	if err := c.call(ctx, "GetMetricData", params, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Results) == 0 {
+		return nil, nil
+	}
+	return resp.Results[0].Values, nil
+}
+
+// call 签名并发送 Query API 请求，将 XML 响应解析到 out
+// 错误响应转换为 smithy.APIError，按错误码分类
+func (c *CloudWatch) call(ctx context.Context, action string, params url.Values, out interface{}) error {
+	params.Set("Action", action)
+	params.Set("Version", cloudWatchAPIVersion)
+	body := params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, strings.NewReader(body))
+	if err != nil {
+		return WrapError(action, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	if c.creds == nil {
+		return WrapError(action, &CredentialsError{Err: fmt.Errorf("未配置 AWS 凭证")})
+	}
+	creds, err := c.creds.Retrieve(ctx)
+	if err != nil {
+		return WrapError(action, err)
+	}
+	hash := sha256.Sum256([]byte(body))
+	if err := c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), cloudWatchSigningName, c.region, time.Now()); err != nil {
+		return WrapError(action, err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return WrapError(action, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return WrapError(action, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return WrapError(action, parseCloudWatchError(resp.StatusCode, data))
+	}
+	if err := xml.Unmarshal(data, out); err != nil {
+		return WrapError(action, fmt.Errorf("无法解析 %s 响应: %w", action, err))
+	}
+	return nil
+}
+
+// parseCloudWatchError 将 Query API 的错误响应转换为 smithy.APIError
+func parseCloudWatchError(status int, data []byte) error {
+	var resp struct {
+		Error struct {
+			Type    string `xml:"Type"`
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		} `xml:"Error"`
+	}
+	if err := xml.Unmarshal(data, &resp); err != nil || resp.Error.Code == "" {
+		return fmt.Errorf("CloudWatch 返回 HTTP %d", status)
+	}
+
+	fault := smithy.FaultClient
+	if resp.Error.Type == "Receiver" || status >= 500 {
+		fault = smithy.FaultServer
+	}
+	return &smithy.GenericAPIError{Code: resp.Error.Code, Message: resp.Error.Message, Fault: fault}
+}
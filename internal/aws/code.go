@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// defaultPollInterval 是等待函数更新或版本就绪时的默认查询间隔
+const defaultPollInterval = 2 * time.Second
+
+// CodeSource 是函数代码的来源，ZipFile 和 ImageURI 只能指定一个
+type CodeSource struct {
+	ZipFile  []byte // zip 部署包内容
+	ImageURI string // 容器镜像 URI
+}
+
+// FunctionStatus 是函数或版本的状态
+type FunctionStatus struct {
+	Version                string // 版本号，$LATEST 表示未发布版本
+	CodeSha256             string // 代码包哈希
+	State                  string // Pending / Active / Inactive / Failed
+	StateReason            string
+	LastUpdateStatus       string // InProgress / Successful / Failed
+	LastUpdateStatusReason string
+}
+
+// UpdateFunctionCode 更新 $LATEST 的代码
+// 更新是异步的，需要通过 WaitForUpdate 等待更新完成后才能发布版本
+// 返回: 新的代码哈希, 错误（*Error）
+func (c *Client) UpdateFunctionCode(ctx context.Context, functionName string, source CodeSource) (string, error) {
+	input := &lambda.UpdateFunctionCodeInput{
+		FunctionName: aws.String(functionName),
+	}
+	if len(source.ZipFile) > 0 {
+		input.ZipFile = source.ZipFile
+	}
+	if source.ImageURI != "" {
+		input.ImageUri = aws.String(source.ImageURI)
+	}
+
+	var result *lambda.UpdateFunctionCodeOutput
+	err := c.withRetry(ctx, "UpdateFunctionCode", func() (err error) {
+		result, err = c.client.UpdateFunctionCode(ctx, input)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(result.CodeSha256), nil
+}
+
+// GetFunctionStatus 获取函数或版本的状态
+// qualifier 为空时查询 $LATEST
+// 返回: 状态, 错误（*Error）
+func (c *Client) GetFunctionStatus(ctx context.Context, functionName, qualifier string) (*FunctionStatus, error) {
+	input := &lambda.GetFunctionConfigurationInput{
+		FunctionName: aws.String(functionName),
+	}
+	if qualifier != "" {
+		input.Qualifier = aws.String(qualifier)
+	}
+
+	var result *lambda.GetFunctionConfigurationOutput
+	err := c.withRetry(ctx, "GetFunctionConfiguration", func() (err error) {
+		result, err = c.client.GetFunctionConfiguration(ctx, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &FunctionStatus{
+		Version:                aws.ToString(result.Version),
+		CodeSha256:             aws.ToString(result.CodeSha256),
+		State:                  string(result.State),
+		StateReason:            aws.ToString(result.StateReason),
+		LastUpdateStatus:       string(result.LastUpdateStatus),
+		LastUpdateStatusReason: aws.ToString(result.LastUpdateStatusReason),
+	}, nil
+}
+
+// WaitForUpdate 等待 $LATEST 的代码或配置更新完成
+// 每隔查询间隔查询一次，直到 LastUpdateStatus 不再是 InProgress
+// 返回: 错误（*Error，更新失败或超时时退出码为 AWSError）
+func (c *Client) WaitForUpdate(ctx context.Context, functionName string, timeout time.Duration) error {
+	_, done, err := c.pollStatus(ctx, "WaitForUpdate", functionName, "", timeout, func(status *FunctionStatus) (bool, error) {
+		switch types.LastUpdateStatus(status.LastUpdateStatus) {
+		case types.LastUpdateStatusFailed:
+			return false, &Error{Op: "WaitForUpdate", Code: exitcode.AWSError, Err: fmt.Errorf("函数 %s 更新失败: %s", functionName, status.LastUpdateStatusReason)}
+		case types.LastUpdateStatusInProgress:
+			return false, nil
+		default:
+			return true, nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !done {
+		return &Error{Op: "WaitForUpdate", Code: exitcode.AWSError, Err: fmt.Errorf("等待函数 %s 更新完成超时 (%v)", functionName, timeout)}
+	}
+	return nil
+}
+
+// WaitForVersionReady 等待已发布的版本可以接收流量
+// 版本 State 为 Pending 或 LastUpdateStatus 为 InProgress 时继续等待（SnapStart、VPC 和容器镜像函数的新版本需要一段时间初始化）
+// Inactive 的版本在被调用时会重新激活，视为就绪
+// 返回: 错误（*Error，版本为 Failed 或超时时退出码为 VersionNotReady）
+func (c *Client) WaitForVersionReady(ctx context.Context, functionName, version string, timeout time.Duration) error {
+	last, done, err := c.pollStatus(ctx, "WaitForVersionReady", functionName, version, timeout, func(status *FunctionStatus) (bool, error) {
+		switch {
+		case types.State(status.State) == types.StateFailed:
+			return false, &Error{Op: "WaitForVersionReady", Code: exitcode.VersionNotReady, Err: fmt.Errorf("版本 %s 状态为 Failed: %s", version, status.StateReason)}
+		case types.LastUpdateStatus(status.LastUpdateStatus) == types.LastUpdateStatusFailed:
+			return false, &Error{Op: "WaitForVersionReady", Code: exitcode.VersionNotReady, Err: fmt.Errorf("版本 %s 更新失败: %s", version, status.LastUpdateStatusReason)}
+		case types.State(status.State) == types.StatePending, types.LastUpdateStatus(status.LastUpdateStatus) == types.LastUpdateStatusInProgress:
+			return false, nil
+		default:
+			return true, nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !done {
+		return &Error{Op: "WaitForVersionReady", Code: exitcode.VersionNotReady, Err: fmt.Errorf("等待版本 %s 就绪超时 (%v)，当前状态: %s/%s", version, timeout, last.State, last.LastUpdateStatus)}
+	}
+	return nil
+}
+
+// pollStatus 每隔查询间隔查询一次函数或版本的状态，直到 check 返回完成、返回错误或超时
+// 返回: 最后一次查询的状态, 是否完成（false 表示超时）, 错误
+func (c *Client) pollStatus(ctx context.Context, op, functionName, qualifier string, timeout time.Duration, check func(*FunctionStatus) (bool, error)) (*FunctionStatus, bool, error) {
+	interval := c.pollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		status, err := c.GetFunctionStatus(ctx, functionName, qualifier)
+		if err != nil {
+			return nil, false, err
+		}
+		if done, err := check(status); done || err != nil {
+			return status, done, err
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return status, false, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return status, false, WrapError(op, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
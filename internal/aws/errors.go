@@ -0,0 +1,180 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// Error 是 Lambda 操作失败时返回的错误，携带分类后的退出码
+// cmd 可通过 errors.As 获取退出码
+type Error struct {
+	Op   string // 操作名称，例如 GetAlias
+	Code int    // 退出码，见 exitcode 包
+	Err  error  // 原始错误
+}
+
+// Error 实现 error 接口
+// SDK 的错误信息已包含操作名称，因此直接使用原始错误信息
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WrapError 将错误包装为 *Error 并分类
+// err 为 nil 时返回 nil；已经是 *Error 的错误保持原有分类
+func WrapError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var awsErr *Error
+	if errors.As(err, &awsErr) {
+		return err
+	}
+	return &Error{Op: op, Code: ClassifyError(err), Err: err}
+}
+
+// CredentialsError 表示获取 AWS 凭证失败
+type CredentialsError struct {
+	Err error
+}
+
+// Error 实现 error 接口
+func (e *CredentialsError) Error() string {
+	return fmt.Sprintf("获取 AWS 凭证失败: %v", e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *CredentialsError) Unwrap() error {
+	return e.Err
+}
+
+// credentialsProvider 将凭证获取失败包装为 CredentialsError
+// 凭证链的错误本身没有类型，无法与其他错误区分
+type credentialsProvider struct {
+	provider aws.CredentialsProvider
+}
+
+// Retrieve 实现 aws.CredentialsProvider
+func (p credentialsProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	creds, err := p.provider.Retrieve(ctx)
+	if err != nil {
+		return creds, &CredentialsError{Err: err}
+	}
+	return creds, nil
+}
+
+// 按 API 错误码分类
+var (
+	notFoundCodes = map[string]bool{
+		"ResourceNotFoundException": true,
+		"ResourceNotFound":          true,
+		"NotFoundException":         true,
+	}
+	throttledCodes = map[string]bool{
+		"TooManyRequestsException":               true,
+		"ThrottlingException":                    true,
+		"Throttling":                             true,
+		"ThrottledException":                     true,
+		"RequestThrottled":                       true,
+		"RequestThrottledException":              true,
+		"RequestLimitExceeded":                   true,
+		"ProvisionedThroughputExceededException": true,
+		"SlowDown":                               true,
+	}
+	forbiddenCodes = map[string]bool{
+		"AccessDeniedException":       true,
+		"AccessDenied":                true,
+		"UnauthorizedOperation":       true,
+		"UnrecognizedClientException": true,
+		"InvalidClientTokenId":        true,
+		"InvalidSignatureException":   true,
+		"SignatureDoesNotMatch":       true,
+		"ExpiredToken":                true,
+		"ExpiredTokenException":       true,
+		"MissingAuthenticationToken":  true,
+	}
+	conflictCodes = map[string]bool{
+		"ResourceConflictException": true,
+		"ResourceInUseException":    true,
+	}
+	stateChangedCodes = map[string]bool{
+		"PreconditionFailedException": true,
+	}
+)
+
+// ClassifyError 根据错误类型分类返回退出码
+// 分类顺序:
+//  1. *Error: 使用已分类的退出码
+//  2. 凭证和签名错误: Forbidden
+//  3. API 错误码: ResourceNotFound / Throttled / Forbidden / Conflict / StateChanged
+//  4. ctx 被取消（收到 SIGINT 或 SIGTERM）: Interrupted
+//  5. 网络错误（DNS、连接失败、超时）: NetworkError
+//  6. 其他错误: AWSError
+func ClassifyError(err error) int {
+	if err == nil {
+		return exitcode.Success
+	}
+
+	var awsErr *Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code
+	}
+
+	// 凭证和签名错误
+	var credsErr *CredentialsError
+	var signingErr *v4.SigningError
+	var profileErr config.SharedConfigProfileNotExistError
+	if errors.As(err, &credsErr) || errors.As(err, &signingErr) || errors.As(err, &profileErr) {
+		return exitcode.Forbidden
+	}
+
+	// API 错误
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		switch {
+		case notFoundCodes[code]:
+			return exitcode.ResourceNotFound
+		case throttledCodes[code]:
+			return exitcode.Throttled
+		case forbiddenCodes[code]:
+			return exitcode.Forbidden
+		case conflictCodes[code]:
+			return exitcode.Conflict
+		case stateChangedCodes[code]:
+			return exitcode.StateChanged
+		}
+		return exitcode.AWSError
+	}
+
+	// 命令被中断，请求发送错误也可能包装了 context.Canceled，需要先于网络错误判断
+	if errors.Is(err, context.Canceled) {
+		return exitcode.Interrupted
+	}
+
+	// 网络错误
+	var sendErr *smithyhttp.RequestSendError
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	if errors.As(err, &sendErr) || errors.As(err, &netErr) || errors.As(err, &dnsErr) || errors.As(err, &opErr) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return exitcode.NetworkError
+	}
+
+	// 其他 AWS 错误
+	return exitcode.AWSError
+}
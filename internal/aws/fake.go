@@ -0,0 +1,1014 @@
+// Package aws provides AWS Lambda client functionality.
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+const (
+	// fakeRegion 和 fakeAccountID 用于生成 FakeLambda 中资源的 ARN
+	fakeRegion    = "us-east-1"
+	fakeAccountID = "123456789012"
+	// latestQualifier 表示未发布的 $LATEST 版本
+	latestQualifier = "$LATEST"
+	// fakeCodeStorageLimit 是 Lambda 默认的账户代码存储限额 (75 GB)
+	fakeCodeStorageLimit = 75 * 1024 * 1024 * 1024
+)
+
+// tagPattern 是标签键和值允许的字符
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// FakeLambda 是 LambdaAPI 的内存实现
+// 模拟函数、版本、别名和路由配置，用于在没有 AWS 的情况下测试完整的发布流程
+type FakeLambda struct {
+	mu            sync.Mutex
+	functions     map[string]*fakeFunction
+	revision      int
+	now           func() time.Time
+	updateDelay   int    // UpdateFunctionCode 之后保持 InProgress 的查询次数
+	updateFailure string // 非空时 UpdateFunctionCode 以 Failed 结束，值为失败原因
+}
+
+// fakeFunction 内存中的函数
+type fakeFunction struct {
+	Name        string `json:"name"`
+	CodeSha256  string `json:"code_sha256"` // $LATEST 的代码哈希
+	CodeSize    int64  `json:"code_size"`
+	CodeSerial  int    `json:"code_serial"`  // 代码变更计数，用于生成新的代码哈希
+	NextVersion int    `json:"next_version"` // 下一个发布的版本号，版本号不会复用
+	// UpdatePending 是 $LATEST 保持 InProgress 的剩余查询次数
+	UpdatePending int `json:"update_pending,omitempty"`
+	// UpdateFailure 是 $LATEST 最近一次更新失败的原因，为空表示更新成功
+	UpdateFailure string                `json:"update_failure,omitempty"`
+	Versions      []*fakeVersion        `json:"versions"`
+	Aliases       map[string]*fakeAlias `json:"aliases"`
+	Tags          map[string]string     `json:"tags,omitempty"`
+}
+
+// fakeVersion 内存中的已发布版本
+type fakeVersion struct {
+	Version     string    `json:"version"`
+	Description string    `json:"description"`
+	CodeSha256  string    `json:"code_sha256"`
+	CodeSize    int64     `json:"code_size"`
+	Created     time.Time `json:"created"`
+	// Pending 是版本保持 Pending 状态的剩余查询次数（例如 SnapStart、VPC 函数的新版本）
+	Pending int `json:"pending,omitempty"`
+	// Failure 是版本进入 Failed 状态的原因，为空表示版本正常
+	Failure string `json:"failure,omitempty"`
+}
+
+// fakeAlias 内存中的别名
+type fakeAlias struct {
+	Name        string             `json:"name"`
+	Version     string             `json:"version"`
+	Description string             `json:"description"`
+	RevisionID  string             `json:"revision_id"`
+	Weights     map[string]float64 `json:"weights"`
+}
+
+// NewFakeLambda 创建空的内存 Lambda 实现
+func NewFakeLambda() *FakeLambda {
+	return &FakeLambda{
+		functions: make(map[string]*fakeFunction),
+		now:       time.Now,
+	}
+}
+
+// SetClock 设置发布版本时使用的时钟（用于测试）
+func (f *FakeLambda) SetClock(now func() time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = now
+}
+
+// SetUpdateDelay 设置 UpdateFunctionCode 之后 $LATEST 保持更新中的查询次数（用于测试）
+// 在此期间 GetFunctionConfiguration 返回 LastUpdateStatus InProgress（每次查询减一），
+// UpdateFunctionCode 和 PublishVersion 返回 ResourceConflictException
+func (f *FakeLambda) SetUpdateDelay(polls int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.updateDelay = polls
+}
+
+// SetUpdateFailure 设置之后的 UpdateFunctionCode 以 Failed 结束（用于测试）
+// reason 为空时恢复为更新成功
+func (f *FakeLambda) SetUpdateFailure(reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.updateFailure = reason
+}
+
+// SetVersionPending 设置版本在之后 polls 次查询中保持 Pending 状态（用于测试）
+func (f *FakeLambda) SetVersionPending(name, version string, polls int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.getPublishedVersion(name, version)
+	if err != nil {
+		return err
+	}
+	v.Pending = polls
+	return nil
+}
+
+// SetVersionFailed 设置版本的状态为 Failed（用于测试）
+// reason 为空时恢复为 Active
+func (f *FakeLambda) SetVersionFailed(name, version, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.getPublishedVersion(name, version)
+	if err != nil {
+		return err
+	}
+	v.Failure = reason
+	return nil
+}
+
+// CreateFunction 创建只有 $LATEST 的函数
+// 如果函数已存在则不做任何修改
+func (f *FakeLambda) CreateFunction(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.functions[name]; ok {
+		return
+	}
+	fn := &fakeFunction{
+		Name:        name,
+		NextVersion: 1,
+		Aliases:     make(map[string]*fakeAlias),
+	}
+	fn.changeCode()
+	f.functions[name] = fn
+}
+
+// HasFunction 判断函数是否存在
+func (f *FakeLambda) HasFunction(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.functions[name]
+	return ok
+}
+
+// ChangeCode 修改函数 $LATEST 的代码，模拟 update-function-code
+func (f *FakeLambda) ChangeCode(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getFunction(name)
+	if err != nil {
+		return err
+	}
+	fn.changeCode()
+	return nil
+}
+
+// Deploy 模拟部署新版本后 lad 流程期望的状态
+// 修改代码并发布新版本；首次部署时创建 live、previous、latest 三个别名，
+// 之后只移动 latest 别名（live 和 previous 保持不变）
+// 返回: 新版本号, 错误
+func (f *FakeLambda) Deploy(name string) (string, error) {
+	f.CreateFunction(name)
+	if err := f.ChangeCode(name); err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn := f.functions[name]
+	version := fn.publish("", f.now())
+
+	if len(fn.Aliases) == 0 {
+		for _, aliasName := range []string{"live", "previous", "latest"} {
+			fn.Aliases[aliasName] = &fakeAlias{
+				Name:       aliasName,
+				Version:    version.Version,
+				RevisionID: f.nextRevision(),
+				Weights:    map[string]float64{},
+			}
+		}
+		return version.Version, nil
+	}
+
+	latest, ok := fn.Aliases["latest"]
+	if !ok {
+		latest = &fakeAlias{Name: "latest", Weights: map[string]float64{}}
+		fn.Aliases["latest"] = latest
+	}
+	latest.Version = version.Version
+	latest.RevisionID = f.nextRevision()
+	return version.Version, nil
+}
+
+// DeployStack 模拟在 lad patch 生成的模板上执行 sam deploy
+// 三个别名资源都引用 Version 资源，CloudFormation 发布新版本后把 live、previous、latest 都移动到新版本，
+// 并清除 live 的路由配置；lad deploy 需要把 live 和 previous 恢复到部署前的版本
+// 返回: 新版本号, 错误
+func (f *FakeLambda) DeployStack(name string) (string, error) {
+	version, err := f.Deploy(name)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, aliasName := range []string{"live", "previous"} {
+		if alias, ok := f.functions[name].Aliases[aliasName]; ok && alias.Version != version {
+			alias.Version = version
+			alias.Weights = map[string]float64{}
+			alias.RevisionID = f.nextRevision()
+		}
+	}
+	return version, nil
+}
+
+// GetAlias 实现 LambdaAPI
+func (f *FakeLambda) GetAlias(ctx context.Context, params *lambda.GetAliasInput, optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getFunction(aws.ToString(params.FunctionName))
+	if err != nil {
+		return nil, err
+	}
+	alias, err := fn.getAlias(aws.ToString(params.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	return &lambda.GetAliasOutput{
+		AliasArn:        aws.String(fn.arn() + ":" + alias.Name),
+		Description:     aws.String(alias.Description),
+		FunctionVersion: aws.String(alias.Version),
+		Name:            aws.String(alias.Name),
+		RevisionId:      aws.String(alias.RevisionID),
+		RoutingConfig:   alias.routingConfig(),
+	}, nil
+}
+
+// UpdateAlias 实现 LambdaAPI
+// 未指定的 FunctionVersion、Description、RoutingConfig 保持不变
+// 指定 RevisionId 时，与别名当前的 RevisionId 不一致则返回 PreconditionFailedException
+func (f *FakeLambda) UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getFunction(aws.ToString(params.FunctionName))
+	if err != nil {
+		return nil, err
+	}
+	alias, err := fn.getAlias(aws.ToString(params.Name))
+	if err != nil {
+		return nil, err
+	}
+	if params.RevisionId != nil && aws.ToString(params.RevisionId) != alias.RevisionID {
+		return nil, &types.PreconditionFailedException{
+			Message: aws.String("The Revision Id provided does not match the latest Revision Id. Call the GetAlias API to retrieve the latest Revision Id"),
+			Type:    aws.String("User"),
+		}
+	}
+
+	if err := validateDescription(params.Description); err != nil {
+		return nil, err
+	}
+
+	version := alias.Version
+	if params.FunctionVersion != nil {
+		version = aws.ToString(params.FunctionVersion)
+		if _, err := fn.getVersion(version); err != nil {
+			return nil, err
+		}
+	}
+
+	weights := alias.Weights
+	if params.RoutingConfig != nil {
+		weights, err = fn.validateWeights(version, params.RoutingConfig.AdditionalVersionWeights)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	alias.Version = version
+	alias.Weights = weights
+	if params.Description != nil {
+		alias.Description = aws.ToString(params.Description)
+	}
+	alias.RevisionID = f.nextRevision()
+
+	return &lambda.UpdateAliasOutput{
+		AliasArn:        aws.String(fn.arn() + ":" + alias.Name),
+		Description:     aws.String(alias.Description),
+		FunctionVersion: aws.String(alias.Version),
+		Name:            aws.String(alias.Name),
+		RevisionId:      aws.String(alias.RevisionID),
+		RoutingConfig:   alias.routingConfig(),
+	}, nil
+}
+
+// PublishVersion 实现 LambdaAPI
+// 与 Lambda 行为一致：如果 $LATEST 自上次发布后没有变化，返回上次发布的版本
+func (f *FakeLambda) PublishVersion(ctx context.Context, params *lambda.PublishVersionInput, optFns ...func(*lambda.Options)) (*lambda.PublishVersionOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getFunction(aws.ToString(params.FunctionName))
+	if err != nil {
+		return nil, err
+	}
+
+	if fn.UpdatePending > 0 {
+		return nil, fn.updateInProgress()
+	}
+
+	version := fn.lastVersion()
+	if version == nil || version.CodeSha256 != fn.CodeSha256 {
+		version = fn.publish(aws.ToString(params.Description), f.now())
+	}
+
+	config := fn.configuration(version)
+	return &lambda.PublishVersionOutput{
+		FunctionName:     config.FunctionName,
+		FunctionArn:      config.FunctionArn,
+		Version:          config.Version,
+		Description:      config.Description,
+		CodeSha256:       config.CodeSha256,
+		CodeSize:         config.CodeSize,
+		LastModified:     config.LastModified,
+		Runtime:          config.Runtime,
+		Architectures:    config.Architectures,
+		PackageType:      config.PackageType,
+		State:            config.State,
+		LastUpdateStatus: config.LastUpdateStatus,
+	}, nil
+}
+
+// GetFunction 实现 LambdaAPI
+// Qualifier 可以是版本号、别名或 $LATEST
+func (f *FakeLambda) GetFunction(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getFunction(aws.ToString(params.FunctionName))
+	if err != nil {
+		return nil, err
+	}
+
+	qualifier := aws.ToString(params.Qualifier)
+	if alias, ok := fn.Aliases[qualifier]; ok {
+		qualifier = alias.Version
+	}
+	version, err := fn.getVersion(qualifier)
+	if err != nil {
+		return nil, err
+	}
+
+	config := fn.configuration(version)
+	tags := make(map[string]string, len(fn.Tags))
+	for k, v := range fn.Tags {
+		tags[k] = v
+	}
+	return &lambda.GetFunctionOutput{
+		Configuration: &config,
+		Code: &types.FunctionCodeLocation{
+			RepositoryType: aws.String("S3"),
+		},
+		Tags: tags,
+	}, nil
+}
+
+// GetFunctionConfiguration 实现 LambdaAPI
+// Qualifier 可以是版本号、别名或 $LATEST；$LATEST 更新中或版本 Pending 时每次查询减少一次剩余的查询次数
+func (f *FakeLambda) GetFunctionConfiguration(ctx context.Context, params *lambda.GetFunctionConfigurationInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionConfigurationOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getFunction(aws.ToString(params.FunctionName))
+	if err != nil {
+		return nil, err
+	}
+
+	qualifier := aws.ToString(params.Qualifier)
+	if alias, ok := fn.Aliases[qualifier]; ok {
+		qualifier = alias.Version
+	}
+	version, err := fn.getVersion(qualifier)
+	if err != nil {
+		return nil, err
+	}
+
+	config := fn.configuration(version)
+	if version == nil && fn.UpdatePending > 0 {
+		fn.UpdatePending--
+	}
+	if version != nil && version.Pending > 0 {
+		version.Pending--
+	}
+	return &lambda.GetFunctionConfigurationOutput{
+		FunctionName:           config.FunctionName,
+		FunctionArn:            config.FunctionArn,
+		Version:                config.Version,
+		Description:            config.Description,
+		CodeSha256:             config.CodeSha256,
+		CodeSize:               config.CodeSize,
+		LastModified:           config.LastModified,
+		Runtime:                config.Runtime,
+		Architectures:          config.Architectures,
+		PackageType:            config.PackageType,
+		State:                  config.State,
+		StateReason:            config.StateReason,
+		LastUpdateStatus:       config.LastUpdateStatus,
+		LastUpdateStatusReason: config.LastUpdateStatusReason,
+	}, nil
+}
+
+// UpdateFunctionCode 实现 LambdaAPI
+// 代码哈希由 ZipFile、ImageUri 或 S3 位置计算，不支持 Publish
+func (f *FakeLambda) UpdateFunctionCode(ctx context.Context, params *lambda.UpdateFunctionCodeInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionCodeOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getFunction(aws.ToString(params.FunctionName))
+	if err != nil {
+		return nil, err
+	}
+	if fn.UpdatePending > 0 {
+		return nil, fn.updateInProgress()
+	}
+
+	var code []byte
+	switch {
+	case len(params.ZipFile) > 0:
+		code = params.ZipFile
+	case params.ImageUri != nil:
+		code = []byte(aws.ToString(params.ImageUri))
+	case params.S3Bucket != nil && params.S3Key != nil:
+		code = []byte(aws.ToString(params.S3Bucket) + "/" + aws.ToString(params.S3Key) + "@" + aws.ToString(params.S3ObjectVersion))
+	default:
+		return nil, invalidParameter("Please provide a source for function code.")
+	}
+
+	sum := sha256.Sum256(code)
+	fn.CodeSha256 = base64.StdEncoding.EncodeToString(sum[:])
+	fn.CodeSize = int64(len(code))
+	fn.UpdatePending = f.updateDelay
+	fn.UpdateFailure = f.updateFailure
+
+	config := fn.configuration(nil)
+	return &lambda.UpdateFunctionCodeOutput{
+		FunctionName:           config.FunctionName,
+		FunctionArn:            config.FunctionArn,
+		Version:                config.Version,
+		CodeSha256:             config.CodeSha256,
+		CodeSize:               config.CodeSize,
+		Runtime:                config.Runtime,
+		Architectures:          config.Architectures,
+		PackageType:            config.PackageType,
+		State:                  config.State,
+		LastUpdateStatus:       config.LastUpdateStatus,
+		LastUpdateStatusReason: config.LastUpdateStatusReason,
+	}, nil
+}
+
+// CreateAlias 实现 LambdaAPI
+func (f *FakeLambda) CreateAlias(ctx context.Context, params *lambda.CreateAliasInput, optFns ...func(*lambda.Options)) (*lambda.CreateAliasOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getFunction(aws.ToString(params.FunctionName))
+	if err != nil {
+		return nil, err
+	}
+
+	name := aws.ToString(params.Name)
+	if _, ok := fn.Aliases[name]; ok {
+		return nil, &types.ResourceConflictException{
+			Type:    aws.String("User"),
+			Message: aws.String("Alias already exists: " + fn.arn() + ":" + name),
+		}
+	}
+
+	if err := validateDescription(params.Description); err != nil {
+		return nil, err
+	}
+
+	version := aws.ToString(params.FunctionVersion)
+	if _, err := fn.getVersion(version); err != nil {
+		return nil, err
+	}
+
+	weights := map[string]float64{}
+	if params.RoutingConfig != nil {
+		weights, err = fn.validateWeights(version, params.RoutingConfig.AdditionalVersionWeights)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	alias := &fakeAlias{
+		Name:        name,
+		Version:     version,
+		Description: aws.ToString(params.Description),
+		RevisionID:  f.nextRevision(),
+		Weights:     weights,
+	}
+	fn.Aliases[name] = alias
+
+	return &lambda.CreateAliasOutput{
+		AliasArn:        aws.String(fn.arn() + ":" + alias.Name),
+		Description:     aws.String(alias.Description),
+		FunctionVersion: aws.String(alias.Version),
+		Name:            aws.String(alias.Name),
+		RevisionId:      aws.String(alias.RevisionID),
+		RoutingConfig:   alias.routingConfig(),
+	}, nil
+}
+
+// ListVersionsByFunction 实现 LambdaAPI
+// 与 Lambda 一致，结果第一项为 $LATEST，之后按版本号升序排列
+func (f *FakeLambda) ListVersionsByFunction(ctx context.Context, params *lambda.ListVersionsByFunctionInput, optFns ...func(*lambda.Options)) (*lambda.ListVersionsByFunctionOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getFunction(aws.ToString(params.FunctionName))
+	if err != nil {
+		return nil, err
+	}
+
+	configs := []types.FunctionConfiguration{fn.configuration(nil)}
+	for _, version := range fn.Versions {
+		configs = append(configs, fn.configuration(version))
+	}
+
+	start, end, next, err := paginate(len(configs), params.Marker, params.MaxItems)
+	if err != nil {
+		return nil, err
+	}
+	return &lambda.ListVersionsByFunctionOutput{
+		Versions:   configs[start:end],
+		NextMarker: next,
+	}, nil
+}
+
+// ListAliases 实现 LambdaAPI
+// 指定 FunctionVersion 时只返回指向该版本的别名
+func (f *FakeLambda) ListAliases(ctx context.Context, params *lambda.ListAliasesInput, optFns ...func(*lambda.Options)) (*lambda.ListAliasesOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getFunction(aws.ToString(params.FunctionName))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(fn.Aliases))
+	for name := range fn.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var aliases []types.AliasConfiguration
+	for _, name := range names {
+		alias := fn.Aliases[name]
+		if params.FunctionVersion != nil && alias.Version != aws.ToString(params.FunctionVersion) {
+			continue
+		}
+		aliases = append(aliases, types.AliasConfiguration{
+			AliasArn:        aws.String(fn.arn() + ":" + alias.Name),
+			Description:     aws.String(alias.Description),
+			FunctionVersion: aws.String(alias.Version),
+			Name:            aws.String(alias.Name),
+			RevisionId:      aws.String(alias.RevisionID),
+			RoutingConfig:   alias.routingConfig(),
+		})
+	}
+
+	start, end, next, err := paginate(len(aliases), params.Marker, params.MaxItems)
+	if err != nil {
+		return nil, err
+	}
+	return &lambda.ListAliasesOutput{
+		Aliases:    aliases[start:end],
+		NextMarker: next,
+	}, nil
+}
+
+// DeleteFunction 实现 LambdaAPI
+// 指定 Qualifier 时只删除该版本；与 Lambda 一致，被别名（包括路由配置）引用的版本不能删除
+func (f *FakeLambda) DeleteFunction(ctx context.Context, params *lambda.DeleteFunctionInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.ToString(params.FunctionName)
+	fn, err := f.getFunction(name)
+	if err != nil {
+		return nil, err
+	}
+
+	qualifier := aws.ToString(params.Qualifier)
+	if qualifier == "" {
+		delete(f.functions, name)
+		return &lambda.DeleteFunctionOutput{StatusCode: 204}, nil
+	}
+	if qualifier == latestQualifier {
+		return nil, invalidParameter("$LATEST version cannot be deleted without deleting the function.")
+	}
+	if _, err := fn.getVersion(qualifier); err != nil {
+		return nil, err
+	}
+
+	var referencedBy []string
+	for _, alias := range fn.Aliases {
+		if _, routed := alias.Weights[qualifier]; alias.Version == qualifier || routed {
+			referencedBy = append(referencedBy, alias.Name)
+		}
+	}
+	if len(referencedBy) > 0 {
+		sort.Strings(referencedBy)
+		return nil, &types.ResourceConflictException{
+			Type:    aws.String("User"),
+			Message: aws.String(fmt.Sprintf("Unable to delete version because the following aliases reference it: %v", referencedBy)),
+		}
+	}
+
+	versions := fn.Versions[:0]
+	for _, version := range fn.Versions {
+		if version.Version != qualifier {
+			versions = append(versions, version)
+		}
+	}
+	fn.Versions = versions
+	return &lambda.DeleteFunctionOutput{StatusCode: 204}, nil
+}
+
+// GetAccountSettings 实现 LambdaAPI
+// 代码存储用量为所有函数 $LATEST 和已发布版本的代码大小之和，限额为 Lambda 的默认值
+func (f *FakeLambda) GetAccountSettings(ctx context.Context, params *lambda.GetAccountSettingsInput, optFns ...func(*lambda.Options)) (*lambda.GetAccountSettingsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var totalCodeSize int64
+	for _, fn := range f.functions {
+		totalCodeSize += fn.CodeSize
+		for _, version := range fn.Versions {
+			totalCodeSize += version.CodeSize
+		}
+	}
+
+	return &lambda.GetAccountSettingsOutput{
+		AccountLimit: &types.AccountLimit{
+			TotalCodeSize:                  fakeCodeStorageLimit,
+			CodeSizeUnzipped:               262144000,
+			CodeSizeZipped:                 52428800,
+			ConcurrentExecutions:           1000,
+			UnreservedConcurrentExecutions: aws.Int32(1000),
+		},
+		AccountUsage: &types.AccountUsage{
+			FunctionCount: int64(len(f.functions)),
+			TotalCodeSize: totalCodeSize,
+		},
+	}, nil
+}
+
+// TagResource 实现 LambdaAPI
+// 与 Lambda 一致：只能为函数（不带版本限定符的 ARN）设置标签，每个函数最多 50 个标签
+func (f *FakeLambda) TagResource(ctx context.Context, params *lambda.TagResourceInput, optFns ...func(*lambda.Options)) (*lambda.TagResourceOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getTaggedFunction(aws.ToString(params.Resource))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(fn.Tags)+len(params.Tags))
+	for k, v := range fn.Tags {
+		tags[k] = v
+	}
+	for k, v := range params.Tags {
+		if len(k) == 0 || len(k) > 128 || len(v) > 256 || strings.HasPrefix(k, "aws:") || !tagPattern.MatchString(k) || !tagPattern.MatchString(v) {
+			return nil, invalidParameter(fmt.Sprintf("Invalid tag: %q=%q", k, v))
+		}
+		tags[k] = v
+	}
+	if len(tags) > 50 {
+		return nil, invalidParameter("Number of tags exceeds resource tag limit of 50")
+	}
+	fn.Tags = tags
+	return &lambda.TagResourceOutput{}, nil
+}
+
+// UntagResource 实现 LambdaAPI
+// 不存在的标签会被忽略
+func (f *FakeLambda) UntagResource(ctx context.Context, params *lambda.UntagResourceInput, optFns ...func(*lambda.Options)) (*lambda.UntagResourceOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn, err := f.getTaggedFunction(aws.ToString(params.Resource))
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range params.TagKeys {
+		delete(fn.Tags, key)
+	}
+	return &lambda.UntagResourceOutput{}, nil
+}
+
+// fakeSnapshot 是 FakeLambda 持久化时的 JSON 结构
+type fakeSnapshot struct {
+	Revision  int                      `json:"revision"`
+	Functions map[string]*fakeFunction `json:"functions"`
+}
+
+// MarshalJSON 将全部函数、版本和别名序列化为 JSON
+func (f *FakeLambda) MarshalJSON() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return json.Marshal(fakeSnapshot{Revision: f.revision, Functions: f.functions})
+}
+
+// UnmarshalJSON 从 MarshalJSON 生成的 JSON 恢复状态
+func (f *FakeLambda) UnmarshalJSON(data []byte) error {
+	var snapshot fakeSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+	if snapshot.Functions == nil {
+		snapshot.Functions = make(map[string]*fakeFunction)
+	}
+	for _, fn := range snapshot.Functions {
+		if fn.Aliases == nil {
+			fn.Aliases = make(map[string]*fakeAlias)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.revision = snapshot.Revision
+	f.functions = snapshot.Functions
+	if f.now == nil {
+		f.now = time.Now
+	}
+	return nil
+}
+
+// getFunction 获取函数，调用方需持有锁
+func (f *FakeLambda) getFunction(name string) (*fakeFunction, error) {
+	fn, ok := f.functions[name]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{
+			Type:    aws.String("User"),
+			Message: aws.String("Function not found: " + functionArn(name)),
+		}
+	}
+	return fn, nil
+}
+
+// getTaggedFunction 根据标签操作的资源 ARN 获取函数，调用方需持有锁
+func (f *FakeLambda) getTaggedFunction(resource string) (*fakeFunction, error) {
+	parts := strings.Split(resource, ":")
+	if len(parts) != 7 || parts[5] != "function" {
+		return nil, invalidParameter("Tags are only supported on functions: " + resource)
+	}
+	return f.getFunction(parts[6])
+}
+
+// getPublishedVersion 获取已发布的版本，调用方需持有锁
+func (f *FakeLambda) getPublishedVersion(name, version string) (*fakeVersion, error) {
+	fn, err := f.getFunction(name)
+	if err != nil {
+		return nil, err
+	}
+	v, err := fn.getVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, invalidParameter("$LATEST is not a published version")
+	}
+	return v, nil
+}
+
+// nextRevision 生成新的 RevisionId，调用方需持有锁
+func (f *FakeLambda) nextRevision() string {
+	f.revision++
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", f.revision)
+}
+
+// functionArn 生成函数 ARN
+func functionArn(name string) string {
+	return fmt.Sprintf("arn:aws:lambda:%s:%s:function:%s", fakeRegion, fakeAccountID, name)
+}
+
+func (fn *fakeFunction) arn() string {
+	return functionArn(fn.Name)
+}
+
+// updateInProgress 生成 $LATEST 更新中时的冲突错误
+func (fn *fakeFunction) updateInProgress() error {
+	return &types.ResourceConflictException{
+		Type:    aws.String("User"),
+		Message: aws.String("The operation cannot be performed at this time. An update is in progress for resource: " + fn.arn()),
+	}
+}
+
+// changeCode 生成新的 $LATEST 代码哈希
+func (fn *fakeFunction) changeCode() {
+	fn.CodeSerial++
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s#%d", fn.Name, fn.CodeSerial)))
+	fn.CodeSha256 = base64.StdEncoding.EncodeToString(sum[:])
+	fn.CodeSize = int64(1024 * (fn.CodeSerial + 1))
+	fn.UpdateFailure = ""
+}
+
+// publish 将 $LATEST 发布为新版本
+func (fn *fakeFunction) publish(description string, now time.Time) *fakeVersion {
+	version := &fakeVersion{
+		Version:     strconv.Itoa(fn.NextVersion),
+		Description: description,
+		CodeSha256:  fn.CodeSha256,
+		CodeSize:    fn.CodeSize,
+		Created:     now,
+	}
+	fn.NextVersion++
+	fn.Versions = append(fn.Versions, version)
+	return version
+}
+
+// lastVersion 返回最近发布的版本，没有已发布版本时返回 nil
+func (fn *fakeFunction) lastVersion() *fakeVersion {
+	if len(fn.Versions) == 0 {
+		return nil
+	}
+	return fn.Versions[len(fn.Versions)-1]
+}
+
+// getVersion 获取已发布版本，$LATEST 返回 nil 表示未发布版本
+func (fn *fakeFunction) getVersion(qualifier string) (*fakeVersion, error) {
+	if qualifier == "" || qualifier == latestQualifier {
+		return nil, nil
+	}
+	for _, version := range fn.Versions {
+		if version.Version == qualifier {
+			return version, nil
+		}
+	}
+	return nil, &types.ResourceNotFoundException{
+		Type:    aws.String("User"),
+		Message: aws.String("Function not found: " + fn.arn() + ":" + qualifier),
+	}
+}
+
+// getAlias 获取别名
+func (fn *fakeFunction) getAlias(name string) (*fakeAlias, error) {
+	alias, ok := fn.Aliases[name]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{
+			Type:    aws.String("User"),
+			Message: aws.String("Alias not found: " + fn.arn() + ":" + name),
+		}
+	}
+	return alias, nil
+}
+
+// validateWeights 校验路由配置
+// 与 Lambda 一致：最多一个附加版本，权重在 0-1 之间，且不能与主版本相同
+func (fn *fakeFunction) validateWeights(primary string, weights map[string]float64) (map[string]float64, error) {
+	if len(weights) > 1 {
+		return nil, invalidParameter("Number of items in AdditionalVersionWeights cannot be greater than 1")
+	}
+
+	result := make(map[string]float64, len(weights))
+	for version, weight := range weights {
+		if weight < 0 || weight > 1 {
+			return nil, invalidParameter(fmt.Sprintf("Invalid weight %v for version %s", weight, version))
+		}
+		if version == primary {
+			return nil, invalidParameter("Primary and additional version cannot be the same")
+		}
+		if version == latestQualifier {
+			return nil, invalidParameter("$LATEST is not supported for an alias pointing to more than 1 version")
+		}
+		if _, err := fn.getVersion(version); err != nil {
+			return nil, err
+		}
+		result[version] = weight
+	}
+	return result, nil
+}
+
+// configuration 生成版本配置，version 为 nil 时表示 $LATEST
+func (fn *fakeFunction) configuration(version *fakeVersion) types.FunctionConfiguration {
+	config := types.FunctionConfiguration{
+		FunctionName:     aws.String(fn.Name),
+		FunctionArn:      aws.String(fn.arn() + ":" + latestQualifier),
+		Version:          aws.String(latestQualifier),
+		CodeSha256:       aws.String(fn.CodeSha256),
+		CodeSize:         fn.CodeSize,
+		Runtime:          types.Runtime("provided.al2023"),
+		Architectures:    []types.Architecture{types.Architecture("arm64")},
+		PackageType:      types.PackageTypeZip,
+		State:            types.StateActive,
+		LastUpdateStatus: types.LastUpdateStatusSuccessful,
+	}
+	if version == nil {
+		// $LATEST 反映最近一次代码更新的状态
+		if fn.UpdatePending > 0 {
+			config.LastUpdateStatus = types.LastUpdateStatusInProgress
+		} else if fn.UpdateFailure != "" {
+			config.LastUpdateStatus = types.LastUpdateStatusFailed
+			config.LastUpdateStatusReason = aws.String(fn.UpdateFailure)
+		}
+	}
+	if version != nil {
+		config.FunctionArn = aws.String(fn.arn() + ":" + version.Version)
+		config.Version = aws.String(version.Version)
+		config.Description = aws.String(version.Description)
+		config.CodeSha256 = aws.String(version.CodeSha256)
+		config.CodeSize = version.CodeSize
+		config.LastModified = aws.String(version.Created.UTC().Format("2006-01-02T15:04:05.000-0700"))
+		// 版本创建中为 Pending，创建失败为 Failed
+		if version.Pending > 0 {
+			config.State = types.StatePending
+			config.StateReason = aws.String("The function is being created.")
+			config.StateReasonCode = types.StateReasonCodeCreating
+		} else if version.Failure != "" {
+			config.State = types.StateFailed
+			config.StateReason = aws.String(version.Failure)
+		}
+	}
+	return config
+}
+
+// routingConfig 生成别名的路由配置
+func (a *fakeAlias) routingConfig() *types.AliasRoutingConfiguration {
+	weights := make(map[string]float64, len(a.Weights))
+	for version, weight := range a.Weights {
+		weights[version] = weight
+	}
+	return &types.AliasRoutingConfiguration{AdditionalVersionWeights: weights}
+}
+
+// paginate 计算分页范围，Marker 为上一页结束位置
+// 返回: 起始位置, 结束位置, 下一页 Marker, 错误
+func paginate(total int, marker *string, maxItems *int32) (int, int, *string, error) {
+	start := 0
+	if marker != nil {
+		n, err := strconv.Atoi(aws.ToString(marker))
+		if err != nil || n < 0 || n > total {
+			return 0, 0, nil, invalidParameter("Invalid Marker: " + aws.ToString(marker))
+		}
+		start = n
+	}
+
+	limit := 50
+	if maxItems != nil && *maxItems > 0 {
+		limit = int(*maxItems)
+	}
+
+	end := start + limit
+	if end >= total {
+		return start, total, nil, nil
+	}
+	return start, end, aws.String(strconv.Itoa(end)), nil
+}
+
+// invalidParameter 生成参数错误
+// validateDescription 与 Lambda 一样限制别名描述最多 256 个字符
+func validateDescription(description *string) error {
+	if n := utf8.RuneCountInString(aws.ToString(description)); n > 256 {
+		return invalidParameter(fmt.Sprintf("1 validation error detected: Value at 'description' failed to satisfy constraint: Member must have length less than or equal to 256 (got %d)", n))
+	}
+	return nil
+}
+
+func invalidParameter(message string) error {
+	return &types.InvalidParameterValueException{
+		Type:    aws.String("User"),
+		Message: aws.String(message),
+	}
+}
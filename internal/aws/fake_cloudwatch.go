@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeCloudWatch 是 MetricsAPI 的内存实现
+// 告警状态和指标值由测试设置，用于在没有 AWS 的情况下测试灰度健康检查
+type FakeCloudWatch struct {
+	mu      sync.Mutex
+	alarms  map[string]AlarmState
+	metrics map[string][]float64
+	queries []MetricQuery
+	err     error
+}
+
+// NewFakeCloudWatch 创建没有告警和指标数据的 FakeCloudWatch
+func NewFakeCloudWatch() *FakeCloudWatch {
+	return &FakeCloudWatch{
+		alarms:  make(map[string]AlarmState),
+		metrics: make(map[string][]float64),
+	}
+}
+
+// SetAlarm 创建或修改告警的状态
+func (f *FakeCloudWatch) SetAlarm(name, state, reason string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.alarms[name] = AlarmState{Name: name, State: state, Reason: reason}
+}
+
+// SetMetric 设置指标的统计值（从新到旧），对该指标的所有维度和统计方式生效
+// values 为空表示没有数据
+func (f *FakeCloudWatch) SetMetric(metricName string, values ...float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.metrics[metricName] = values
+}
+
+// SetError 设置之后所有请求返回的错误，传入 nil 恢复正常
+func (f *FakeCloudWatch) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// Queries 返回已执行的指标查询
+func (f *FakeCloudWatch) Queries() []MetricQuery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]MetricQuery(nil), f.queries...)
+}
+
+// DescribeAlarms 实现 MetricsAPI
+func (f *FakeCloudWatch) DescribeAlarms(ctx context.Context, names []string) ([]AlarmState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, WrapError("DescribeAlarms", f.err)
+	}
+
+	var states []AlarmState
+	for _, name := range names {
+		if state, ok := f.alarms[name]; ok {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+// GetMetricValues 实现 MetricsAPI
+func (f *FakeCloudWatch) GetMetricValues(ctx context.Context, query MetricQuery) ([]float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, WrapError("GetMetricData", f.err)
+	}
+
+	f.queries = append(f.queries, query)
+	return append([]float64(nil), f.metrics[query.MetricName]...), nil
+}
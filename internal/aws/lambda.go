@@ -3,7 +3,9 @@ package aws
 
 import (
 	"context"
-	"strings"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/aura-studio/lad/internal/exitcode"
 	"github.com/aura-studio/lad/internal/output"
@@ -13,84 +15,150 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
 )
 
+// LambdaAPI 是 Client 依赖的 Lambda API 子集
+// *lambda.Client 和 FakeLambda 都实现了该接口
+type LambdaAPI interface {
+	GetAlias(ctx context.Context, params *lambda.GetAliasInput, optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error)
+	UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error)
+	PublishVersion(ctx context.Context, params *lambda.PublishVersionInput, optFns ...func(*lambda.Options)) (*lambda.PublishVersionOutput, error)
+	GetFunction(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error)
+	CreateAlias(ctx context.Context, params *lambda.CreateAliasInput, optFns ...func(*lambda.Options)) (*lambda.CreateAliasOutput, error)
+	ListVersionsByFunction(ctx context.Context, params *lambda.ListVersionsByFunctionInput, optFns ...func(*lambda.Options)) (*lambda.ListVersionsByFunctionOutput, error)
+	ListAliases(ctx context.Context, params *lambda.ListAliasesInput, optFns ...func(*lambda.Options)) (*lambda.ListAliasesOutput, error)
+	DeleteFunction(ctx context.Context, params *lambda.DeleteFunctionInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error)
+	GetAccountSettings(ctx context.Context, params *lambda.GetAccountSettingsInput, optFns ...func(*lambda.Options)) (*lambda.GetAccountSettingsOutput, error)
+	GetFunctionConfiguration(ctx context.Context, params *lambda.GetFunctionConfigurationInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionConfigurationOutput, error)
+	UpdateFunctionCode(ctx context.Context, params *lambda.UpdateFunctionCodeInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionCodeOutput, error)
+	TagResource(ctx context.Context, params *lambda.TagResourceInput, optFns ...func(*lambda.Options)) (*lambda.TagResourceOutput, error)
+	UntagResource(ctx context.Context, params *lambda.UntagResourceInput, optFns ...func(*lambda.Options)) (*lambda.UntagResourceOutput, error)
+}
+
 // Client 封装 Lambda API 操作
 type Client struct {
-	client *lambda.Client
+	client       LambdaAPI
+	retry        RetryPolicy
+	region       string        // 解析后的 AWS Region
+	endpoint     string        // 解析后的 Lambda API 地址
+	pollInterval time.Duration // 等待函数更新时的查询间隔，为 0 时使用 defaultPollInterval
+}
+
+// ClientOptions 创建 Lambda 客户端的选项
+type ClientOptions struct {
+	Profile     string      // AWS Profile，为空时使用默认配置
+	Region      string      // AWS Region，为空时使用 Profile 或环境变量中的配置
+	EndpointURL string      // Lambda API 地址，为空时使用 AWS 默认地址（例如指向 lad emulator）
+	Retry       RetryPolicy // 重试策略，未设置的字段使用默认值
 }
 
 // NewClient 创建新的 Lambda 客户端
 // 如果 profile 为空，则使用默认的 AWS 配置
-func NewClient(ctx context.Context, profile string) (*Client, error) {
-	var cfg config.LoadOptions
-
-	// 如果指定了 profile，则使用该 profile
-	opts := []func(*config.LoadOptions) error{}
-	if profile != "" {
-		opts = append(opts, config.WithSharedConfigProfile(profile))
+func NewClient(ctx context.Context, clientOpts ClientOptions) (*Client, error) {
+	awsCfg, err := loadConfig(ctx, clientOpts)
+	if err != nil {
+		return nil, err
 	}
 
-	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	endpoint, err := resolveEndpoint(ctx, awsCfg.Region, clientOpts.EndpointURL)
 	if err != nil {
-		return nil, err
+		return nil, &Error{Op: "ResolveEndpoint", Code: exitcode.ParamError, Err: err}
 	}
-	_ = cfg // 避免未使用变量警告
 
-	lambdaClient := lambda.NewFromConfig(awsCfg)
-	return &Client{client: lambdaClient}, nil
+	lambdaClient := lambda.NewFromConfig(awsCfg, func(o *lambda.Options) {
+		// 如果指定了 endpoint，则覆盖默认的 Lambda API 地址
+		if clientOpts.EndpointURL != "" {
+			o.BaseEndpoint = aws.String(clientOpts.EndpointURL)
+		}
+		// 关闭 SDK 自带的重试，由 RetryPolicy 统一控制重试次数和时间
+		o.RetryMaxAttempts = 1
+	})
+	return &Client{client: lambdaClient, retry: clientOpts.Retry, region: awsCfg.Region, endpoint: endpoint}, nil
 }
 
-// ClassifyError 根据错误信息分类返回退出码
-// 网络错误关键词: unable to locate credentials, could not connect, connection refused, network, timeout, timed out, unreachable
-// 资源不存在关键词: resourcenotfoundexception, does not exist, not found, cannot find
-func ClassifyError(err error) int {
-	if err == nil {
-		return exitcode.Success
+// loadConfig 按 Profile 和 Region 加载 AWS 配置
+// 未配置 Region 时返回参数错误；凭证获取失败包装为 CredentialsError，便于分类
+func loadConfig(ctx context.Context, clientOpts ClientOptions) (aws.Config, error) {
+	// 如果指定了 profile，则使用该 profile
+	opts := []func(*config.LoadOptions) error{}
+	if clientOpts.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(clientOpts.Profile))
+	}
+	// 如果指定了 region，则覆盖 profile 和环境变量中的配置
+	if clientOpts.Region != "" {
+		opts = append(opts, config.WithRegion(clientOpts.Region))
 	}
 
-	errMsg := strings.ToLower(err.Error())
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, WrapError("LoadConfig", err)
+	}
 
-	// 检查网络错误关键词
-	networkKeywords := []string{
-		"unable to locate credentials",
-		"could not connect",
-		"connection refused",
-		"network",
-		"timeout",
-		"timed out",
-		"unreachable",
+	if awsCfg.Region == "" {
+		return aws.Config{}, &Error{Op: "LoadConfig", Code: exitcode.ParamError, Err: errors.New("未配置 AWS Region，请通过 --region、samconfig.toml 的 region 参数或 AWS 配置指定")}
 	}
-	for _, keyword := range networkKeywords {
-		if strings.Contains(errMsg, keyword) {
-			return exitcode.NetworkError
-		}
+
+	if awsCfg.Credentials != nil {
+		awsCfg.Credentials = credentialsProvider{provider: awsCfg.Credentials}
 	}
+	return awsCfg, nil
+}
 
-	// 检查资源不存在关键词
-	resourceNotFoundKeywords := []string{
-		"resourcenotfoundexception",
-		"does not exist",
-		"not found",
-		"cannot find",
+// resolveEndpoint 返回实际访问的 Lambda API 地址
+// 指定了 endpointURL 时直接使用，否则按 region 解析 AWS 默认地址
+func resolveEndpoint(ctx context.Context, region, endpointURL string) (string, error) {
+	if endpointURL != "" {
+		return endpointURL, nil
 	}
-	for _, keyword := range resourceNotFoundKeywords {
-		if strings.Contains(errMsg, keyword) {
-			return exitcode.ResourceNotFound
-		}
+
+	endpoint, err := lambda.NewDefaultEndpointResolverV2().ResolveEndpoint(ctx, lambda.EndpointParameters{
+		Region: aws.String(region),
+	})
+	if err != nil {
+		return "", fmt.Errorf("无法解析 region '%s' 的 Lambda API 地址: %w", region, err)
 	}
+	return endpoint.URI.String(), nil
+}
+
+// NewClientWithAPI 使用指定的 LambdaAPI 实现创建客户端
+// 用于注入 FakeLambda 等非 AWS 实现
+func NewClientWithAPI(api LambdaAPI) *Client {
+	return &Client{client: api}
+}
+
+// Region 返回客户端使用的 AWS Region
+// 通过 NewClientWithAPI 创建的客户端返回空字符串
+func (c *Client) Region() string {
+	return c.region
+}
+
+// Endpoint 返回客户端访问的 Lambda API 地址
+// 通过 NewClientWithAPI 创建的客户端返回空字符串
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// SetRetryPolicy 设置重试策略
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
 
-	// 其他 AWS 错误
-	return exitcode.AWSError
+// SetPollInterval 设置等待函数更新时的查询间隔
+func (c *Client) SetPollInterval(interval time.Duration) {
+	c.pollInterval = interval
 }
 
 // CreateVersion 创建新版本
-// 返回: 版本号, 错误
+// 返回: 版本号, 错误（*Error）
 func (c *Client) CreateVersion(ctx context.Context, functionName, description string) (string, error) {
 	input := &lambda.PublishVersionInput{
 		FunctionName: aws.String(functionName),
 		Description:  aws.String(description),
 	}
 
-	result, err := c.client.PublishVersion(ctx, input)
+	var result *lambda.PublishVersionOutput
+	err := c.withRetry(ctx, "PublishVersion", func() (err error) {
+		result, err = c.client.PublishVersion(ctx, input)
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
@@ -98,27 +166,73 @@ func (c *Client) CreateVersion(ctx context.Context, functionName, description st
 	return aws.ToString(result.Version), nil
 }
 
-// GetAliasVersion 获取别名指向的版本
-// 返回: 版本号, 退出码
-func (c *Client) GetAliasVersion(ctx context.Context, functionName, aliasName string) (string, int) {
+// GetAliasState 获取别名的完整状态
+// 返回: 别名状态, 错误（*Error，例如别名不存在时退出码为 ResourceNotFound）
+func (c *Client) GetAliasState(ctx context.Context, functionName, aliasName string) (*AliasState, error) {
 	input := &lambda.GetAliasInput{
 		FunctionName: aws.String(functionName),
 		Name:         aws.String(aliasName),
 	}
 
-	result, err := c.client.GetAlias(ctx, input)
+	var result *lambda.GetAliasOutput
+	err := c.withRetry(ctx, "GetAlias", func() (err error) {
+		result, err = c.client.GetAlias(ctx, input)
+		return err
+	})
 	if err != nil {
-		exitCode := ClassifyError(err)
-		output.Error("%v", err)
-		return "", exitCode
+		return nil, err
 	}
 
-	return aws.ToString(result.FunctionVersion), exitcode.Success
+	state := &AliasState{
+		Name:        aws.ToString(result.Name),
+		Version:     aws.ToString(result.FunctionVersion),
+		Weights:     map[string]float64{},
+		Description: aws.ToString(result.Description),
+		RevisionID:  aws.ToString(result.RevisionId),
+	}
+	if state.Name == "" {
+		state.Name = aliasName
+	}
+	if result.RoutingConfig != nil {
+		for version, weight := range result.RoutingConfig.AdditionalVersionWeights {
+			state.Weights[version] = weight
+		}
+	}
+	return state, nil
+}
+
+// CreateAlias 创建指向指定版本的别名（不配置路由）
+// 别名已存在（例如在检查后被其他操作创建）时返回 StateChanged，不会修改已有别名
+// 返回: 错误（*Error）
+func (c *Client) CreateAlias(ctx context.Context, functionName, aliasName, version, description string) error {
+	input := &lambda.CreateAliasInput{
+		FunctionName:    aws.String(functionName),
+		Name:            aws.String(aliasName),
+		FunctionVersion: aws.String(version),
+		Description:     aws.String(description),
+	}
+
+	return c.withRetry(ctx, "CreateAlias", func() error {
+		_, err := c.client.CreateAlias(ctx, input)
+		// 别名已存在和函数正在更新都返回 ResourceConflictException，
+		// 只有后者值得重试，因此通过 GetAlias 区分
+		if ClassifyError(err) == exitcode.Conflict {
+			_, getErr := c.client.GetAlias(ctx, &lambda.GetAliasInput{
+				FunctionName: input.FunctionName,
+				Name:         input.Name,
+			})
+			if getErr == nil {
+				return &Error{Op: "CreateAlias", Code: exitcode.StateChanged, Err: err}
+			}
+		}
+		return err
+	})
 }
 
 // UpdateAlias 更新别名指向（清除路由配置）
-// 返回: 退出码
-func (c *Client) UpdateAlias(ctx context.Context, functionName, aliasName, version string) int {
+// revisionID 为读取别名时得到的 RevisionId，别名在此之后被修改则返回 StateChanged；为空时不检查
+// 返回: 新的 RevisionId, 退出码
+func (c *Client) UpdateAlias(ctx context.Context, functionName, aliasName, version, revisionID string) (string, int) {
 	input := &lambda.UpdateAliasInput{
 		FunctionName:    aws.String(functionName),
 		Name:            aws.String(aliasName),
@@ -129,20 +243,30 @@ func (c *Client) UpdateAlias(ctx context.Context, functionName, aliasName, versi
 		},
 	}
 
-	_, err := c.client.UpdateAlias(ctx, input)
-	if err != nil {
-		exitCode := ClassifyError(err)
-		output.Error("%v", err)
-		return exitCode
+	return c.updateAlias(ctx, input, revisionID)
+}
+
+// UpdateAliasWithDescription 更新别名指向（清除路由配置）并设置别名描述
+// revisionID 同 UpdateAlias
+// 返回: 新的 RevisionId, 退出码
+func (c *Client) UpdateAliasWithDescription(ctx context.Context, functionName, aliasName, version, description, revisionID string) (string, int) {
+	input := &lambda.UpdateAliasInput{
+		FunctionName:    aws.String(functionName),
+		Name:            aws.String(aliasName),
+		FunctionVersion: aws.String(version),
+		Description:     aws.String(description),
+		RoutingConfig: &types.AliasRoutingConfiguration{
+			AdditionalVersionWeights: map[string]float64{},
+		},
 	}
 
-	return exitcode.Success
+	return c.updateAlias(ctx, input, revisionID)
 }
 
 // ConfigureCanary 配置灰度流量
-// 参数: functionName, aliasName, mainVersion, canaryVersion, weight (0.0-1.0)
-// 返回: 退出码
-func (c *Client) ConfigureCanary(ctx context.Context, functionName, aliasName, mainVersion, canaryVersion string, weight float64) int {
+// 参数: functionName, aliasName, mainVersion, canaryVersion, weight (0.0-1.0), revisionID (同 UpdateAlias)
+// 返回: 新的 RevisionId, 退出码
+func (c *Client) ConfigureCanary(ctx context.Context, functionName, aliasName, mainVersion, canaryVersion string, weight float64, revisionID string) (string, int) {
 	input := &lambda.UpdateAliasInput{
 		FunctionName:    aws.String(functionName),
 		Name:            aws.String(aliasName),
@@ -154,41 +278,69 @@ func (c *Client) ConfigureCanary(ctx context.Context, functionName, aliasName, m
 		},
 	}
 
-	_, err := c.client.UpdateAlias(ctx, input)
-	if err != nil {
-		exitCode := ClassifyError(err)
-		output.Error("%v", err)
-		return exitCode
+	return c.updateAlias(ctx, input, revisionID)
+}
+
+// ConfigureCanaryWithDescription 配置灰度流量并设置别名描述
+// 参数同 ConfigureCanary
+// 返回: 新的 RevisionId, 退出码
+func (c *Client) ConfigureCanaryWithDescription(ctx context.Context, functionName, aliasName, mainVersion, canaryVersion string, weight float64, description, revisionID string) (string, int) {
+	input := &lambda.UpdateAliasInput{
+		FunctionName:    aws.String(functionName),
+		Name:            aws.String(aliasName),
+		FunctionVersion: aws.String(mainVersion),
+		Description:     aws.String(description),
+		RoutingConfig: &types.AliasRoutingConfiguration{
+			AdditionalVersionWeights: map[string]float64{
+				canaryVersion: weight,
+			},
+		},
 	}
 
-	return exitcode.Success
+	return c.updateAlias(ctx, input, revisionID)
 }
 
-// CheckCanaryActive 检查是否有活跃的灰度配置
-// 返回: 是否活跃, 灰度版本, 权重
-func (c *Client) CheckCanaryActive(ctx context.Context, functionName, aliasName string) (bool, string, float64) {
-	input := &lambda.GetAliasInput{
-		FunctionName: aws.String(functionName),
-		Name:         aws.String(aliasName),
+// updateAlias 执行 UpdateAlias，别名被并发修改时输出别名的当前状态
+func (c *Client) updateAlias(ctx context.Context, input *lambda.UpdateAliasInput, revisionID string) (string, int) {
+	if revisionID != "" {
+		input.RevisionId = aws.String(revisionID)
 	}
 
-	result, err := c.client.GetAlias(ctx, input)
+	var result *lambda.UpdateAliasOutput
+	err := c.withRetry(ctx, "UpdateAlias", func() (err error) {
+		result, err = c.client.UpdateAlias(ctx, input)
+		return err
+	})
 	if err != nil {
-		// 如果获取别名失败，返回无活跃灰度
-		return false, "", 0
+		exitCode := ClassifyError(err)
+		if exitCode == exitcode.StateChanged {
+			c.reportStateChanged(ctx, aws.ToString(input.FunctionName), aws.ToString(input.Name))
+			return "", exitCode
+		}
+		output.Error("%v", err)
+		return "", exitCode
 	}
 
-	// 检查是否有路由配置
-	if result.RoutingConfig == nil || len(result.RoutingConfig.AdditionalVersionWeights) == 0 {
-		return false, "", 0
-	}
+	return aws.ToString(result.RevisionId), exitcode.Success
+}
+
+// reportStateChanged 输出别名被其他操作修改后的当前状态
+func (c *Client) reportStateChanged(ctx context.Context, functionName, aliasName string) {
+	output.Error("别名 %s 在读取后已被其他操作修改，本次修改未执行", aliasName)
 
-	// 获取灰度版本和权重
-	for version, weight := range result.RoutingConfig.AdditionalVersionWeights {
-		return true, version, weight
+	state, err := c.GetAliasState(ctx, functionName, aliasName)
+	if err != nil {
+		output.Warning("无法获取别名 %s 的当前状态: %v", aliasName, err)
+		return
 	}
 
-	return false, "", 0
+	output.Info("别名 %s 当前状态:", aliasName)
+	output.Info("  - 流量分配: %s", state.RoutingSummary())
+	if state.Description != "" {
+		output.Info("  - 描述: %s", state.Description)
+	}
+	output.Info("  - RevisionId: %s", state.RevisionID)
+	output.Info("请使用 'lad status' 确认当前状态后重新执行")
 }
 
 // VerifyVersionExists 验证版本是否存在
@@ -200,7 +352,10 @@ func (c *Client) VerifyVersionExists(ctx context.Context, functionName, version
 		Qualifier:    aws.String(version),
 	}
 
-	_, err := c.client.GetFunction(ctx, input)
+	err := c.withRetry(ctx, "GetFunction", func() error {
+		_, err := c.client.GetFunction(ctx, input)
+		return err
+	})
 	if err != nil {
 		exitCode := ClassifyError(err)
 		output.Error("%v", err)
@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/output"
+	"github.com/aws/smithy-go"
+)
+
+// RetryPolicy 描述 Lambda API 调用失败时的重试策略
+// 重试间隔按指数增长（BaseDelay * 2^n，不超过 MaxDelay），并加入随机抖动
+type RetryPolicy struct {
+	MaxAttempts int           // 最大尝试次数（包括首次调用），1 表示不重试
+	MaxElapsed  time.Duration // 从首次调用开始的最长重试时间，0 表示不限制
+	BaseDelay   time.Duration // 首次重试前的等待时间
+	MaxDelay    time.Duration // 单次等待时间上限
+}
+
+// DefaultRetryPolicy 返回默认的重试策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		MaxElapsed:  2 * time.Minute,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    20 * time.Second,
+	}
+}
+
+// withDefaults 将未设置的字段替换为默认值
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	defaults := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaults.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = defaults.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaults.MaxDelay
+	}
+	return p
+}
+
+// delay 计算第 attempt 次重试前的等待时间（attempt 从 1 开始）
+// 使用 equal jitter：一半固定，一半随机，避免多个进程同时重试
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// IsRetryable 判断错误是否可以重试
+// 可重试: 限流、资源冲突、网络错误和服务端错误
+// 不可重试: StateChanged（别名已被修改，重试会覆盖其他人的修改）和其他客户端错误
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch ClassifyError(err) {
+	case exitcode.Throttled, exitcode.Conflict, exitcode.NetworkError:
+		return true
+	}
+
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorFault() == smithy.FaultServer
+}
+
+// withRetry 按重试策略执行 fn，返回包装后的错误（*Error）
+// 每次重试前通过 output.Warning 报告失败原因和等待时间
+func (c *Client) withRetry(ctx context.Context, op string, fn func() error) error {
+	policy := c.retry.withDefaults()
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt >= policy.MaxAttempts || !IsRetryable(err) || ctx.Err() != nil {
+			return WrapError(op, err)
+		}
+
+		wait := policy.delay(attempt)
+		if policy.MaxElapsed > 0 && time.Since(start)+wait > policy.MaxElapsed {
+			output.Warning("%s 失败，已达到最长重试时间 %v", op, policy.MaxElapsed)
+			return WrapError(op, err)
+		}
+
+		output.Warning("%s 失败 (%v)，%v 后重试 (%d/%d)", op, retryReason(err), wait.Round(time.Millisecond), attempt, policy.MaxAttempts-1)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return WrapError(op, err)
+		case <-timer.C:
+		}
+	}
+}
+
+// retryReason 返回用于重试提示的简短原因
+func retryReason(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	if ClassifyError(err) == exitcode.NetworkError {
+		return "网络错误"
+	}
+	return err.Error()
+}
@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// FunctionTags 获取函数的全部标签
+// 返回: 标签, 错误（*Error，例如函数不存在时退出码为 ResourceNotFound）
+func (c *Client) FunctionTags(ctx context.Context, functionName string) (map[string]string, error) {
+	_, tags, err := c.functionArnAndTags(ctx, functionName)
+	return tags, err
+}
+
+// TagFunction 为函数添加或覆盖标签
+// 返回: 错误（*Error）
+func (c *Client) TagFunction(ctx context.Context, functionName string, tags map[string]string) error {
+	arn, _, err := c.functionArnAndTags(ctx, functionName)
+	if err != nil {
+		return err
+	}
+
+	input := &lambda.TagResourceInput{
+		Resource: aws.String(arn),
+		Tags:     tags,
+	}
+	return c.withRetry(ctx, "TagResource", func() error {
+		_, err := c.client.TagResource(ctx, input)
+		return err
+	})
+}
+
+// UntagFunction 删除函数的指定标签，不存在的标签会被忽略
+// 返回: 错误（*Error）
+func (c *Client) UntagFunction(ctx context.Context, functionName string, keys []string) error {
+	arn, _, err := c.functionArnAndTags(ctx, functionName)
+	if err != nil {
+		return err
+	}
+
+	input := &lambda.UntagResourceInput{
+		Resource: aws.String(arn),
+		TagKeys:  keys,
+	}
+	return c.withRetry(ctx, "UntagResource", func() error {
+		_, err := c.client.UntagResource(ctx, input)
+		return err
+	})
+}
+
+// functionArnAndTags 通过 GetFunction 获取函数的 ARN（不带版本限定符）和标签
+// 标签只能设置在函数上，不能设置在版本或别名上
+func (c *Client) functionArnAndTags(ctx context.Context, functionName string) (string, map[string]string, error) {
+	input := &lambda.GetFunctionInput{FunctionName: aws.String(functionName)}
+
+	var result *lambda.GetFunctionOutput
+	err := c.withRetry(ctx, "GetFunction", func() (err error) {
+		result, err = c.client.GetFunction(ctx, input)
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var arn string
+	if result.Configuration != nil {
+		arn = aws.ToString(result.Configuration.FunctionArn)
+	}
+	// arn:aws:lambda:region:account:function:name[:qualifier]
+	if parts := strings.Split(arn, ":"); len(parts) == 8 {
+		arn = strings.Join(parts[:7], ":")
+	}
+
+	tags := make(map[string]string, len(result.Tags))
+	for k, v := range result.Tags {
+		tags[k] = v
+	}
+	return arn, tags, nil
+}
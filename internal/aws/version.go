@@ -0,0 +1,169 @@
+package aws
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// lambdaTimeLayout 是 Lambda API 返回的 LastModified 时间格式
+const lambdaTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// VersionInfo 是已发布版本的信息
+type VersionInfo struct {
+	Version       string    // 版本号
+	Description   string    // 版本描述
+	Created       time.Time // 创建时间（版本发布后不可修改，LastModified 即创建时间）
+	CodeSha256    string    // 代码包哈希
+	CodeSize      int64     // 代码包大小（字节）
+	Runtime       string    // 运行时，镜像函数为空
+	Architectures []string  // 指令集架构
+}
+
+// ListVersions 列出函数所有已发布的版本（不包括 $LATEST），按版本号降序排列
+// 返回: 版本列表, 错误（*Error）
+func (c *Client) ListVersions(ctx context.Context, functionName string) ([]VersionInfo, error) {
+	var versions []VersionInfo
+	var marker *string
+	for {
+		input := &lambda.ListVersionsByFunctionInput{
+			FunctionName: aws.String(functionName),
+			Marker:       marker,
+		}
+
+		var result *lambda.ListVersionsByFunctionOutput
+		err := c.withRetry(ctx, "ListVersionsByFunction", func() (err error) {
+			result, err = c.client.ListVersionsByFunction(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, config := range result.Versions {
+			version := aws.ToString(config.Version)
+			if version == "$LATEST" {
+				continue
+			}
+
+			info := VersionInfo{
+				Version:     version,
+				Description: aws.ToString(config.Description),
+				CodeSha256:  aws.ToString(config.CodeSha256),
+				CodeSize:    config.CodeSize,
+				Runtime:     string(config.Runtime),
+			}
+			if created, err := time.Parse(lambdaTimeLayout, aws.ToString(config.LastModified)); err == nil {
+				info.Created = created
+			}
+			for _, arch := range config.Architectures {
+				info.Architectures = append(info.Architectures, string(arch))
+			}
+			versions = append(versions, info)
+		}
+
+		if aws.ToString(result.NextMarker) == "" {
+			break
+		}
+		marker = result.NextMarker
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versionLess(versions[j].Version, versions[i].Version)
+	})
+	return versions, nil
+}
+
+// DeleteVersion 删除已发布的版本
+// 被别名引用的版本会被 Lambda 拒绝删除（ResourceConflictException）
+// 返回: 错误（*Error）
+func (c *Client) DeleteVersion(ctx context.Context, functionName, version string) error {
+	input := &lambda.DeleteFunctionInput{
+		FunctionName: aws.String(functionName),
+		Qualifier:    aws.String(version),
+	}
+
+	return c.withRetry(ctx, "DeleteFunction", func() error {
+		_, err := c.client.DeleteFunction(ctx, input)
+		return err
+	})
+}
+
+// CodeStorage 是账户的代码存储用量
+type CodeStorage struct {
+	Used  int64 // 已使用的字节数
+	Limit int64 // 限额（字节）
+}
+
+// GetCodeStorage 通过 GetAccountSettings 获取当前 region 的账户代码存储用量
+// 返回: 代码存储用量, 错误（*Error）
+func (c *Client) GetCodeStorage(ctx context.Context) (*CodeStorage, error) {
+	var result *lambda.GetAccountSettingsOutput
+	err := c.withRetry(ctx, "GetAccountSettings", func() (err error) {
+		result, err = c.client.GetAccountSettings(ctx, &lambda.GetAccountSettingsInput{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	storage := &CodeStorage{}
+	if result.AccountUsage != nil {
+		storage.Used = result.AccountUsage.TotalCodeSize
+	}
+	if result.AccountLimit != nil {
+		storage.Limit = result.AccountLimit.TotalCodeSize
+	}
+	return storage, nil
+}
+
+// ListAliases 列出函数的所有别名，按名称排序
+// 返回: 别名状态列表, 错误（*Error）
+func (c *Client) ListAliases(ctx context.Context, functionName string) ([]*AliasState, error) {
+	var aliases []*AliasState
+	var marker *string
+	for {
+		input := &lambda.ListAliasesInput{
+			FunctionName: aws.String(functionName),
+			Marker:       marker,
+		}
+
+		var result *lambda.ListAliasesOutput
+		err := c.withRetry(ctx, "ListAliases", func() (err error) {
+			result, err = c.client.ListAliases(ctx, input)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, alias := range result.Aliases {
+			state := &AliasState{
+				Name:        aws.ToString(alias.Name),
+				Version:     aws.ToString(alias.FunctionVersion),
+				Weights:     map[string]float64{},
+				Description: aws.ToString(alias.Description),
+				RevisionID:  aws.ToString(alias.RevisionId),
+			}
+			if alias.RoutingConfig != nil {
+				for version, weight := range alias.RoutingConfig.AdditionalVersionWeights {
+					state.Weights[version] = weight
+				}
+			}
+			aliases = append(aliases, state)
+		}
+
+		if aws.ToString(result.NextMarker) == "" {
+			break
+		}
+		marker = result.NextMarker
+	}
+
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].Name < aliases[j].Name
+	})
+	return aliases, nil
+}
@@ -11,6 +11,7 @@ import (
 type DeployParameters struct {
 	StackName string `toml:"stack_name"`
 	Profile   string `toml:"profile"`
+	Region    string `toml:"region"`
 }
 
 // Deploy 表示 deploy 配置
@@ -18,9 +19,25 @@ type Deploy struct {
 	Parameters DeployParameters `toml:"parameters"`
 }
 
+// LadParameters 表示 lad 自身的参数
+type LadParameters struct {
+	RetryMaxAttempts int      `toml:"retry_max_attempts"`
+	RetryMaxElapsed  string   `toml:"retry_max_elapsed"`
+	SAMPath          string   `toml:"sam_path"`
+	AuditLog         []string `toml:"audit_log"`
+	AutoStrategy     string   `toml:"auto_strategy"`
+	AutoOnInterrupt  string   `toml:"auto_on_interrupt"`
+}
+
+// Lad 表示 lad 配置
+type Lad struct {
+	Parameters LadParameters `toml:"parameters"`
+}
+
 // EnvConfig 表示环境配置
 type EnvConfig struct {
 	Deploy Deploy `toml:"deploy"`
+	Lad    Lad    `toml:"lad"`
 }
 
 // SAMConfig 表示 samconfig.toml 的结构
@@ -68,6 +85,50 @@ func LoadSAMConfig(path string) (*SAMConfig, error) {
 				if profile, ok := paramsMap["profile"].(string); ok {
 					envConfig.Deploy.Parameters.Profile = profile
 				}
+				if region, ok := paramsMap["region"].(string); ok {
+					envConfig.Deploy.Parameters.Region = region
+				}
+			}
+		}
+
+		// 解析 global 配置，deploy 中未指定 region 时使用
+		if globalMap, ok := envMap["global"].(map[string]interface{}); ok {
+			if paramsMap, ok := globalMap["parameters"].(map[string]interface{}); ok {
+				if region, ok := paramsMap["region"].(string); ok && envConfig.Deploy.Parameters.Region == "" {
+					envConfig.Deploy.Parameters.Region = region
+				}
+			}
+		}
+
+		// 解析 lad 配置
+		if ladMap, ok := envMap["lad"].(map[string]interface{}); ok {
+			if paramsMap, ok := ladMap["parameters"].(map[string]interface{}); ok {
+				if attempts, ok := paramsMap["retry_max_attempts"].(int64); ok {
+					envConfig.Lad.Parameters.RetryMaxAttempts = int(attempts)
+				}
+				if elapsed, ok := paramsMap["retry_max_elapsed"].(string); ok {
+					envConfig.Lad.Parameters.RetryMaxElapsed = elapsed
+				}
+				if samPath, ok := paramsMap["sam_path"].(string); ok {
+					envConfig.Lad.Parameters.SAMPath = samPath
+				}
+				if strategy, ok := paramsMap["auto_strategy"].(string); ok {
+					envConfig.Lad.Parameters.AutoStrategy = strategy
+				}
+				if policy, ok := paramsMap["auto_on_interrupt"].(string); ok {
+					envConfig.Lad.Parameters.AutoOnInterrupt = policy
+				}
+				// audit_log 可以是单个字符串或字符串数组
+				switch auditLog := paramsMap["audit_log"].(type) {
+				case string:
+					envConfig.Lad.Parameters.AuditLog = []string{auditLog}
+				case []interface{}:
+					for _, item := range auditLog {
+						if spec, ok := item.(string); ok {
+							envConfig.Lad.Parameters.AuditLog = append(envConfig.Lad.Parameters.AuditLog, spec)
+						}
+					}
+				}
 			}
 		}
 
@@ -93,6 +154,69 @@ func (c *SAMConfig) GetProfile(env string) string {
 	return ""
 }
 
+// GetRegion 获取指定环境的 AWS region
+// 优先使用 [env.deploy.parameters]，其次是 [env.global.parameters]
+func (c *SAMConfig) GetRegion(env string) string {
+	if envConfig, ok := c.envConfigs[env]; ok {
+		return envConfig.Deploy.Parameters.Region
+	}
+	return ""
+}
+
+// GetRetryMaxAttempts 获取指定环境的最大尝试次数
+// 未配置时返回 0
+func (c *SAMConfig) GetRetryMaxAttempts(env string) int {
+	if envConfig, ok := c.envConfigs[env]; ok {
+		return envConfig.Lad.Parameters.RetryMaxAttempts
+	}
+	return 0
+}
+
+// GetRetryMaxElapsed 获取指定环境的重试总时长（例如 "2m"）
+// 未配置时返回空字符串
+func (c *SAMConfig) GetRetryMaxElapsed(env string) string {
+	if envConfig, ok := c.envConfigs[env]; ok {
+		return envConfig.Lad.Parameters.RetryMaxElapsed
+	}
+	return ""
+}
+
+// GetSAMPath 获取指定环境的 SAM CLI 路径
+// 未配置时返回空字符串
+func (c *SAMConfig) GetSAMPath(env string) string {
+	if envConfig, ok := c.envConfigs[env]; ok {
+		return envConfig.Lad.Parameters.SAMPath
+	}
+	return ""
+}
+
+// GetAuditLog 获取指定环境的审计日志输出
+// 未配置时返回 nil
+func (c *SAMConfig) GetAuditLog(env string) []string {
+	if envConfig, ok := c.envConfigs[env]; ok {
+		return envConfig.Lad.Parameters.AuditLog
+	}
+	return nil
+}
+
+// GetAutoStrategy 获取指定环境的 auto 流量切换策略（例如 "Canary10Percent5Minutes"）
+// 未配置时返回空字符串
+func (c *SAMConfig) GetAutoStrategy(env string) string {
+	if envConfig, ok := c.envConfigs[env]; ok {
+		return envConfig.Lad.Parameters.AutoStrategy
+	}
+	return ""
+}
+
+// GetAutoOnInterrupt 获取指定环境的 auto 中断处理方式（hold、revert 或 rollback）
+// 未配置时返回空字符串
+func (c *SAMConfig) GetAutoOnInterrupt(env string) string {
+	if envConfig, ok := c.envConfigs[env]; ok {
+		return envConfig.Lad.Parameters.AutoOnInterrupt
+	}
+	return ""
+}
+
 // GetFunctionName 根据 stack_name 和环境生成函数名
 // 格式: {stack_name}-function-default
 func (c *SAMConfig) GetFunctionName(env string) string {
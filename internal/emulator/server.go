@@ -0,0 +1,715 @@
+// Package emulator provides a local HTTP stand-in for the Lambda alias and version API.
+package emulator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aura-studio/lad/internal/aws"
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+)
+
+// apiPrefix 是 Lambda REST API 的路径前缀
+const apiPrefix = "/2015-03-31/functions/{FunctionName}"
+
+// accountSettingsPath 是 GetAccountSettings 的路径
+const accountSettingsPath = "/2016-08-19/account-settings"
+
+// tagsPath 是 TagResource 和 UntagResource 的路径
+const tagsPath = "/2017-03-31/tags/{ARN}"
+
+// adminPrefix 是模拟服务自身管理接口的路径前缀（不属于 Lambda API）
+const adminPrefix = "/_emulator/functions/{FunctionName}"
+
+// Server 是基于 FakeLambda 的 Lambda REST API 模拟服务
+// 只实现 lad 使用的 API 子集
+type Server struct {
+	store   *aws.FakeLambda
+	persist func() error
+	mux     *http.ServeMux
+}
+
+// New 创建模拟服务
+// persist 在每次写操作成功后调用，用于持久化状态；为 nil 时只保存在内存中
+func New(store *aws.FakeLambda, persist func() error) *Server {
+	s := &Server{
+		store:   store,
+		persist: persist,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET "+apiPrefix, s.getFunction)
+	s.mux.HandleFunc("DELETE "+apiPrefix, s.deleteFunction)
+	s.mux.HandleFunc("GET "+apiPrefix+"/configuration", s.getFunctionConfiguration)
+	s.mux.HandleFunc("PUT "+apiPrefix+"/code", s.updateFunctionCode)
+	s.mux.HandleFunc("POST "+apiPrefix+"/versions", s.publishVersion)
+	s.mux.HandleFunc("GET "+apiPrefix+"/versions", s.listVersionsByFunction)
+	s.mux.HandleFunc("POST "+apiPrefix+"/aliases", s.createAlias)
+	s.mux.HandleFunc("GET "+apiPrefix+"/aliases", s.listAliases)
+	s.mux.HandleFunc("GET "+apiPrefix+"/aliases/{Name}", s.getAlias)
+	s.mux.HandleFunc("PUT "+apiPrefix+"/aliases/{Name}", s.updateAlias)
+	s.mux.HandleFunc("GET "+accountSettingsPath, s.getAccountSettings)
+	s.mux.HandleFunc("POST "+tagsPath, s.tagResource)
+	s.mux.HandleFunc("DELETE "+tagsPath, s.untagResource)
+	s.mux.HandleFunc("POST "+adminPrefix+"/deploy", s.deploy)
+	s.mux.HandleFunc("/", s.notFound)
+
+	return s
+}
+
+// ServeHTTP 实现 http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// =============================================================================
+// Wire types
+// =============================================================================
+
+// routingConfig 对应 AliasRoutingConfiguration
+type routingConfig struct {
+	AdditionalVersionWeights map[string]float64 `json:"AdditionalVersionWeights"`
+}
+
+// aliasConfiguration 对应 AliasConfiguration
+type aliasConfiguration struct {
+	AliasArn        string         `json:"AliasArn"`
+	Description     string         `json:"Description"`
+	FunctionVersion string         `json:"FunctionVersion"`
+	Name            string         `json:"Name"`
+	RevisionId      string         `json:"RevisionId"`
+	RoutingConfig   *routingConfig `json:"RoutingConfig,omitempty"`
+}
+
+// functionConfiguration 对应 FunctionConfiguration
+type functionConfiguration struct {
+	FunctionName           string   `json:"FunctionName"`
+	FunctionArn            string   `json:"FunctionArn"`
+	Version                string   `json:"Version"`
+	Description            string   `json:"Description"`
+	CodeSha256             string   `json:"CodeSha256"`
+	CodeSize               int64    `json:"CodeSize"`
+	LastModified           string   `json:"LastModified,omitempty"`
+	Runtime                string   `json:"Runtime,omitempty"`
+	Architectures          []string `json:"Architectures,omitempty"`
+	PackageType            string   `json:"PackageType,omitempty"`
+	State                  string   `json:"State,omitempty"`
+	StateReason            string   `json:"StateReason,omitempty"`
+	LastUpdateStatus       string   `json:"LastUpdateStatus,omitempty"`
+	LastUpdateStatusReason string   `json:"LastUpdateStatusReason,omitempty"`
+	RevisionId             string   `json:"RevisionId,omitempty"`
+}
+
+// functionCodeLocation 对应 FunctionCodeLocation
+type functionCodeLocation struct {
+	RepositoryType string `json:"RepositoryType,omitempty"`
+	Location       string `json:"Location,omitempty"`
+}
+
+// getFunctionResponse 对应 GetFunction 的响应
+type getFunctionResponse struct {
+	Configuration functionConfiguration `json:"Configuration"`
+	Code          functionCodeLocation  `json:"Code"`
+	Tags          map[string]string     `json:"Tags"`
+}
+
+// listVersionsResponse 对应 ListVersionsByFunction 的响应
+type listVersionsResponse struct {
+	NextMarker *string                 `json:"NextMarker,omitempty"`
+	Versions   []functionConfiguration `json:"Versions"`
+}
+
+// listAliasesResponse 对应 ListAliases 的响应
+type listAliasesResponse struct {
+	NextMarker *string              `json:"NextMarker,omitempty"`
+	Aliases    []aliasConfiguration `json:"Aliases"`
+}
+
+// publishVersionRequest 对应 PublishVersion 的请求体
+type publishVersionRequest struct {
+	CodeSha256  *string `json:"CodeSha256"`
+	Description *string `json:"Description"`
+	RevisionId  *string `json:"RevisionId"`
+}
+
+// updateFunctionCodeRequest 对应 UpdateFunctionCode 的请求体
+// ZipFile 在 JSON 中为 base64 编码
+type updateFunctionCodeRequest struct {
+	ZipFile         []byte  `json:"ZipFile"`
+	ImageUri        *string `json:"ImageUri"`
+	S3Bucket        *string `json:"S3Bucket"`
+	S3Key           *string `json:"S3Key"`
+	S3ObjectVersion *string `json:"S3ObjectVersion"`
+	RevisionId      *string `json:"RevisionId"`
+}
+
+// createAliasRequest 对应 CreateAlias 的请求体
+type createAliasRequest struct {
+	Name            *string        `json:"Name"`
+	FunctionVersion *string        `json:"FunctionVersion"`
+	Description     *string        `json:"Description"`
+	RoutingConfig   *routingConfig `json:"RoutingConfig"`
+}
+
+// updateAliasRequest 对应 UpdateAlias 的请求体
+type updateAliasRequest struct {
+	FunctionVersion *string        `json:"FunctionVersion"`
+	Description     *string        `json:"Description"`
+	RevisionId      *string        `json:"RevisionId"`
+	RoutingConfig   *routingConfig `json:"RoutingConfig"`
+}
+
+// tagResourceRequest 对应 TagResource 的请求体
+type tagResourceRequest struct {
+	Tags map[string]string `json:"Tags"`
+}
+
+// accountLimit 对应 Lambda API 的 AccountLimit
+type accountLimit struct {
+	TotalCodeSize                  int64  `json:"TotalCodeSize"`
+	CodeSizeUnzipped               int64  `json:"CodeSizeUnzipped"`
+	CodeSizeZipped                 int64  `json:"CodeSizeZipped"`
+	ConcurrentExecutions           int32  `json:"ConcurrentExecutions"`
+	UnreservedConcurrentExecutions *int32 `json:"UnreservedConcurrentExecutions,omitempty"`
+}
+
+// accountUsage 对应 Lambda API 的 AccountUsage
+type accountUsage struct {
+	TotalCodeSize int64 `json:"TotalCodeSize"`
+	FunctionCount int64 `json:"FunctionCount"`
+}
+
+// accountSettingsResponse 对应 GetAccountSettings 的响应
+type accountSettingsResponse struct {
+	AccountLimit accountLimit `json:"AccountLimit"`
+	AccountUsage accountUsage `json:"AccountUsage"`
+}
+
+// deployResponse 对应模拟部署接口的响应
+type deployResponse struct {
+	Version string `json:"Version"`
+}
+
+// =============================================================================
+// Handlers
+// =============================================================================
+
+func (s *Server) getFunction(w http.ResponseWriter, r *http.Request) {
+	input := &lambda.GetFunctionInput{FunctionName: sdkaws.String(r.PathValue("FunctionName"))}
+	if qualifier := r.URL.Query().Get("Qualifier"); qualifier != "" {
+		input.Qualifier = sdkaws.String(qualifier)
+	}
+
+	out, err := s.store.GetFunction(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resp := getFunctionResponse{Tags: out.Tags}
+	if resp.Tags == nil {
+		resp.Tags = map[string]string{}
+	}
+	if out.Configuration != nil {
+		resp.Configuration = toFunctionConfiguration(*out.Configuration)
+	}
+	if out.Code != nil {
+		resp.Code = functionCodeLocation{
+			RepositoryType: sdkaws.ToString(out.Code.RepositoryType),
+			Location:       sdkaws.ToString(out.Code.Location),
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) deleteFunction(w http.ResponseWriter, r *http.Request) {
+	input := &lambda.DeleteFunctionInput{FunctionName: sdkaws.String(r.PathValue("FunctionName"))}
+	if qualifier := r.URL.Query().Get("Qualifier"); qualifier != "" {
+		input.Qualifier = sdkaws.String(qualifier)
+	}
+
+	if _, err := s.store.DeleteFunction(r.Context(), input); err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.save(w) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getAccountSettings(w http.ResponseWriter, r *http.Request) {
+	out, err := s.store.GetAccountSettings(r.Context(), &lambda.GetAccountSettingsInput{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var resp accountSettingsResponse
+	if out.AccountLimit != nil {
+		resp.AccountLimit = accountLimit{
+			TotalCodeSize:                  out.AccountLimit.TotalCodeSize,
+			CodeSizeUnzipped:               out.AccountLimit.CodeSizeUnzipped,
+			CodeSizeZipped:                 out.AccountLimit.CodeSizeZipped,
+			ConcurrentExecutions:           out.AccountLimit.ConcurrentExecutions,
+			UnreservedConcurrentExecutions: out.AccountLimit.UnreservedConcurrentExecutions,
+		}
+	}
+	if out.AccountUsage != nil {
+		resp.AccountUsage = accountUsage{
+			TotalCodeSize: out.AccountUsage.TotalCodeSize,
+			FunctionCount: out.AccountUsage.FunctionCount,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getFunctionConfiguration(w http.ResponseWriter, r *http.Request) {
+	input := &lambda.GetFunctionConfigurationInput{FunctionName: sdkaws.String(r.PathValue("FunctionName"))}
+	if qualifier := r.URL.Query().Get("Qualifier"); qualifier != "" {
+		input.Qualifier = sdkaws.String(qualifier)
+	}
+
+	out, err := s.store.GetFunctionConfiguration(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// $LATEST 的更新状态在查询时推进
+	if !s.save(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, toFunctionConfiguration(types.FunctionConfiguration{
+		FunctionName:           out.FunctionName,
+		FunctionArn:            out.FunctionArn,
+		Version:                out.Version,
+		Description:            out.Description,
+		CodeSha256:             out.CodeSha256,
+		CodeSize:               out.CodeSize,
+		LastModified:           out.LastModified,
+		Runtime:                out.Runtime,
+		Architectures:          out.Architectures,
+		PackageType:            out.PackageType,
+		State:                  out.State,
+		StateReason:            out.StateReason,
+		LastUpdateStatus:       out.LastUpdateStatus,
+		LastUpdateStatusReason: out.LastUpdateStatusReason,
+		RevisionId:             out.RevisionId,
+	}))
+}
+
+func (s *Server) updateFunctionCode(w http.ResponseWriter, r *http.Request) {
+	var req updateFunctionCodeRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	out, err := s.store.UpdateFunctionCode(r.Context(), &lambda.UpdateFunctionCodeInput{
+		FunctionName:    sdkaws.String(r.PathValue("FunctionName")),
+		ZipFile:         req.ZipFile,
+		ImageUri:        req.ImageUri,
+		S3Bucket:        req.S3Bucket,
+		S3Key:           req.S3Key,
+		S3ObjectVersion: req.S3ObjectVersion,
+		RevisionId:      req.RevisionId,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.save(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, toFunctionConfiguration(types.FunctionConfiguration{
+		FunctionName:           out.FunctionName,
+		FunctionArn:            out.FunctionArn,
+		Version:                out.Version,
+		Description:            out.Description,
+		CodeSha256:             out.CodeSha256,
+		CodeSize:               out.CodeSize,
+		LastModified:           out.LastModified,
+		Runtime:                out.Runtime,
+		Architectures:          out.Architectures,
+		PackageType:            out.PackageType,
+		State:                  out.State,
+		StateReason:            out.StateReason,
+		LastUpdateStatus:       out.LastUpdateStatus,
+		LastUpdateStatusReason: out.LastUpdateStatusReason,
+		RevisionId:             out.RevisionId,
+	}))
+}
+
+func (s *Server) publishVersion(w http.ResponseWriter, r *http.Request) {
+	var req publishVersionRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	out, err := s.store.PublishVersion(r.Context(), &lambda.PublishVersionInput{
+		FunctionName: sdkaws.String(r.PathValue("FunctionName")),
+		CodeSha256:   req.CodeSha256,
+		Description:  req.Description,
+		RevisionId:   req.RevisionId,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.save(w) {
+		return
+	}
+	writeJSON(w, http.StatusCreated, toFunctionConfiguration(types.FunctionConfiguration{
+		FunctionName:     out.FunctionName,
+		FunctionArn:      out.FunctionArn,
+		Version:          out.Version,
+		Description:      out.Description,
+		CodeSha256:       out.CodeSha256,
+		CodeSize:         out.CodeSize,
+		LastModified:     out.LastModified,
+		Runtime:          out.Runtime,
+		Architectures:    out.Architectures,
+		PackageType:      out.PackageType,
+		State:            out.State,
+		LastUpdateStatus: out.LastUpdateStatus,
+		RevisionId:       out.RevisionId,
+	}))
+}
+
+func (s *Server) listVersionsByFunction(w http.ResponseWriter, r *http.Request) {
+	input := &lambda.ListVersionsByFunctionInput{FunctionName: sdkaws.String(r.PathValue("FunctionName"))}
+	if marker := r.URL.Query().Get("Marker"); marker != "" {
+		input.Marker = sdkaws.String(marker)
+	}
+	if maxItems, ok := queryInt32(w, r, "MaxItems"); !ok {
+		return
+	} else if maxItems != nil {
+		input.MaxItems = maxItems
+	}
+
+	out, err := s.store.ListVersionsByFunction(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resp := listVersionsResponse{NextMarker: out.NextMarker, Versions: []functionConfiguration{}}
+	for _, config := range out.Versions {
+		resp.Versions = append(resp.Versions, toFunctionConfiguration(config))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) createAlias(w http.ResponseWriter, r *http.Request) {
+	var req createAliasRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	out, err := s.store.CreateAlias(r.Context(), &lambda.CreateAliasInput{
+		FunctionName:    sdkaws.String(r.PathValue("FunctionName")),
+		Name:            req.Name,
+		FunctionVersion: req.FunctionVersion,
+		Description:     req.Description,
+		RoutingConfig:   fromRoutingConfig(req.RoutingConfig),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.save(w) {
+		return
+	}
+	writeJSON(w, http.StatusCreated, toAliasConfiguration(types.AliasConfiguration{
+		AliasArn:        out.AliasArn,
+		Description:     out.Description,
+		FunctionVersion: out.FunctionVersion,
+		Name:            out.Name,
+		RevisionId:      out.RevisionId,
+		RoutingConfig:   out.RoutingConfig,
+	}))
+}
+
+func (s *Server) listAliases(w http.ResponseWriter, r *http.Request) {
+	input := &lambda.ListAliasesInput{FunctionName: sdkaws.String(r.PathValue("FunctionName"))}
+	query := r.URL.Query()
+	if version := query.Get("FunctionVersion"); version != "" {
+		input.FunctionVersion = sdkaws.String(version)
+	}
+	if marker := query.Get("Marker"); marker != "" {
+		input.Marker = sdkaws.String(marker)
+	}
+	if maxItems, ok := queryInt32(w, r, "MaxItems"); !ok {
+		return
+	} else if maxItems != nil {
+		input.MaxItems = maxItems
+	}
+
+	out, err := s.store.ListAliases(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resp := listAliasesResponse{NextMarker: out.NextMarker, Aliases: []aliasConfiguration{}}
+	for _, alias := range out.Aliases {
+		resp.Aliases = append(resp.Aliases, toAliasConfiguration(alias))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getAlias(w http.ResponseWriter, r *http.Request) {
+	out, err := s.store.GetAlias(r.Context(), &lambda.GetAliasInput{
+		FunctionName: sdkaws.String(r.PathValue("FunctionName")),
+		Name:         sdkaws.String(r.PathValue("Name")),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toAliasConfiguration(types.AliasConfiguration{
+		AliasArn:        out.AliasArn,
+		Description:     out.Description,
+		FunctionVersion: out.FunctionVersion,
+		Name:            out.Name,
+		RevisionId:      out.RevisionId,
+		RoutingConfig:   out.RoutingConfig,
+	}))
+}
+
+func (s *Server) updateAlias(w http.ResponseWriter, r *http.Request) {
+	var req updateAliasRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	out, err := s.store.UpdateAlias(r.Context(), &lambda.UpdateAliasInput{
+		FunctionName:    sdkaws.String(r.PathValue("FunctionName")),
+		Name:            sdkaws.String(r.PathValue("Name")),
+		FunctionVersion: req.FunctionVersion,
+		Description:     req.Description,
+		RevisionId:      req.RevisionId,
+		RoutingConfig:   fromRoutingConfig(req.RoutingConfig),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.save(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, toAliasConfiguration(types.AliasConfiguration{
+		AliasArn:        out.AliasArn,
+		Description:     out.Description,
+		FunctionVersion: out.FunctionVersion,
+		Name:            out.Name,
+		RevisionId:      out.RevisionId,
+		RoutingConfig:   out.RoutingConfig,
+	}))
+}
+
+func (s *Server) tagResource(w http.ResponseWriter, r *http.Request) {
+	var req tagResourceRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	_, err := s.store.TagResource(r.Context(), &lambda.TagResourceInput{
+		Resource: sdkaws.String(r.PathValue("ARN")),
+		Tags:     req.Tags,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.save(w) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) untagResource(w http.ResponseWriter, r *http.Request) {
+	_, err := s.store.UntagResource(r.Context(), &lambda.UntagResourceInput{
+		Resource: sdkaws.String(r.PathValue("ARN")),
+		TagKeys:  r.URL.Query()["tagKeys"],
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.save(w) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deploy 模拟 sam deploy：发布新版本并移动 latest 别名
+func (s *Server) deploy(w http.ResponseWriter, r *http.Request) {
+	version, err := s.store.Deploy(r.PathValue("FunctionName"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !s.save(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, deployResponse{Version: version})
+}
+
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request) {
+	writeErrorCode(w, http.StatusNotFound, "UnknownOperationException", "Unknown operation "+r.Method+" "+r.URL.Path)
+}
+
+// save 在写操作后持久化状态，失败时写入错误响应
+func (s *Server) save(w http.ResponseWriter) bool {
+	if s.persist == nil {
+		return true
+	}
+	if err := s.persist(); err != nil {
+		writeErrorCode(w, http.StatusInternalServerError, "ServiceException", "failed to persist state: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// =============================================================================
+// Helpers
+// =============================================================================
+
+// errorStatus 将 Lambda 错误码映射为 HTTP 状态码
+var errorStatus = map[string]int{
+	"InvalidParameterValueException":     http.StatusBadRequest,
+	"InvalidRequestContentException":     http.StatusBadRequest,
+	"ResourceNotFoundException":          http.StatusNotFound,
+	"ResourceConflictException":          http.StatusConflict,
+	"PreconditionFailedException":        http.StatusPreconditionFailed,
+	"TooManyRequestsException":           http.StatusTooManyRequests,
+	"CodeStorageExceededException":       http.StatusBadRequest,
+	"ResourceInUseException":             http.StatusBadRequest,
+	"ServiceException":                   http.StatusInternalServerError,
+	"UnsupportedMediaTypeException":      http.StatusUnsupportedMediaType,
+	"RequestTooLargeException":           http.StatusRequestEntityTooLarge,
+	"ResourceNotReadyException":          http.StatusBadGateway,
+	"InvalidCodeSignatureException":      http.StatusBadRequest,
+	"CodeVerificationFailedException":    http.StatusBadRequest,
+	"CodeSigningConfigNotFoundException": http.StatusNotFound,
+}
+
+// writeError 按 Lambda REST API 的格式写入错误响应
+func writeError(w http.ResponseWriter, err error) {
+	code, message := "ServiceException", err.Error()
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code, message = apiErr.ErrorCode(), apiErr.ErrorMessage()
+	}
+
+	status, ok := errorStatus[code]
+	if !ok {
+		status = http.StatusBadRequest
+	}
+	writeErrorCode(w, status, code, message)
+}
+
+// writeErrorCode 写入指定错误码的错误响应
+// 错误码同时写入 X-Amzn-ErrorType 头和响应体，与 Lambda 一致
+func writeErrorCode(w http.ResponseWriter, status int, code, message string) {
+	errorType := "User"
+	if status >= http.StatusInternalServerError {
+		errorType = "Service"
+	}
+	w.Header().Set("X-Amzn-ErrorType", code)
+	writeJSON(w, status, map[string]string{
+		"Type":    errorType,
+		"Message": message,
+	})
+}
+
+// writeJSON 写入 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// readJSON 解析请求体，失败时写入错误响应
+// 空请求体视为空对象
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.ContentLength == 0 {
+		return true
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeErrorCode(w, http.StatusBadRequest, "InvalidRequestContentException", "Could not parse request body into json: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// queryInt32 解析整数查询参数，失败时写入错误响应
+func queryInt32(w http.ResponseWriter, r *http.Request, name string) (*int32, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		writeErrorCode(w, http.StatusBadRequest, "InvalidParameterValueException", "Invalid "+name+": "+value)
+		return nil, false
+	}
+	return sdkaws.Int32(int32(n)), true
+}
+
+// fromRoutingConfig 将请求中的路由配置转换为 SDK 类型
+func fromRoutingConfig(rc *routingConfig) *types.AliasRoutingConfiguration {
+	if rc == nil {
+		return nil
+	}
+	weights := rc.AdditionalVersionWeights
+	if weights == nil {
+		weights = map[string]float64{}
+	}
+	return &types.AliasRoutingConfiguration{AdditionalVersionWeights: weights}
+}
+
+// toAliasConfiguration 将 SDK 别名配置转换为响应格式
+// 没有附加版本时省略 RoutingConfig，与 Lambda 一致
+func toAliasConfiguration(alias types.AliasConfiguration) aliasConfiguration {
+	result := aliasConfiguration{
+		AliasArn:        sdkaws.ToString(alias.AliasArn),
+		Description:     sdkaws.ToString(alias.Description),
+		FunctionVersion: sdkaws.ToString(alias.FunctionVersion),
+		Name:            sdkaws.ToString(alias.Name),
+		RevisionId:      sdkaws.ToString(alias.RevisionId),
+	}
+	if alias.RoutingConfig != nil && len(alias.RoutingConfig.AdditionalVersionWeights) > 0 {
+		result.RoutingConfig = &routingConfig{AdditionalVersionWeights: alias.RoutingConfig.AdditionalVersionWeights}
+	}
+	return result
+}
+
+// toFunctionConfiguration 将 SDK 函数配置转换为响应格式
+func toFunctionConfiguration(config types.FunctionConfiguration) functionConfiguration {
+	result := functionConfiguration{
+		FunctionName:           sdkaws.ToString(config.FunctionName),
+		FunctionArn:            sdkaws.ToString(config.FunctionArn),
+		Version:                sdkaws.ToString(config.Version),
+		Description:            sdkaws.ToString(config.Description),
+		CodeSha256:             sdkaws.ToString(config.CodeSha256),
+		CodeSize:               config.CodeSize,
+		LastModified:           sdkaws.ToString(config.LastModified),
+		Runtime:                string(config.Runtime),
+		PackageType:            string(config.PackageType),
+		State:                  string(config.State),
+		StateReason:            sdkaws.ToString(config.StateReason),
+		LastUpdateStatus:       string(config.LastUpdateStatus),
+		LastUpdateStatusReason: sdkaws.ToString(config.LastUpdateStatusReason),
+		RevisionId:             sdkaws.ToString(config.RevisionId),
+	}
+	for _, arch := range config.Architectures {
+		result.Architectures = append(result.Architectures, string(arch))
+	}
+	return result
+}
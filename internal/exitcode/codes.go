@@ -2,9 +2,17 @@
 package exitcode
 
 const (
-	Success          = 0 // 成功
-	ParamError       = 1 // 参数错误
-	AWSError         = 2 // AWS 错误
-	ResourceNotFound = 3 // 资源不存在
-	NetworkError     = 4 // 网络错误
+	Success           = 0  // 成功
+	ParamError        = 1  // 参数错误
+	AWSError          = 2  // AWS 错误
+	ResourceNotFound  = 3  // 资源不存在
+	NetworkError      = 4  // 网络错误
+	Throttled         = 5  // 请求被限流
+	Forbidden         = 6  // 权限不足或凭证无效
+	Conflict          = 7  // 资源冲突（例如并发修改）
+	StateChanged      = 8  // 别名在读取后被其他操作修改
+	VersionNotReady   = 9  // 版本状态为 Failed 或等待就绪超时
+	Locked            = 10 // 部署锁被其他操作持有
+	HealthCheckFailed = 11 // 灰度期间告警或指标检查失败，灰度已取消
+	Interrupted       = 12 // 收到 SIGINT 或 SIGTERM，命令被中断
 )
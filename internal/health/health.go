@@ -0,0 +1,228 @@
+// Package health 在灰度期间检查 CloudWatch 告警和指标阈值，用于发现有问题的新版本
+package health
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+)
+
+// Namespace 是指标阈值查询的命名空间
+const Namespace = "AWS/Lambda"
+
+// MaxAlarms 是一次健康检查最多查询的告警数
+const MaxAlarms = 100
+
+// 支持的统计方式，另外支持百分位数 p0-p100（例如 p99、p99.9）
+var (
+	stats          = []string{"Sum", "Average", "Minimum", "Maximum", "SampleCount"}
+	percentileStat = regexp.MustCompile(`^p(100|[0-9]{1,2}(\.[0-9]+)?)$`)
+	metricName     = regexp.MustCompile(`^[A-Za-z0-9_.\-/#]+$`)
+)
+
+// 比较运算符，较长的在前以便优先匹配
+var operators = []string{">=", "<=", ">", "<"}
+
+// Threshold 是指标阈值，统计值满足比较条件时视为失败
+// 例如 Errors:Sum>0 表示新版本在统计周期内出现错误即失败
+type Threshold struct {
+	Metric string  // 指标名称，例如 Errors、Duration
+	Stat   string  // 统计方式，默认 Sum
+	Op     string  // 比较运算符: >、>=、<、<=
+	Value  float64 // 阈值
+}
+
+// ParseThreshold 解析指标阈值
+// 格式: 指标名[:统计方式]运算符阈值，例如 Errors>0、Duration:p99>=1000、Invocations:Sum<1
+func ParseThreshold(spec string) (*Threshold, error) {
+	trimmed := strings.TrimSpace(spec)
+	pos, op := -1, ""
+	for _, candidate := range operators {
+		if i := strings.Index(trimmed, candidate); i >= 0 && (pos < 0 || i < pos) {
+			pos, op = i, candidate
+		}
+	}
+	if pos < 0 {
+		return nil, fmt.Errorf("无效的指标阈值 '%s'，格式为 指标名[:统计方式]运算符阈值 (例如 Errors>0)", spec)
+	}
+
+	t := &Threshold{Metric: strings.TrimSpace(trimmed[:pos]), Stat: "Sum", Op: op}
+	if name, stat, ok := strings.Cut(t.Metric, ":"); ok {
+		t.Metric, t.Stat = strings.TrimSpace(name), strings.TrimSpace(stat)
+	}
+	if !metricName.MatchString(t.Metric) {
+		return nil, fmt.Errorf("无效的指标阈值 '%s': 指标名 '%s' 无效", spec, t.Metric)
+	}
+	if !validStat(t.Stat) {
+		return nil, fmt.Errorf("无效的指标阈值 '%s': 统计方式 '%s' 无效，有效值为 %s 或 p0-p100", spec, t.Stat, strings.Join(stats, ", "))
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(trimmed[pos+len(op):]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("无效的指标阈值 '%s': 阈值不是数字", spec)
+	}
+	t.Value = value
+	return t, nil
+}
+
+// validStat 判断统计方式是否有效
+func validStat(stat string) bool {
+	for _, s := range stats {
+		if stat == s {
+			return true
+		}
+	}
+	return percentileStat.MatchString(stat)
+}
+
+// Breached 判断统计值是否满足失败条件
+func (t *Threshold) Breached(value float64) bool {
+	switch t.Op {
+	case ">":
+		return value > t.Value
+	case ">=":
+		return value >= t.Value
+	case "<":
+		return value < t.Value
+	case "<=":
+		return value <= t.Value
+	}
+	return false
+}
+
+// String 返回阈值的描述，例如 Errors:Sum>0
+func (t *Threshold) String() string {
+	return fmt.Sprintf("%s:%s%s%s", t.Metric, t.Stat, t.Op, strconv.FormatFloat(t.Value, 'f', -1, 64))
+}
+
+// ValidatePeriod 检查指标的统计周期，必须是 1 分钟的整数倍
+func ValidatePeriod(period time.Duration) error {
+	if period < time.Minute || period%time.Minute != 0 {
+		return fmt.Errorf("无效的统计周期 '%v'，必须是 1 分钟的整数倍", period)
+	}
+	return nil
+}
+
+// Target 是被检查的灰度版本
+type Target struct {
+	Function string // 函数名
+	Alias    string // 承载灰度流量的别名，通常是 live
+	Version  string // 灰度版本
+}
+
+// dimensions 返回灰度版本的指标维度
+// Lambda 为带 ExecutedVersion 的别名调用上报 FunctionName、Resource 和 ExecutedVersion 维度
+func (t Target) dimensions() map[string]string {
+	return map[string]string{
+		"FunctionName":    t.Function,
+		"Resource":        t.Function + ":" + t.Alias,
+		"ExecutedVersion": t.Version,
+	}
+}
+
+// Breach 描述一次健康检查失败
+type Breach struct {
+	Source string // 失败的告警或指标阈值，例如 "告警 api-errors"
+	Detail string // 失败原因，例如告警的状态原因或指标的统计值
+}
+
+// String 返回失败的描述
+func (b *Breach) String() string {
+	if b.Detail == "" {
+		return b.Source
+	}
+	return fmt.Sprintf("%s (%s)", b.Source, b.Detail)
+}
+
+// Gate 检查告警和指标阈值
+type Gate struct {
+	api        aws.MetricsAPI
+	alarms     []string
+	thresholds []*Threshold
+	period     time.Duration
+	now        func() time.Time
+}
+
+// NewGate 创建健康检查，period 是指标阈值的统计周期
+func NewGate(api aws.MetricsAPI, alarms []string, thresholds []*Threshold, period time.Duration) *Gate {
+	return &Gate{api: api, alarms: alarms, thresholds: thresholds, period: period, now: time.Now}
+}
+
+// Preflight 开始灰度前检查告警: 告警不存在时返回 ResourceNotFound 错误，已处于 ALARM 状态时返回 Breach
+// 此时新版本还没有流量，不检查指标阈值
+func (g *Gate) Preflight(ctx context.Context) (*Breach, error) {
+	if len(g.alarms) == 0 {
+		return nil, nil
+	}
+	states, err := g.api.DescribeAlarms(ctx, g.alarms)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool)
+	for _, s := range states {
+		found[s.Name] = true
+	}
+	var missing []string
+	for _, name := range g.alarms {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &aws.Error{Op: "DescribeAlarms", Code: exitcode.ResourceNotFound, Err: fmt.Errorf("告警不存在: %s", strings.Join(missing, ", "))}
+	}
+	return alarmBreach(states), nil
+}
+
+// Check 检查告警和灰度版本的指标阈值
+// 任意告警处于 ALARM 状态或指标满足失败条件时返回 Breach；没有数据的指标视为正常
+func (g *Gate) Check(ctx context.Context, target Target) (*Breach, error) {
+	if len(g.alarms) > 0 {
+		states, err := g.api.DescribeAlarms(ctx, g.alarms)
+		if err != nil {
+			return nil, err
+		}
+		if breach := alarmBreach(states); breach != nil {
+			return breach, nil
+		}
+	}
+
+	end := g.now()
+	for _, t := range g.thresholds {
+		values, err := g.api.GetMetricValues(ctx, aws.MetricQuery{
+			Namespace:  Namespace,
+			MetricName: t.Metric,
+			Dimensions: target.dimensions(),
+			Stat:       t.Stat,
+			Period:     g.period,
+			Start:      end.Add(-g.period),
+			End:        end,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			if t.Breached(v) {
+				detail := fmt.Sprintf("版本 %s 的 %s 为 %s", target.Version, t.Stat, strconv.FormatFloat(v, 'f', -1, 64))
+				return &Breach{Source: "指标 " + t.String(), Detail: detail}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// alarmBreach 返回第一个处于 ALARM 状态的告警，没有时返回 nil
+func alarmBreach(states []aws.AlarmState) *Breach {
+	for _, s := range states {
+		if s.State == aws.AlarmStateAlarm {
+			return &Breach{Source: "告警 " + s.Name, Detail: s.Reason}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileStore 将部署锁保存在本地 JSON 文件中，适用于所有操作都在同一台主机上执行的 CI
+// FileStore 实现了 Creator: 通过硬链接创建锁文件，已有锁文件时创建失败
+type FileStore struct {
+	path string
+}
+
+// NewFileStore 创建保存在 path 的文件存储
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load 实现 Store
+func (s *FileStore) Load(ctx context.Context) (*Lock, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取锁文件失败: %w", err)
+	}
+
+	var lock Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("解析锁文件 %s 失败: %w", s.path, err)
+	}
+	return &lock, nil
+}
+
+// Save 实现 Store
+// 先写入临时文件再重命名，避免其他进程读到写了一半的文件
+func (s *FileStore) Save(ctx context.Context, lock *Lock) error {
+	tmp, err := s.writeTemp(lock)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("写入锁文件失败: %w", err)
+	}
+	return nil
+}
+
+// Create 实现 Creator
+// 先写入临时文件再硬链接到锁文件，链接在锁文件已存在时失败，因此创建是原子的且不会读到写了一半的文件
+func (s *FileStore) Create(ctx context.Context, lock *Lock) error {
+	tmp, err := s.writeTemp(lock)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err := os.Link(tmp, s.path); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return ErrExists
+		}
+		return fmt.Errorf("创建锁文件失败: %w", err)
+	}
+	return nil
+}
+
+// RemoveExpired 实现 Creator
+// 先把锁文件重命名为临时名称，重命名是原子的，只有一个进程能移走同一个锁文件；
+// 移走的锁未到期（读取之后被其他进程替换）时将其恢复
+func (s *FileStore) RemoveExpired(ctx context.Context, now time.Time) error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	removed := s.path + "." + token + ".expired"
+	if err := os.Rename(s.path, removed); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("删除已到期的锁文件失败: %w", err)
+	}
+	defer os.Remove(removed)
+
+	lock, err := (&FileStore{path: removed}).Load(ctx)
+	if err == nil && lock != nil && !lock.Expired(now) {
+		// 已有其他锁文件时说明其他进程已经获取了锁，不覆盖
+		if err := os.Link(removed, s.path); err != nil && !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("恢复锁文件失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeTemp 将锁写入与锁文件同目录的临时文件，返回临时文件路径
+func (s *FileStore) writeTemp(lock *Lock) (string, error) {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return "", fmt.Errorf("创建锁文件目录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return "", fmt.Errorf("写入锁文件失败: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("写入锁文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("写入锁文件失败: %w", err)
+	}
+	return tmp.Name(), nil
+}
+
+// Delete 实现 Store
+func (s *FileStore) Delete(ctx context.Context) error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("删除锁文件失败: %w", err)
+	}
+	return nil
+}
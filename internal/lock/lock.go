@@ -0,0 +1,260 @@
+// Package lock provides a lease-based deployment lock that stops concurrent
+// operators from modifying the aliases of the same function.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Lock 是部署锁的内容
+type Lock struct {
+	Holder    string    `json:"holder"`     // 持有者，例如 user@host
+	Command   string    `json:"command"`    // 持有锁的命令，例如 auto
+	Env       string    `json:"env"`        // 环境
+	Token     string    `json:"token"`      // 每次获取锁时生成的随机标识，用于确认锁仍由自己持有
+	StartedAt time.Time `json:"started_at"` // 获取锁的时间
+	ExpiresAt time.Time `json:"expires_at"` // 租约到期时间，到期后其他操作可以获取锁
+}
+
+// Expired 判断租约是否已到期
+func (l *Lock) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// String 返回锁的可读描述
+func (l *Lock) String() string {
+	return fmt.Sprintf("%s (命令: %s, 环境: %s, 开始: %s, 到期: %s)",
+		l.Holder, l.Command, l.Env, l.StartedAt.Format(time.RFC3339), l.ExpiresAt.Format(time.RFC3339))
+}
+
+// Store 是部署锁的存储
+// 实现了 Creator 的存储由 Acquire 原子地创建锁；其他存储写入后等待 settleDelay 再回读确认锁由自己持有
+type Store interface {
+	// Load 读取当前的锁，没有锁时返回 nil, nil
+	Load(ctx context.Context) (*Lock, error)
+	// Save 写入锁，覆盖已有的锁
+	Save(ctx context.Context, lock *Lock) error
+	// Delete 删除锁，没有锁时不返回错误
+	Delete(ctx context.Context) error
+}
+
+// Creator 是可以原子地创建锁的存储，例如 FileStore
+type Creator interface {
+	// Create 在没有锁时写入锁，已有锁（包括已到期的锁）时返回 ErrExists
+	Create(ctx context.Context, lock *Lock) error
+	// RemoveExpired 在当前的锁已到期时删除它，锁未到期或不存在时不做修改
+	RemoveExpired(ctx context.Context, now time.Time) error
+}
+
+// ErrLost 表示租约在持有期间被解除
+var ErrLost = errors.New("部署锁已被解除")
+
+// ErrExists 表示 Creator.Create 时已有锁
+var ErrExists = errors.New("部署锁已存在")
+
+// maxCreateAttempts 是通过 Creator 获取锁的最大尝试次数，每次失败后删除已到期的锁再重试
+const maxCreateAttempts = 3
+
+// DefaultSettleDelay 是不支持原子创建的存储写入锁后等待的时间
+// 同时获取锁的操作者都在读到没有锁之后写入，等待后回读时只有最后写入的一方看到自己的锁
+const DefaultSettleDelay = 2 * time.Second
+
+// settleDelay 是写入锁后回读前等待的时间，可在测试中覆盖
+var settleDelay = DefaultSettleDelay
+
+// SetSettleDelay 设置写入锁后回读前等待的时间（用于测试），传入负数时恢复为 DefaultSettleDelay
+func SetSettleDelay(d time.Duration) {
+	if d < 0 {
+		d = DefaultSettleDelay
+	}
+	settleDelay = d
+}
+
+// HeldError 表示锁被其他操作持有
+type HeldError struct {
+	Lock *Lock
+}
+
+func (e *HeldError) Error() string {
+	return "部署锁被其他操作持有: " + e.Lock.String()
+}
+
+// now 返回当前时间（用于测试）
+var now = time.Now
+
+// SetClock 设置获取当前时间的函数（用于测试），传入 nil 时恢复为 time.Now
+func SetClock(f func() time.Time) {
+	if f == nil {
+		f = time.Now
+	}
+	now = f
+}
+
+// Lease 是已获取的部署锁租约
+type Lease struct {
+	store Store
+	lock  Lock
+}
+
+// Acquire 获取部署锁
+// 已有未到期的锁时返回 *HeldError；已到期的锁会被覆盖
+// 多个操作者同时获取时只有一个成功: Creator 原子地创建锁；其他存储写入后等待 settleDelay 再回读，
+// 只有最后写入的一方读到自己的锁
+func Acquire(ctx context.Context, store Store, holder, command, env string, ttl time.Duration) (*Lease, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	started := now().UTC().Truncate(time.Second)
+	lease := &Lease{store: store, lock: Lock{
+		Holder:    holder,
+		Command:   command,
+		Env:       env,
+		Token:     token,
+		StartedAt: started,
+		ExpiresAt: started.Add(ttl),
+	}}
+
+	if creator, ok := store.(Creator); ok {
+		return lease, lease.create(ctx, creator)
+	}
+
+	existing, err := store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !existing.Expired(now()) {
+		return nil, &HeldError{Lock: existing}
+	}
+	if err := store.Save(ctx, &lease.lock); err != nil {
+		return nil, err
+	}
+
+	// 存储不能原子地创建锁，同时读到没有锁的操作者会先后写入
+	// 等待其他操作者的写入完成后回读，被覆盖的一方返回 *HeldError
+	if err := lease.verify(ctx); err != nil {
+		return nil, err
+	}
+	if err := sleep(ctx, settleDelay); err != nil {
+		return nil, err
+	}
+	if err := lease.verify(ctx); err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// create 通过 Creator 原子地创建锁，已有的锁到期时删除后重试
+func (l *Lease) create(ctx context.Context, creator Creator) error {
+	for attempt := 1; ; attempt++ {
+		err := creator.Create(ctx, &l.lock)
+		if !errors.Is(err, ErrExists) {
+			return err
+		}
+
+		existing, err := l.store.Load(ctx)
+		if err != nil {
+			return err
+		}
+		if existing != nil && !existing.Expired(now()) {
+			return &HeldError{Lock: existing}
+		}
+		if attempt >= maxCreateAttempts {
+			return fmt.Errorf("获取部署锁失败: 锁在尝试 %d 次期间一直被其他操作修改", attempt)
+		}
+		if existing != nil {
+			if err := creator.RemoveExpired(ctx, now()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sleep 等待 d，ctx 被取消时提前返回 ctx 的错误
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Lock 返回租约当前的锁内容
+func (l *Lease) Lock() Lock {
+	return l.lock
+}
+
+// Renew 延长租约，到期时间为当前时间加上 ttl
+// 锁已被解除时返回 ErrLost，已被其他操作获取时返回 *HeldError
+func (l *Lease) Renew(ctx context.Context, ttl time.Duration) error {
+	if err := l.verify(ctx); err != nil {
+		return err
+	}
+	renewed := l.lock
+	renewed.ExpiresAt = now().UTC().Truncate(time.Second).Add(ttl)
+	if err := l.store.Save(ctx, &renewed); err != nil {
+		return err
+	}
+	l.lock = renewed
+	return nil
+}
+
+// Release 释放租约，锁已不由自己持有时不做任何修改
+func (l *Lease) Release(ctx context.Context) error {
+	current, err := l.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.Token != l.lock.Token {
+		return nil
+	}
+	return l.store.Delete(ctx)
+}
+
+// verify 确认锁仍由自己持有
+func (l *Lease) verify(ctx context.Context) error {
+	current, err := l.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return ErrLost
+	}
+	if current.Token != l.lock.Token {
+		return &HeldError{Lock: current}
+	}
+	return nil
+}
+
+// Break 强制删除当前的锁，用于持有者异常退出且不想等待租约到期的情况
+// 返回: 被删除的锁（没有锁时为 nil）, 错误
+func Break(ctx context.Context, store Store) (*Lock, error) {
+	current, err := store.Load(ctx)
+	if err != nil || current == nil {
+		return nil, err
+	}
+	if err := store.Delete(ctx); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// newToken 生成随机的锁标识
+func newToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成锁标识失败: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
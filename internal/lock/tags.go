@@ -0,0 +1,98 @@
+package lock
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// tagPrefix 是部署锁使用的函数标签前缀
+const tagPrefix = "lad:lock:"
+
+// 部署锁的各字段分别保存在一个标签中
+const (
+	tagHolder    = tagPrefix + "holder"
+	tagCommand   = tagPrefix + "command"
+	tagEnv       = tagPrefix + "env"
+	tagToken     = tagPrefix + "token"
+	tagStartedAt = tagPrefix + "started-at"
+	tagExpiresAt = tagPrefix + "expires-at"
+)
+
+// tagKeys 是部署锁使用的全部标签
+var tagKeys = []string{tagHolder, tagCommand, tagEnv, tagToken, tagStartedAt, tagExpiresAt}
+
+// TagClient 是 TagStore 依赖的函数标签操作，*aws.Client 实现了该接口
+type TagClient interface {
+	FunctionTags(ctx context.Context, functionName string) (map[string]string, error)
+	TagFunction(ctx context.Context, functionName string, tags map[string]string) error
+	UntagFunction(ctx context.Context, functionName string, keys []string) error
+}
+
+// TagStore 将部署锁保存在 Lambda 函数的标签中，所有能访问该函数的操作者共享同一把锁
+type TagStore struct {
+	client       TagClient
+	functionName string
+}
+
+// NewTagStore 创建保存在函数标签中的存储
+func NewTagStore(client TagClient, functionName string) *TagStore {
+	return &TagStore{client: client, functionName: functionName}
+}
+
+// Load 实现 Store
+func (s *TagStore) Load(ctx context.Context) (*Lock, error) {
+	tags, err := s.client.FunctionTags(ctx, s.functionName)
+	if err != nil {
+		return nil, err
+	}
+	token, ok := tags[tagToken]
+	if !ok {
+		return nil, nil
+	}
+
+	// 无法解析的时间视为已到期，避免损坏的标签永久占用锁
+	started, _ := time.Parse(time.RFC3339, tags[tagStartedAt])
+	expires, _ := time.Parse(time.RFC3339, tags[tagExpiresAt])
+	return &Lock{
+		Holder:    tags[tagHolder],
+		Command:   tags[tagCommand],
+		Env:       tags[tagEnv],
+		Token:     token,
+		StartedAt: started,
+		ExpiresAt: expires,
+	}, nil
+}
+
+// Save 实现 Store
+func (s *TagStore) Save(ctx context.Context, lock *Lock) error {
+	return s.client.TagFunction(ctx, s.functionName, map[string]string{
+		tagHolder:    tagValue(lock.Holder),
+		tagCommand:   tagValue(lock.Command),
+		tagEnv:       tagValue(lock.Env),
+		tagToken:     lock.Token,
+		tagStartedAt: lock.StartedAt.UTC().Format(time.RFC3339),
+		tagExpiresAt: lock.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// Delete 实现 Store
+func (s *TagStore) Delete(ctx context.Context) error {
+	return s.client.UntagFunction(ctx, s.functionName, tagKeys)
+}
+
+// tagValue 将值转换为标签允许的字符并截断到 256 个字符
+// 标签值只能包含字母、数字、空格和 _ . : / = + - @
+func tagValue(value string) string {
+	runes := []rune(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.In(r, unicode.Z) || strings.ContainsRune("_.:/=+-@", r) {
+			return r
+		}
+		return '_'
+	}, value))
+	if len(runes) > 256 {
+		runes = runes[:256]
+	}
+	return string(runes)
+}
@@ -0,0 +1,83 @@
+// Package patcher provides template patching utilities for the lad command line tool.
+package patcher
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// resourcePattern 匹配 Resources 下的顶层资源名称
+	resourcePattern = regexp.MustCompile(`(?m)^  ([A-Za-z0-9]+):\s*$`)
+	// aliasNamePattern 匹配别名资源的 Name 属性
+	aliasNamePattern = regexp.MustCompile(`(?m)^\s+Name:\s*(.*)$`)
+	// functionVersionPattern 匹配别名资源的 FunctionVersion 属性，值可能在下一行（Fn::GetAtt 列表形式）
+	functionVersionPattern = regexp.MustCompile(`(?m)^\s+FunctionVersion:[ \t]*(.*)$`)
+)
+
+// AliasTarget 模板中声明的别名资源
+type AliasTarget struct {
+	Resource        string // 资源名称，例如 LiveAlias
+	Name            string // 别名名称，例如 live
+	FunctionVersion string // FunctionVersion 属性的原始值，例如 !GetAtt FunctionVersion.Version
+}
+
+// FollowsNewVersion 判断别名是否引用 Version 资源的版本号
+// 这类别名在每次 sam deploy 发布新版本时都会被 CloudFormation 移动到新版本
+func (a AliasTarget) FollowsNewVersion() bool {
+	return strings.Contains(a.FunctionVersion, "GetAtt") && strings.Contains(a.FunctionVersion, "Version")
+}
+
+// ParseAliasTargets 解析模板中 AWS::Lambda::Alias 资源的名称和 FunctionVersion
+// 只支持 lad patch 生成的缩进格式（资源缩进 2 个空格）
+func ParseAliasTargets(content string) []AliasTarget {
+	var targets []AliasTarget
+
+	matches := resourcePattern.FindAllStringSubmatchIndex(content, -1)
+	for i, match := range matches {
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		block := content[match[1]:end]
+		if !strings.Contains(block, "Type: AWS::Lambda::Alias") {
+			continue
+		}
+
+		target := AliasTarget{Resource: content[match[2]:match[3]]}
+		if m := aliasNamePattern.FindStringSubmatch(block); m != nil {
+			target.Name = trimYAMLValue(m[1])
+		}
+		if loc := functionVersionPattern.FindStringSubmatchIndex(block); loc != nil {
+			value := trimYAMLValue(block[loc[2]:loc[3]])
+			if value == "" {
+				// Fn::GetAtt 写在下一层时合并后续缩进行
+				var parts []string
+				for _, line := range strings.Split(block[loc[1]:], "\n") {
+					line = strings.TrimSpace(line)
+					if line == "" {
+						continue
+					}
+					if !strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "Fn::") {
+						break
+					}
+					parts = append(parts, line)
+				}
+				value = strings.Join(parts, " ")
+			}
+			target.FunctionVersion = value
+		}
+		targets = append(targets, target)
+	}
+
+	return targets
+}
+
+// trimYAMLValue 去除 YAML 值两端的空白、注释和引号
+func trimYAMLValue(value string) string {
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.TrimSpace(value)
+	return strings.Trim(value, `"'`)
+}
@@ -0,0 +1,130 @@
+// Package releases 维护每个函数晋升到 live 的版本历史，用于多级回退
+package releases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// TagKey 是保存发布历史的函数标签，值为以空格分隔的版本号，最近的在前
+const TagKey = "lad:releases"
+
+// MaxVersions 是发布历史最多保存的版本数
+const MaxVersions = 20
+
+// maxTagValue 是标签值的最大长度
+const maxTagValue = 256
+
+// TagClient 是读写发布历史依赖的函数标签操作，*aws.Client 实现了该接口
+type TagClient interface {
+	FunctionTags(ctx context.Context, functionName string) (map[string]string, error)
+	TagFunction(ctx context.Context, functionName string, tags map[string]string) error
+}
+
+// History 是依次晋升到 live 的版本，最近的在前
+type History []string
+
+// Parse 解析标签值
+func Parse(value string) History {
+	return History(strings.Fields(value))
+}
+
+// String 返回标签值
+func (h History) String() string {
+	return strings.Join(h, " ")
+}
+
+// Push 返回 version 晋升到 live 后的历史
+// 历史中已有的相同版本会被移除，同一版本在回退链中只出现一次
+func (h History) Push(version string) History {
+	next := History{version}
+	for _, v := range h {
+		if v != version {
+			next = append(next, v)
+		}
+	}
+	return next.truncate()
+}
+
+// truncate 保留最近的 MaxVersions 个版本，并保证标签值不超过长度限制
+func (h History) truncate() History {
+	if len(h) > MaxVersions {
+		h = h[:MaxVersions]
+	}
+	for len(h) > 1 && len(h.String()) > maxTagValue {
+		h = h[:len(h)-1]
+	}
+	return h
+}
+
+// Chain 返回从 live 开始的回退链: live、上一次晋升到 live 的版本、再上一次……
+// live 在历史中时从它的位置开始；不在历史中时（例如在 lad 之外修改了 live）放在最前
+// 回退链中只有 live 时使用 previous 别名作为上一个版本，兼容还没有发布历史的函数
+func (h History) Chain(live, previous string) History {
+	var chain History
+	if i := h.Index(live); i >= 0 {
+		chain = append(chain, h[i:]...)
+	} else {
+		chain = append(History{live}, h...)
+	}
+	if len(chain) == 1 && previous != "" && previous != live {
+		chain = append(chain, previous)
+	}
+	return chain
+}
+
+// Step 返回回退链中 live 之前第 steps 个版本
+func (h History) Step(steps int) (string, error) {
+	if steps < 1 {
+		return "", fmt.Errorf("无效的回退步数 '%d'，最小为 1", steps)
+	}
+	if steps >= len(h) {
+		return "", fmt.Errorf("发布历史中只有 %d 个更早的版本，无法回退 %d 步", len(h)-1, steps)
+	}
+	return h[steps], nil
+}
+
+// Index 返回版本在回退链中的位置，不存在时返回 -1
+func (h History) Index(version string) int {
+	for i, v := range h {
+		if v == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// RollbackTo 返回回退链回退到 version 后的历史: 比 version 新的版本被移除
+// version 不在回退链中时返回只包含 version 的历史
+func (h History) RollbackTo(version string) History {
+	if i := h.Index(version); i >= 0 {
+		return append(History{}, h[i:]...)
+	}
+	return History{version}
+}
+
+// Previous 返回回退后 previous 别名应指向的版本: 历史中的第二个版本，没有时为第一个版本
+func (h History) Previous() string {
+	if len(h) > 1 {
+		return h[1]
+	}
+	if len(h) == 1 {
+		return h[0]
+	}
+	return ""
+}
+
+// Load 读取函数的发布历史，没有记录时返回空历史
+func Load(ctx context.Context, client TagClient, functionName string) (History, error) {
+	tags, err := client.FunctionTags(ctx, functionName)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(tags[TagKey]), nil
+}
+
+// Save 保存函数的发布历史
+func Save(ctx context.Context, client TagClient, functionName string, h History) error {
+	return client.TagFunction(ctx, functionName, map[string]string{TagKey: h.truncate().String()})
+}
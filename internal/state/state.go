@@ -0,0 +1,113 @@
+// Package state computes the deployment state of a function from its aliases
+// and validates command transitions against the state transition table.
+package state
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aura-studio/lad/internal/aws"
+)
+
+// State 是由 live、previous、latest 别名推导出的部署状态
+type State string
+
+const (
+	Stable     State = "stable"      // 稳定态: live == latest，无灰度
+	Pending    State = "pending"     // 待验证态: live != latest，无灰度
+	Canary     State = "canary"      // 灰度态: live 配置了灰度路由
+	RolledBack State = "rolled-back" // 回退态: live == latest == previous，无灰度
+)
+
+// Label 返回状态的中文名称
+func (s State) Label() string {
+	switch s {
+	case Stable:
+		return "稳定态"
+	case Pending:
+		return "待验证态"
+	case Canary:
+		return "灰度态"
+	case RolledBack:
+		return "回退态"
+	}
+	return string(s)
+}
+
+// Snapshot 是某一时刻的别名状态及由此计算出的部署状态
+type Snapshot struct {
+	State    State
+	Live     *aws.AliasState
+	Previous *aws.AliasState // 可能为 nil，此时无法识别回退态
+	Latest   *aws.AliasState
+
+	// Anomalies 是不符合 lad 发布流程的别名状态，通常由 lad 之外的修改导致
+	Anomalies []string
+}
+
+// Compute 根据别名计算部署状态并检查异常
+// previous 为 nil 时不区分稳定态和回退态
+func Compute(live, previous, latest *aws.AliasState) *Snapshot {
+	s := &Snapshot{Live: live, Previous: previous, Latest: latest}
+
+	switch {
+	case live.HasRouting():
+		s.State = Canary
+	case live.Version != latest.Version:
+		s.State = Pending
+	case previous != nil && previous.Version == live.Version:
+		s.State = RolledBack
+	default:
+		s.State = Stable
+	}
+
+	s.Anomalies = anomalies(live, previous, latest)
+	return s
+}
+
+// anomalies 检查别名是否符合 lad 发布流程
+func anomalies(live, previous, latest *aws.AliasState) []string {
+	var result []string
+
+	if live.HasRouting() {
+		if canaryVersion, _, ok := live.Canary(); !ok {
+			result = append(result, fmt.Sprintf("live 别名配置了多个灰度版本 (%s)", live.RoutingSummary()))
+		} else if canaryVersion != latest.Version {
+			result = append(result, fmt.Sprintf("灰度版本 %s 不是 latest 版本 %s", canaryVersion, latest.Version))
+		}
+		if previous != nil && previous.Version == live.Version {
+			result = append(result, fmt.Sprintf("灰度进行中，但 live 与 previous 都指向版本 %s", live.Version))
+		}
+	}
+
+	// latest 应当不早于 live（只有回退会让 latest 退回到 live 的版本）
+	if versionLess(latest.Version, live.Version) {
+		result = append(result, fmt.Sprintf("latest 版本 %s 早于 live 版本 %s", latest.Version, live.Version))
+	}
+
+	// previous 和 latest 由 lad 维护，不应配置流量路由
+	for _, alias := range []*aws.AliasState{previous, latest} {
+		if alias != nil && alias.HasRouting() {
+			result = append(result, fmt.Sprintf("%s 别名配置了流量路由 (%s)", alias.Name, alias.RoutingSummary()))
+		}
+	}
+
+	return result
+}
+
+// versionLess 按数字比较版本号，无法比较时返回 false
+func versionLess(a, b string) bool {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	return errA == nil && errB == nil && na < nb
+}
+
+// UnexpectedRouting 判断 live 别名的流量路由是否不是 lad 配置的形式
+// lad 只会配置一个灰度版本且该版本为 latest，其他形式说明别名在 lad 之外被修改过
+func (s *Snapshot) UnexpectedRouting() bool {
+	if !s.Live.HasRouting() {
+		return false
+	}
+	canaryVersion, _, ok := s.Live.Canary()
+	return !ok || canaryVersion != s.Latest.Version
+}
@@ -0,0 +1,130 @@
+package state
+
+import "fmt"
+
+// Command 是会改变部署状态的操作
+type Command string
+
+const (
+	Deploy      Command = "deploy"                // sam deploy 或 lad publish 发布新版本
+	StartCanary Command = "canary"                // 配置灰度流量 (--percent 1-100)
+	ClearCanary Command = "canary --percent 0"    // 清除灰度配置
+	Auto        Command = "auto"                  // 自动递进灰度
+	Promote     Command = "promote"               // 完成发布
+	Rollback    Command = "rollback"              // 回退到 previous
+	RollbackTo  Command = "rollback --steps/--to" // 按发布历史回退到更早的版本
+	Abort       Command = "rollback (abort)"      // 取消灰度或待发布版本
+	Switch      Command = "switch"                // 紧急切换到指定版本
+)
+
+// Outcome 是命令在某个状态下的执行结果
+type Outcome int
+
+const (
+	Allowed Outcome = iota // 可以执行
+	NoOp                   // 没有需要执行的操作，命令直接返回成功
+	Blocked                // 阻止执行，命令以参数错误退出
+)
+
+// Rule 是状态转换表中的一项
+type Rule struct {
+	Outcome Outcome
+	Next    State  // Allowed 时执行后的状态，为空表示取决于命令参数
+	Reason  string // NoOp 或 Blocked 的原因
+}
+
+const (
+	reasonNoNewVersion = "live 和 latest 指向同一版本，没有新版本需要发布"
+	reasonNoCanary     = "没有活跃的灰度配置"
+)
+
+// transitions 是状态转换表，与 doc/state-transitions.md 一致
+var transitions = map[Command]map[State]Rule{
+	Deploy: {
+		Stable:     {Outcome: Allowed, Next: Pending},
+		Pending:    {Outcome: Blocked, Reason: "latest 版本尚未发布到 live，需先 promote 或 rollback"},
+		Canary:     {Outcome: Blocked, Reason: "live 别名存在活跃的灰度配置，需先 promote 或 rollback"},
+		RolledBack: {Outcome: Allowed, Next: Pending},
+	},
+	StartCanary: {
+		Stable:     {Outcome: NoOp, Reason: reasonNoNewVersion},
+		Pending:    {Outcome: Allowed, Next: Canary},
+		Canary:     {Outcome: Allowed, Next: Canary},
+		RolledBack: {Outcome: NoOp, Reason: reasonNoNewVersion},
+	},
+	ClearCanary: {
+		Stable:     {Outcome: NoOp, Reason: reasonNoCanary},
+		Pending:    {Outcome: NoOp, Reason: reasonNoCanary},
+		Canary:     {Outcome: Allowed, Next: Pending},
+		RolledBack: {Outcome: NoOp, Reason: reasonNoCanary},
+	},
+	Auto: {
+		Stable:     {Outcome: NoOp, Reason: reasonNoNewVersion},
+		Pending:    {Outcome: Allowed, Next: Stable},
+		Canary:     {Outcome: Allowed, Next: Stable},
+		RolledBack: {Outcome: NoOp, Reason: reasonNoNewVersion},
+	},
+	Promote: {
+		Stable:     {Outcome: NoOp, Reason: reasonNoNewVersion},
+		Pending:    {Outcome: Allowed, Next: Stable},
+		Canary:     {Outcome: Allowed, Next: Stable},
+		RolledBack: {Outcome: NoOp, Reason: reasonNoNewVersion},
+	},
+	// 回退后 previous 指向发布历史中更早的版本，没有更早的版本时与 live 相同（回退态）
+	Rollback: {
+		Stable:     {Outcome: Allowed},
+		Pending:    {Outcome: Allowed},
+		Canary:     {Outcome: Allowed},
+		RolledBack: {Outcome: Blocked, Reason: "live 和 previous 已指向同一版本，没有可回退的历史版本"},
+	},
+	RollbackTo: {
+		Stable:     {Outcome: Allowed},
+		Pending:    {Outcome: Allowed},
+		Canary:     {Outcome: Allowed},
+		RolledBack: {Outcome: Allowed},
+	},
+	Abort: {
+		Stable:     {Outcome: NoOp, Reason: "没有活跃的灰度配置或待发布版本"},
+		Pending:    {Outcome: Allowed, Next: Stable},
+		Canary:     {Outcome: Allowed, Next: Stable},
+		RolledBack: {Outcome: NoOp, Reason: "没有活跃的灰度配置或待发布版本"},
+	},
+	Switch: {
+		Stable:     {Outcome: Allowed},
+		Pending:    {Outcome: Allowed},
+		Canary:     {Outcome: Allowed},
+		RolledBack: {Outcome: Allowed},
+	},
+}
+
+// Check 返回命令在指定状态下的转换规则
+// 未知的命令或状态视为阻止执行
+func Check(command Command, from State) Rule {
+	if rule, ok := transitions[command][from]; ok {
+		return rule
+	}
+	return Rule{Outcome: Blocked, Reason: fmt.Sprintf("状态 %s 下不支持 %s", from.Label(), command)}
+}
+
+// Check 返回命令在当前部署状态下的转换规则
+// 在转换表的基础上考虑别名的具体版本：
+//   - live 与 latest 相同但仍有流量路由时，没有新版本可以灰度或发布，只能通过 rollback 取消
+//   - live 与 previous 相同时，即使处于灰度态也没有可回退的历史版本
+func (s *Snapshot) Check(command Command) Rule {
+	rule := Check(command, s.State)
+	if rule.Outcome != Allowed {
+		return rule
+	}
+
+	switch command {
+	case StartCanary, Auto, Promote:
+		if s.Live.Version == s.Latest.Version {
+			return Rule{Outcome: NoOp, Reason: reasonNoNewVersion}
+		}
+	case Rollback:
+		if s.Previous != nil && s.Previous.Version == s.Live.Version {
+			return transitions[Rollback][RolledBack]
+		}
+	}
+	return rule
+}
@@ -0,0 +1,95 @@
+package strategy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// progressPrefix 是 auto 进度在 live 别名描述中的标记前缀
+const progressPrefix = "lad auto: "
+
+// MaxDescription 是 Lambda 别名描述的最大长度
+const MaxDescription = 256
+
+// 控制请求，由 lad auto pause|resume|abort 写入进度，运行中的 auto 轮询读取
+const (
+	// ControlPause 请求暂停: 保持当前灰度比例，暂停期间不计入等待时间
+	ControlPause = "pause"
+	// ControlAbort 请求取消灰度，与 lad rollback 取消灰度相同
+	ControlAbort = "abort"
+)
+
+// Progress 是 auto 的执行进度，保存在 live 别名的描述中，用于 auto --resume
+// 格式: lad auto: strategy=<策略> step=<当前步骤>/<步骤数> version=<灰度版本> started=<RFC3339> [control=<控制请求>] operator=<操作人>
+type Progress struct {
+	Strategy string    // 策略，可由 Parse 解析
+	Step     int       // 当前步骤，从 1 开始
+	Steps    int       // 灰度步骤数，不包括 promote
+	Version  string    // 灰度版本
+	Started  time.Time // 当前步骤开始的时间
+	Control  string    // 控制请求: ControlPause、ControlAbort，为空时正常执行
+	Operator string    // 执行 auto 的操作人
+}
+
+// Description 返回写入别名描述的进度
+func (p *Progress) Description() string {
+	control := ""
+	if p.Control != "" {
+		control = " control=" + p.Control
+	}
+	return fmt.Sprintf("%sstrategy=%s step=%d/%d version=%s started=%s%s operator=%s",
+		progressPrefix, p.Strategy, p.Step, p.Steps, p.Version, p.Started.UTC().Format(time.RFC3339), control, p.Operator)
+}
+
+// ParseProgress 解析别名描述中的进度，描述不是 auto 进度时 ok 为 false
+func ParseProgress(description string) (*Progress, bool) {
+	rest, ok := strings.CutPrefix(description, progressPrefix)
+	if !ok {
+		return nil, false
+	}
+
+	// 操作人在最后，可能包含空格
+	rest, operator, _ := strings.Cut(rest, " operator=")
+	fields := make(map[string]string)
+	for _, field := range strings.Fields(rest) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, false
+		}
+		fields[key] = value
+	}
+
+	p := &Progress{Strategy: fields["strategy"], Version: fields["version"], Control: fields["control"], Operator: operator}
+	step, steps, ok := strings.Cut(fields["step"], "/")
+	if !ok {
+		return nil, false
+	}
+	var err error
+	if p.Step, err = strconv.Atoi(step); err != nil {
+		return nil, false
+	}
+	if p.Steps, err = strconv.Atoi(steps); err != nil {
+		return nil, false
+	}
+	if p.Started, err = time.Parse(time.RFC3339, fields["started"]); err != nil {
+		return nil, false
+	}
+	if p.Strategy == "" || p.Version == "" || p.Step < 1 || p.Step > p.Steps {
+		return nil, false
+	}
+	if p.Control != "" && p.Control != ControlPause && p.Control != ControlAbort {
+		return nil, false
+	}
+	return p, true
+}
+
+// Remaining 返回当前步骤剩余的等待时间，wait 是该步骤的等待时间
+func (p *Progress) Remaining(wait time.Duration, now time.Time) time.Duration {
+	remaining := wait - now.Sub(p.Started)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
@@ -0,0 +1,246 @@
+// Package strategy 定义 auto 的流量切换策略: 每一步的灰度比例和等待时间
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFactor 是 exponential 策略默认的增长倍数
+const DefaultFactor = 2.0
+
+// 与 CodeDeploy 的 Lambda 部署配置对应的预设，名称可以省略 Lambda 前缀，不区分大小写
+var (
+	canaryPreset    = regexp.MustCompile(`(?i)^(?:lambda)?canary(\d+)percent(\d+)minutes?$`)
+	linearPreset    = regexp.MustCompile(`(?i)^(?:lambda)?linear(\d+)percentevery(\d+)minutes?$`)
+	allAtOncePreset = regexp.MustCompile(`(?i)^(?:lambda)?allatonce$`)
+)
+
+// Step 是灰度的一步: 将 Percent% 的流量切换到新版本后等待 Wait
+type Step struct {
+	Percent int
+	Wait    time.Duration
+}
+
+// Plan 是 auto 的执行计划，Steps 之后由 promote 切换 100% 流量
+type Plan struct {
+	Name  string // 策略描述，例如 Canary10Percent5Minutes
+	Steps []Step // 灰度比例严格递增，均在 1-99 之间
+}
+
+// Duration 返回所有步骤的等待时间之和
+func (p *Plan) Duration() time.Duration {
+	var total time.Duration
+	for _, step := range p.Steps {
+		total += step.Wait
+	}
+	return total
+}
+
+// Percents 返回每一步的灰度比例
+func (p *Plan) Percents() []int {
+	percents := make([]int, 0, len(p.Steps))
+	for _, step := range p.Steps {
+		percents = append(percents, step.Percent)
+	}
+	return percents
+}
+
+// Linear 创建每次增加 percent%、每步等待 wait 的计划（--percent 和 --wait）
+func Linear(percent int, wait time.Duration) (*Plan, error) {
+	if percent < 1 || percent > 100 {
+		return nil, fmt.Errorf("无效的百分比 '%d'，有效范围为 1-100", percent)
+	}
+	if wait < 0 {
+		return nil, fmt.Errorf("无效的等待时间 '%v'，不能为负数", wait)
+	}
+	plan := &Plan{Name: fmt.Sprintf("linear:%d%%:%v", percent, wait)}
+	for pct := percent; pct < 100; pct += percent {
+		plan.Steps = append(plan.Steps, Step{Percent: pct, Wait: wait})
+	}
+	return plan, nil
+}
+
+// Exponential 创建从 start% 开始、每步乘以 factor 的计划，每步等待 wait
+// 比例四舍五入为整数，重复的比例只保留一次
+func Exponential(start int, wait time.Duration, factor float64) (*Plan, error) {
+	if start < 1 || start > 99 {
+		return nil, fmt.Errorf("无效的起始百分比 '%d'，有效范围为 1-99", start)
+	}
+	if factor <= 1 {
+		return nil, fmt.Errorf("无效的增长倍数 '%v'，必须大于 1", factor)
+	}
+	if wait < 0 {
+		return nil, fmt.Errorf("无效的等待时间 '%v'，不能为负数", wait)
+	}
+	plan := &Plan{Name: fmt.Sprintf("exponential:%d%%:%v:%s", start, wait, strconv.FormatFloat(factor, 'f', -1, 64))}
+	last := 0
+	for p := float64(start); p < 100; p *= factor {
+		pct := int(math.Round(p))
+		if pct >= 100 {
+			break
+		}
+		if pct > last {
+			plan.Steps = append(plan.Steps, Step{Percent: pct, Wait: wait})
+			last = pct
+		}
+	}
+	return plan, nil
+}
+
+// Parse 解析策略
+// 支持以下格式:
+//   - 步骤列表: 1%:10m,5%:10m,25%:30m,50%:1h，最后一步可以是 100%（没有等待时间）
+//   - linear:<百分比>%:<等待时间>，例如 linear:10%:5m
+//   - exponential:<起始百分比>%:<等待时间>[:<倍数>]，例如 exponential:1%:10m 或 exponential:5%:10m:3
+//   - CodeDeploy 预设: Canary<百分比>Percent<分钟>Minutes、Linear<百分比>PercentEvery<分钟>Minutes、AllAtOnce，
+//     例如 Canary10Percent5Minutes、LambdaLinear10PercentEvery1Minute
+func Parse(spec string) (*Plan, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("策略不能为空")
+	}
+
+	if m := canaryPreset.FindStringSubmatch(spec); m != nil {
+		percent, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		if percent < 1 || percent > 99 {
+			return nil, fmt.Errorf("无效的策略 '%s': 百分比的有效范围为 1-99", spec)
+		}
+		return &Plan{Name: spec, Steps: []Step{{Percent: percent, Wait: time.Duration(minutes) * time.Minute}}}, nil
+	}
+	if m := linearPreset.FindStringSubmatch(spec); m != nil {
+		percent, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		plan, err := Linear(percent, time.Duration(minutes)*time.Minute)
+		if err != nil {
+			return nil, fmt.Errorf("无效的策略 '%s': %w", spec, err)
+		}
+		plan.Name = spec
+		return plan, nil
+	}
+	if allAtOncePreset.MatchString(spec) {
+		return &Plan{Name: spec}, nil
+	}
+
+	kind, rest, _ := strings.Cut(spec, ":")
+	switch strings.ToLower(kind) {
+	case "linear":
+		return parseLinear(spec, rest)
+	case "exponential":
+		return parseExponential(spec, rest)
+	}
+	return parseSteps(spec)
+}
+
+// parseLinear 解析 linear:<百分比>%:<等待时间>
+func parseLinear(spec, rest string) (*Plan, error) {
+	parts := strings.Split(rest, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("无效的策略 '%s'，格式为 linear:<百分比>%%:<等待时间>", spec)
+	}
+	percent, err := parsePercent(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("无效的策略 '%s': %w", spec, err)
+	}
+	wait, err := parseWait(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("无效的策略 '%s': %w", spec, err)
+	}
+	plan, err := Linear(percent, wait)
+	if err != nil {
+		return nil, fmt.Errorf("无效的策略 '%s': %w", spec, err)
+	}
+	return plan, nil
+}
+
+// parseExponential 解析 exponential:<起始百分比>%:<等待时间>[:<倍数>]
+func parseExponential(spec, rest string) (*Plan, error) {
+	parts := strings.Split(rest, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, fmt.Errorf("无效的策略 '%s'，格式为 exponential:<起始百分比>%%:<等待时间>[:<倍数>]", spec)
+	}
+	start, err := parsePercent(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("无效的策略 '%s': %w", spec, err)
+	}
+	wait, err := parseWait(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("无效的策略 '%s': %w", spec, err)
+	}
+	factor := DefaultFactor
+	if len(parts) == 3 {
+		if factor, err = strconv.ParseFloat(strings.TrimSpace(parts[2]), 64); err != nil {
+			return nil, fmt.Errorf("无效的策略 '%s': 倍数 '%s' 不是数字", spec, parts[2])
+		}
+	}
+	plan, err := Exponential(start, wait, factor)
+	if err != nil {
+		return nil, fmt.Errorf("无效的策略 '%s': %w", spec, err)
+	}
+	return plan, nil
+}
+
+// parseSteps 解析以逗号分隔的步骤列表，例如 1%:10m,5%:10m,50%:1h,100%
+func parseSteps(spec string) (*Plan, error) {
+	// 名称去掉空格，保存在 auto 进度中时作为一个字段
+	plan := &Plan{Name: strings.Join(strings.Fields(spec), "")}
+	items := strings.Split(spec, ",")
+	for i, item := range items {
+		pctPart, waitPart, hasWait := strings.Cut(strings.TrimSpace(item), ":")
+		percent, err := parsePercent(pctPart)
+		if err != nil {
+			return nil, fmt.Errorf("无效的策略 '%s': %w", spec, err)
+		}
+
+		// 最后一步可以是 100%，由 promote 完成
+		if percent == 100 {
+			if i != len(items)-1 || hasWait {
+				return nil, fmt.Errorf("无效的策略 '%s': 100%% 只能是最后一步且不能指定等待时间", spec)
+			}
+			break
+		}
+		if !hasWait {
+			return nil, fmt.Errorf("无效的策略 '%s': 步骤 '%s' 缺少等待时间，格式为 <百分比>%%:<等待时间>", spec, strings.TrimSpace(item))
+		}
+		wait, err := parseWait(waitPart)
+		if err != nil {
+			return nil, fmt.Errorf("无效的策略 '%s': %w", spec, err)
+		}
+		if len(plan.Steps) > 0 && percent <= plan.Steps[len(plan.Steps)-1].Percent {
+			return nil, fmt.Errorf("无效的策略 '%s': 百分比必须递增", spec)
+		}
+		plan.Steps = append(plan.Steps, Step{Percent: percent, Wait: wait})
+	}
+	return plan, nil
+}
+
+// parsePercent 解析 10% 形式的百分比，有效范围为 1-100
+func parsePercent(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if err != nil || !strings.HasSuffix(value, "%") {
+		return 0, fmt.Errorf("'%s' 不是百分比 (例如 10%%)", value)
+	}
+	if percent < 1 || percent > 100 {
+		return 0, fmt.Errorf("百分比 '%s' 超出范围 1-100", value)
+	}
+	return percent, nil
+}
+
+// parseWait 解析等待时间，例如 10m、1h
+func parseWait(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	wait, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' 不是有效的等待时间 (例如 10m)", value)
+	}
+	if wait < 0 {
+		return 0, fmt.Errorf("等待时间 '%s' 不能为负数", value)
+	}
+	return wait, nil
+}
@@ -4,10 +4,12 @@ import (
 	"os"
 
 	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/exitcode"
 )
 
 func main() {
+	// cobra 返回的错误都是命令行参数错误（未知命令、选项或多余的参数）
 	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitcode.ParamError)
 	}
 }
@@ -0,0 +1,158 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/audit"
+	"github.com/aura-studio/lad/internal/exitcode"
+)
+
+// auditRecords 读取审计日志中的所有记录，文件不存在时返回 nil
+func auditRecords(t *testing.T, path string) []audit.Record {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	var records []audit.Record
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var record audit.Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("audit log line %q is not JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAudit_RecordsMutatingCommands(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	dir := t.TempDir()
+	cmd.SetRecordDir(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+	if c := runLad(t, code, "switch", "--env", "test", "--version", "42"); c != exitcode.ResourceNotFound {
+		t.Fatalf("switch exit code = %d, want %d", c, exitcode.ResourceNotFound)
+	}
+	if c := runLad(t, code, "rollback", "--env", "test", "--reason", "error rate"); c != exitcode.Success {
+		t.Fatalf("rollback exit code = %d, want %d", c, exitcode.Success)
+	}
+	// 只读命令不记录
+	if c := runLad(t, code, "status", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("status exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	records := auditRecords(t, path)
+	if len(records) != 3 {
+		t.Fatalf("got %d audit records, want 3: %+v", len(records), records)
+	}
+
+	canary := records[0]
+	if canary.Command != "canary" || canary.Function != lifecycleFunction || canary.Env != "test" || canary.Flags["percent"] != "10" {
+		t.Errorf("canary record = %+v", canary)
+	}
+	if canary.Outcome != audit.Success || canary.ExitCode != exitcode.Success || canary.Operator == "" {
+		t.Errorf("canary outcome = %s (exit %d, operator %q), want success", canary.Outcome, canary.ExitCode, canary.Operator)
+	}
+	if before := canary.Before["live"]; before.Version != "1" || len(before.Weights) != 0 {
+		t.Errorf("canary before live = %+v, want version 1 without routing", before)
+	}
+	if after := canary.After["live"]; after.Version != "1" || after.Weights["2"] != 0.1 {
+		t.Errorf("canary after live = %+v, want version 1 with 10%% to version 2", after)
+	}
+
+	// 失败的命令同样记录，并带有退出码
+	failed := records[1]
+	if failed.Command != "switch" || failed.Outcome != audit.Failure || failed.ExitCode != exitcode.ResourceNotFound {
+		t.Errorf("switch record = %+v, want failure with exit code %d", failed, exitcode.ResourceNotFound)
+	}
+
+	rollback := records[2]
+	if rollback.Reason != "error rate" {
+		t.Errorf("rollback reason = %q, want %q", rollback.Reason, "error rate")
+	}
+	if after := rollback.After["live"]; after.Version != "1" || len(after.Weights) != 0 {
+		t.Errorf("rollback after live = %+v, want version 1 without routing", after)
+	}
+	if after := rollback.After["latest"]; after.Version != "1" {
+		t.Errorf("rollback after latest = %+v, want version 1", after)
+	}
+}
+
+func TestAudit_Sinks(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	dir := t.TempDir()
+	cmd.SetRecordDir(dir)
+	custom := filepath.Join(t.TempDir(), "ci", "audit.jsonl")
+
+	// 可以同时输出到多个位置
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "canary", "--env", "test", "--percent", "10", "--audit-log", "stdout", "--audit-log", custom); c != exitcode.Success {
+			t.Errorf("canary exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	if !strings.Contains(out, `"command":"canary"`) {
+		t.Errorf("stdout should contain the audit record, got:\n%s", out)
+	}
+	if records := auditRecords(t, custom); len(records) != 1 || records[0].Command != "canary" {
+		t.Errorf("custom audit log = %+v, want one canary record", records)
+	}
+
+	// none 不记录
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "20", "--audit-log", "none"); c != exitcode.Success {
+		t.Errorf("canary --audit-log none exit code = %d, want %d", c, exitcode.Success)
+	}
+	if records := auditRecords(t, filepath.Join(dir, "audit.log")); records != nil {
+		t.Errorf("default audit log = %+v, want none", records)
+	}
+
+	// 无效的输出在修改别名之前失败
+	if c := runLad(t, code, "promote", "--env", "test", "--audit-log", "webhook:ftp://example.com"); c != exitcode.ParamError {
+		t.Errorf("promote exit code = %d, want %d", c, exitcode.ParamError)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+}
+
+func TestAudit_SamconfigLocation(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	samconfig := filepath.Join(dir, "samconfig.toml")
+	content := "version = 0.1\n[test.lad.parameters]\naudit_log = \"" + filepath.ToSlash(path) + "\"\n"
+	if err := os.WriteFile(samconfig, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write samconfig: %v", err)
+	}
+	cmd.SetSamconfigPath(samconfig)
+	t.Cleanup(func() { cmd.SetSamconfigPath("samconfig.toml") })
+
+	if c := runLad(t, code, "promote", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("promote exit code = %d, want %d", c, exitcode.Success)
+	}
+	records := auditRecords(t, path)
+	if len(records) != 1 || records[0].Command != "promote" {
+		t.Fatalf("audit log = %+v, want one promote record", records)
+	}
+	if before, after := records[0].Before["live"], records[0].After["live"]; before.Version != "1" || after.Version != "2" {
+		t.Errorf("live before/after = %s/%s, want 1/2", before.Version, after.Version)
+	}
+}
@@ -0,0 +1,299 @@
+package cmd_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/strategy"
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/smithy-go"
+)
+
+// liveProgress 读取 live 别名描述中的 auto 进度，没有进度时返回 nil
+func liveProgress(t *testing.T, fake *aws.FakeLambda) *strategy.Progress {
+	t.Helper()
+
+	live, err := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, "live")
+	if err != nil {
+		t.Errorf("GetAliasState(live) unexpected error: %v", err)
+		return nil
+	}
+	progress, ok := strategy.ParseProgress(live.Description)
+	if !ok {
+		return nil
+	}
+	return progress
+}
+
+// requestControl 等待 auto 把灰度设置到 pct%，然后像 lad auto pause|resume|abort 一样写入控制请求
+// 在运行 auto 的同时从其他 goroutine 调用
+func requestControl(t *testing.T, fake *aws.FakeLambda, pct int, control string) {
+	t.Helper()
+
+	client := aws.NewClientWithAPI(fake)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		live, err := client.GetAliasState(context.Background(), lifecycleFunction, "live")
+		if err != nil {
+			t.Errorf("GetAliasState(live) unexpected error: %v", err)
+			return
+		}
+		progress, ok := strategy.ParseProgress(live.Description)
+		version, weight, routed := live.Canary()
+		if ok && routed && int(weight*100+0.5) == pct {
+			progress.Control = control
+			if _, c := client.ConfigureCanaryWithDescription(context.Background(), lifecycleFunction, "live", live.Version, version, weight, progress.Description(), live.RevisionID); c == exitcode.Success {
+				return
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("timed out waiting for the canary at %d%%", pct)
+}
+
+func TestAutoControl_Commands(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	// 没有进行中的 auto
+	if c := runLad(t, code, "auto", "pause", "--env", "test"); c != exitcode.ParamError {
+		t.Errorf("auto pause without progress exit code = %d, want %d", c, exitcode.ParamError)
+	}
+
+	setAutoProgress(t, fake, 25, &strategy.Progress{
+		Strategy: "25%:1h,75%:1h",
+		Step:     1,
+		Steps:    2,
+		Version:  "2",
+		Started:  time.Now(),
+		Operator: "alice",
+	})
+	for _, tt := range []struct {
+		args    []string
+		want    int
+		control string
+	}{
+		{[]string{"pause"}, exitcode.Success, strategy.ControlPause},
+		{[]string{"pause"}, exitcode.Success, strategy.ControlPause},
+		{[]string{"resume"}, exitcode.Success, ""},
+		{[]string{"resume"}, exitcode.Success, ""},
+		{[]string{"abort"}, exitcode.Success, strategy.ControlAbort},
+		{[]string{"pause"}, exitcode.ParamError, strategy.ControlAbort},
+	} {
+		args := append([]string{"auto"}, append(tt.args, "--env", "test")...)
+		if c := runLad(t, code, args...); c != tt.want {
+			t.Errorf("lad %v exit code = %d, want %d", args, c, tt.want)
+		}
+		progress := liveProgress(t, fake)
+		if progress == nil || progress.Control != tt.control || progress.Step != 1 || progress.Operator != "alice" {
+			t.Errorf("after lad %v progress = %+v, want control %q", args, progress, tt.control)
+		}
+	}
+
+	// 控制请求不修改灰度配置
+	if ok, version, weight := canaryState(t, fake); !ok || version != "2" || weight != 0.25 {
+		t.Errorf("canary = %v %s %v, want 25%% to version 2", ok, version, weight)
+	}
+}
+
+func TestAuto_PauseAndResumeWhileRunning(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	go func() {
+		requestControl(t, fake, 50, strategy.ControlPause)
+		// 超过等待时间后仍保持灰度
+		time.Sleep(600 * time.Millisecond)
+		if ok, _, weight := canaryState(t, fake); !ok || weight != 0.5 {
+			t.Errorf("canary = %v %v while paused, want 50%%", ok, weight)
+		}
+		requestControl(t, fake, 50, "")
+	}()
+
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--strategy", "50%:300ms", "--check-interval", "20ms"); c != exitcode.Success {
+			t.Errorf("auto exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	for _, want := range []string{"收到暂停请求，灰度保持在 50%", "收到恢复请求，继续灰度"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+	if got := aliasVersion(t, fake, "live"); got != "2" {
+		t.Errorf("live = %q, want %q", got, "2")
+	}
+}
+
+func TestAuto_AbortWhileRunning(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	go requestControl(t, fake, 25, strategy.ControlAbort)
+
+	if c := runLad(t, code, "auto", "--env", "test", "--strategy", "25%:1h", "--check-interval", "20ms"); c != exitcode.Interrupted {
+		t.Fatalf("auto exit code = %d, want %d", c, exitcode.Interrupted)
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("auto abort should clear the canary")
+	}
+	if got := aliasVersion(t, fake, "latest"); got != "1" {
+		t.Errorf("latest = %q, want %q", got, "1")
+	}
+	if line := lastRollbackLog(t); !strings.Contains(line, "lad auto abort") || !strings.HasSuffix(line, "ACTION=abort") {
+		t.Errorf("rollback.log = %q, want an abort entry", line)
+	}
+}
+
+// controlRaceLambda 在 auto 把灰度提高到 75% 之前，由"其他操作者"修改 live
+// 模拟控制请求在 auto 最后一次检查之后、修改别名之前写入
+type controlRaceLambda struct {
+	*aws.FakeLambda
+	t       *testing.T
+	control string  // 写入的控制请求
+	weight  float64 // 写入的灰度比例
+	raced   chan struct{}
+}
+
+func (r *controlRaceLambda) UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error) {
+	if params.RoutingConfig != nil && params.RoutingConfig.AdditionalVersionWeights["2"] == 0.75 && r.raced != nil {
+		client := aws.NewClientWithAPI(r.FakeLambda)
+		live, err := client.GetAliasState(ctx, lifecycleFunction, "live")
+		if err != nil {
+			r.t.Fatalf("GetAliasState(live) unexpected error: %v", err)
+		}
+		progress, _ := strategy.ParseProgress(live.Description)
+		progress.Control = r.control
+		if _, c := client.ConfigureCanaryWithDescription(ctx, lifecycleFunction, "live", live.Version, "2", r.weight, progress.Description(), live.RevisionID); c != exitcode.Success {
+			r.t.Fatalf("ConfigureCanaryWithDescription() exit code = %d", c)
+		}
+		close(r.raced)
+		r.raced = nil
+	}
+	return r.FakeLambda.UpdateAlias(ctx, params, optFns...)
+}
+
+func TestAuto_ControlRacesStepUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		control  string
+		weight   float64
+		wantCode int
+		wantLive string
+	}{
+		// 暂停请求: 等待恢复后继续，不因 RevisionId 变化而停止
+		{name: "pause", control: strategy.ControlPause, weight: 0.25, wantCode: exitcode.Success, wantLive: "2"},
+		// 取消请求: 与运行中收到 abort 相同
+		{name: "abort", control: strategy.ControlAbort, weight: 0.25, wantCode: exitcode.Interrupted, wantLive: "1"},
+		// 路由被其他操作修改: 停止
+		{name: "routing changed", weight: 0.1, wantCode: exitcode.StateChanged, wantLive: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, code := setupFakeLambda(t)
+			fake.Deploy(lifecycleFunction)
+			fake.Deploy(lifecycleFunction)
+			racing := &controlRaceLambda{FakeLambda: fake, t: t, control: tt.control, weight: tt.weight, raced: make(chan struct{})}
+			raced := racing.raced
+			cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+				return aws.NewClientWithAPI(racing), nil
+			})
+			if tt.control == strategy.ControlPause {
+				go func() {
+					<-raced
+					time.Sleep(100 * time.Millisecond)
+					requestControl(t, fake, 25, "")
+				}()
+			}
+
+			if c := runLad(t, code, "auto", "--env", "test", "--strategy", "25%:0s,75%:0s", "--check-interval", "20ms"); c != tt.wantCode {
+				t.Fatalf("auto exit code = %d, want %d", c, tt.wantCode)
+			}
+			if got := aliasVersion(t, fake, "live"); got != tt.wantLive {
+				t.Errorf("live = %q, want %q", got, tt.wantLive)
+			}
+		})
+	}
+}
+
+func TestAuto_UnknownSubcommand(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	// 拼错的子命令返回参数错误（lad 以 ParamError 退出），不执行灰度
+	err := cmd.ExecuteArgs([]string{"auto", "puase", "--env", "test", "--strategy", "50%:0s", "--function", lifecycleFunction})
+	if err == nil || !strings.Contains(err.Error(), "puase") {
+		t.Errorf("lad auto puase error = %v, want unknown command", err)
+	}
+	if *code != exitcode.Success {
+		t.Errorf("auto ran and exited with %d", *code)
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("lad auto puase should not configure a canary")
+	}
+	for alias, want := range map[string]string{"live": "1", "previous": "1", "latest": "2"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("%s = %q, want %q", alias, got, want)
+		}
+	}
+}
+
+// resumeFailingLambda 让 auto 的第 failAt 次修改 live 别名以权限不足失败（不重试）
+type resumeFailingLambda struct {
+	*aws.FakeLambda
+	calls  int
+	failAt int
+}
+
+func (r *resumeFailingLambda) UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error) {
+	if sdkaws.ToString(params.Name) == "live" {
+		r.calls++
+		if r.calls == r.failAt {
+			return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform lambda:UpdateAlias"}
+		}
+	}
+	return r.FakeLambda.UpdateAlias(ctx, params, optFns...)
+}
+
+func TestAuto_ResumeWriteFails(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	// 第 1 次是设置 50% 灰度，第 2 次是恢复时写入进度
+	failing := &resumeFailingLambda{FakeLambda: fake, failAt: 2}
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		return aws.NewClientWithAPI(failing), nil
+	})
+
+	go func() {
+		requestControl(t, fake, 50, strategy.ControlPause)
+		time.Sleep(100 * time.Millisecond)
+		requestControl(t, fake, 50, "")
+	}()
+
+	// 写入失败时不恢复，auto 停止并保持灰度
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--strategy", "50%:300ms", "--check-interval", "20ms"); c != exitcode.Forbidden {
+			t.Errorf("auto exit code = %d, want %d", c, exitcode.Forbidden)
+		}
+	})
+	if strings.Contains(out, "收到恢复请求，继续灰度") || !strings.Contains(out, "live 别名中仍为暂停请求") {
+		t.Errorf("output should report the failed resume, got:\n%s", out)
+	}
+	if ok, _, weight := canaryState(t, fake); !ok || weight != 0.5 {
+		t.Errorf("canary = %v %v, want 50%% kept", ok, weight)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+}
@@ -0,0 +1,490 @@
+package cmd_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/strategy"
+)
+
+// setupFakeCloudWatch 使用 FakeCloudWatch 作为 auto 健康检查的 CloudWatch 客户端
+func setupFakeCloudWatch(t *testing.T) *aws.FakeCloudWatch {
+	t.Helper()
+
+	cw := aws.NewFakeCloudWatch()
+	cmd.SetMetricsFactory(func(ctx context.Context, opts aws.ClientOptions) (aws.MetricsAPI, error) {
+		return cw, nil
+	})
+	t.Cleanup(func() { cmd.SetMetricsFactory(nil) })
+	return cw
+}
+
+func TestAuto_HealthyPromotes(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	cw := setupFakeCloudWatch(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	cw.SetAlarm("api-errors", aws.AlarmStateOK, "")
+	cw.SetMetric("Errors", 0)
+
+	if c := runLad(t, code, "auto", "--env", "test", "--percent", "50", "--wait", "0s",
+		"--alarm", "api-errors", "--metric", "Errors>0"); c != exitcode.Success {
+		t.Fatalf("auto exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "2" {
+		t.Errorf("live = %q, want %q", got, "2")
+	}
+
+	// 指标查询的是新版本在 live 别名上的数据
+	queries := cw.Queries()
+	if len(queries) == 0 {
+		t.Fatal("auto should query metrics during the canary")
+	}
+	if dims := queries[0].Dimensions; dims["Resource"] != lifecycleFunction+":live" || dims["ExecutedVersion"] != "2" {
+		t.Errorf("metric dimensions = %v", dims)
+	}
+}
+
+func TestAuto_MetricBreachAborts(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	cw := setupFakeCloudWatch(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	cw.SetMetric("Errors", 3)
+
+	if c := runLad(t, code, "auto", "--env", "test", "--percent", "10", "--wait", "0s", "--metric", "Errors>0"); c != exitcode.HealthCheckFailed {
+		t.Fatalf("auto exit code = %d, want %d", c, exitcode.HealthCheckFailed)
+	}
+
+	// 灰度已清除，latest 重置到 live 的版本并标记新版本为取消发布
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("live should not have a canary configured")
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+	latest, err := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, "latest")
+	if err != nil {
+		t.Fatalf("GetAliasState(latest) unexpected error: %v", err)
+	}
+	if latest.Version != "1" || !strings.Contains(latest.Description, "Errors:Sum>0") {
+		t.Errorf("latest = %s (%q), want version 1 rejecting version 2", latest.Version, latest.Description)
+	}
+	if log := lastRollbackLog(t); !strings.Contains(log, "ACTION=abort") || !strings.Contains(log, "FROM_VERSION=2") || !strings.Contains(log, "Errors:Sum>0") {
+		t.Errorf("rollback.log = %q, want abort of version 2 with the breached metric", log)
+	}
+}
+
+func TestAuto_AlarmBeforeStart(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	cw := setupFakeCloudWatch(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	cw.SetAlarm("api-errors", aws.AlarmStateAlarm, "Threshold Crossed")
+
+	if c := runLad(t, code, "auto", "--env", "test", "--wait", "0s", "--alarm", "api-errors"); c != exitcode.HealthCheckFailed {
+		t.Fatalf("auto exit code = %d, want %d", c, exitcode.HealthCheckFailed)
+	}
+	// 开始灰度前失败时不修改别名
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("live should not have a canary configured")
+	}
+	if got := aliasVersion(t, fake, "latest"); got != "2" {
+		t.Errorf("latest = %q, want %q", got, "2")
+	}
+
+	// 告警不存在
+	if c := runLad(t, code, "auto", "--env", "test", "--wait", "0s", "--alarm", "missing"); c != exitcode.ResourceNotFound {
+		t.Errorf("auto with missing alarm exit code = %d, want %d", c, exitcode.ResourceNotFound)
+	}
+}
+
+func TestAuto_HealthCheckUnavailable(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	cw := setupFakeCloudWatch(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	cw.SetError(errors.New("connection reset"))
+
+	// 没有一次检查成功时停止，灰度保持不变
+	if c := runLad(t, code, "auto", "--env", "test", "--percent", "10", "--wait", "0s", "--metric", "Errors>0"); c == exitcode.Success || c == exitcode.HealthCheckFailed {
+		t.Fatalf("auto exit code = %d, want an AWS error", c)
+	}
+	if ok, version, weight := canaryState(t, fake); !ok || version != "2" || weight != 0.1 {
+		t.Errorf("canary = %v %s %v, want 10%% to version 2", ok, version, weight)
+	}
+}
+
+func TestAuto_InvalidHealthFlags(t *testing.T) {
+	_, code := setupFakeLambda(t)
+	setupFakeCloudWatch(t)
+
+	for _, args := range [][]string{
+		{"--metric", "Errors"},
+		{"--metric", "Errors>0", "--metric-period", "90s"},
+		{"--alarm", "api-errors", "--check-interval", "0s"},
+	} {
+		args = append([]string{"auto", "--env", "test"}, args...)
+		if c := runLad(t, code, args...); c != exitcode.ParamError {
+			t.Errorf("%v exit code = %d, want %d", args, c, exitcode.ParamError)
+		}
+	}
+}
+
+func TestAuto_StrategySteps(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--strategy", "1%:0s,25%:0s,50%:0s"); c != exitcode.Success {
+			t.Errorf("auto exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	for _, want := range []string{"[1/4]   1%", "[3/4]  50%", "[4/4] promote"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain the plan step %q, got:\n%s", want, out)
+		}
+	}
+	if got := aliasVersion(t, fake, "live"); got != "2" {
+		t.Errorf("live = %q, want %q", got, "2")
+	}
+}
+
+func TestAuto_StrategyFromSamconfig(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	samconfig := filepath.Join(t.TempDir(), "samconfig.toml")
+	content := "version = 0.1\n[test.lad.parameters]\nauto_strategy = \"Canary10Percent5Minutes\"\n"
+	if err := os.WriteFile(samconfig, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write samconfig: %v", err)
+	}
+	cmd.SetSamconfigPath(samconfig)
+	t.Cleanup(func() { cmd.SetSamconfigPath("samconfig.toml") })
+
+	// dry-run 只输出执行计划
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--dry-run"); c != exitcode.Success {
+			t.Errorf("auto --dry-run exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	if !strings.Contains(out, "Canary10Percent5Minutes (samconfig.toml)") || !strings.Contains(out, "[1/2]  10%") || !strings.Contains(out, "预计耗时: 5m0s") {
+		t.Errorf("output should contain the samconfig plan, got:\n%s", out)
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("dry-run should not configure a canary")
+	}
+
+	// --percent 和 --wait 优先于 samconfig
+	out = captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--percent", "50", "--dry-run"); c != exitcode.Success {
+			t.Errorf("auto --percent --dry-run exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	if !strings.Contains(out, "linear:50%:5m0s (--percent/--wait)") {
+		t.Errorf("output should contain the linear plan, got:\n%s", out)
+	}
+}
+
+func TestAuto_InvalidStrategy(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	for _, args := range [][]string{
+		{"--strategy", "50%:1m,10%:1m"},
+		{"--strategy", "Canary10Percent5Minutes", "--percent", "20"},
+		{"--strategy", "AllAtOnce", "--wait", "1m"},
+	} {
+		args = append([]string{"auto", "--env", "test"}, args...)
+		if c := runLad(t, code, args...); c != exitcode.ParamError {
+			t.Errorf("%v exit code = %d, want %d", args, c, exitcode.ParamError)
+		}
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+}
+
+// setAutoProgress 将 live 配置为 pct% 灰度到版本 2，并在描述中记录 auto 进度
+func setAutoProgress(t *testing.T, fake *aws.FakeLambda, pct int, progress *strategy.Progress) {
+	t.Helper()
+
+	client := aws.NewClientWithAPI(fake)
+	if _, c := client.ConfigureCanaryWithDescription(context.Background(), lifecycleFunction, "live", "1", "2", float64(pct)/100, progress.Description(), ""); c != exitcode.Success {
+		t.Fatalf("ConfigureCanaryWithDescription() exit code = %d", c)
+	}
+}
+
+func TestAuto_ResumeAfterInterruption(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	cw := setupFakeCloudWatch(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	// 健康检查不可用时在第一步停止，进度保存在 live 的描述中
+	cw.SetError(errors.New("connection reset"))
+	if c := runLad(t, code, "auto", "--env", "test", "--strategy", "10%:0s,50%:0s", "--metric", "Errors>0"); c == exitcode.Success {
+		t.Fatal("auto should stop when health checks are unavailable")
+	}
+	live, err := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, "live")
+	if err != nil {
+		t.Fatalf("GetAliasState(live) unexpected error: %v", err)
+	}
+	progress, ok := strategy.ParseProgress(live.Description)
+	if !ok || progress.Strategy != "10%:0s,50%:0s" || progress.Step != 1 || progress.Steps != 2 || progress.Version != "2" {
+		t.Fatalf("live description = %q, want progress of step 1/2", live.Description)
+	}
+
+	// --resume 使用记录的策略，从第二步继续
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--resume"); c != exitcode.Success {
+			t.Errorf("auto --resume exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	for _, want := range []string{"10%:0s,50%:0s (进度记录)", "[1/3]  10% 已达到，跳过", "[2/3] 执行灰度: 50%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+	live, err = aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, "live")
+	if err != nil {
+		t.Fatalf("GetAliasState(live) unexpected error: %v", err)
+	}
+	if live.Version != "2" || live.HasRouting() || live.Description != "" {
+		t.Errorf("live = %s %v (%q), want version 2 without routing or progress", live.Version, live.Weights, live.Description)
+	}
+}
+
+func TestAuto_ResumeRemainingBakeTime(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	setAutoProgress(t, fake, 25, &strategy.Progress{
+		Strategy: "25%:1h,75%:1h",
+		Step:     1,
+		Steps:    2,
+		Version:  "2",
+		Started:  time.Now().Add(-40 * time.Minute),
+		Operator: "alice",
+	})
+
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--resume", "--dry-run"); c != exitcode.Success {
+			t.Errorf("auto --resume --dry-run exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	// 第一步还需等待约 20 分钟，之后第二步等待 1 小时
+	if !strings.Contains(out, "当前灰度 25%，继续等待 19m") && !strings.Contains(out, "当前灰度 25%，继续等待 20m") {
+		t.Errorf("output should contain the remaining bake time, got:\n%s", out)
+	}
+	if !strings.Contains(out, "[2/3]  75% 流量到新版本，等待 1h0m0s") {
+		t.Errorf("output should contain the next step, got:\n%s", out)
+	}
+	if ok, version, weight := canaryState(t, fake); !ok || version != "2" || weight != 0.25 {
+		t.Errorf("canary = %v %s %v, want 25%% to version 2 unchanged", ok, version, weight)
+	}
+}
+
+func TestAuto_ResumeAfterManualCanary(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	setAutoProgress(t, fake, 25, &strategy.Progress{
+		Strategy: "25%:1h,75%:1h",
+		Step:     1,
+		Steps:    2,
+		Version:  "2",
+		Started:  time.Now().Add(-40 * time.Minute),
+		Operator: "alice",
+	})
+
+	// 手动清除灰度后重新开始灰度，中断的 auto 的进度被清除
+	for _, percent := range []string{"0", "25"} {
+		if c := runLad(t, code, "canary", "--env", "test", "--percent", percent); c != exitcode.Success {
+			t.Fatalf("canary --percent %s exit code = %d, want %d", percent, c, exitcode.Success)
+		}
+	}
+	if got := liveProgress(t, fake); got != nil {
+		t.Fatalf("live progress after manual canary = %+v, want none", got)
+	}
+
+	// --resume 不使用过时的进度，按当前比例等待完整时间
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--resume", "--strategy", "25%:1h,75%:1h", "--dry-run"); c != exitcode.Success {
+			t.Errorf("auto --resume --dry-run exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	for _, want := range []string{"live 别名中没有版本 2 的进度记录", "当前灰度 25%，继续等待 1h0m0s"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+
+	// 没有运行中的 auto，控制请求失败
+	if c := runLad(t, code, "auto", "pause", "--env", "test"); c != exitcode.ParamError {
+		t.Errorf("auto pause exit code = %d, want %d", c, exitcode.ParamError)
+	}
+}
+
+func TestAuto_NeverLowersTraffic(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "50"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	// 没有进度记录时跳过不高于当前比例的步骤
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--percent", "25", "--wait", "0s"); c != exitcode.Success {
+			t.Errorf("auto exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	if strings.Contains(out, "执行灰度: 25%") || !strings.Contains(out, "执行灰度: 75%") {
+		t.Errorf("auto should continue from 50%% without lowering traffic, got:\n%s", out)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "2" {
+		t.Errorf("live = %q, want %q", got, "2")
+	}
+}
+
+func TestAuto_Restart(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "50"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	if c := runLad(t, code, "auto", "--env", "test", "--resume", "--restart"); c != exitcode.ParamError {
+		t.Errorf("auto --resume --restart exit code = %d, want %d", c, exitcode.ParamError)
+	}
+
+	// --restart 明确要求从第一步开始
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--percent", "25", "--wait", "0s", "--restart"); c != exitcode.Success {
+			t.Errorf("auto --restart exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	if !strings.Contains(out, "执行灰度: 25%") {
+		t.Errorf("auto --restart should start from the first step, got:\n%s", out)
+	}
+}
+
+// runAutoInterrupted 执行 auto 并在灰度开始后取消 ctx（模拟 Ctrl-C），返回退出码和输出
+func runAutoInterrupted(t *testing.T, code *int, args ...string) (int, string) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	timer := time.AfterFunc(200*time.Millisecond, cancel)
+	defer timer.Stop()
+
+	*code = exitcode.Success
+	args = append([]string{"auto", "--env", "test", "--strategy", "25%:1h,75%:1h", "--function", lifecycleFunction}, args...)
+	out := captureStdout(t, func() {
+		if err := cmd.ExecuteArgsContext(ctx, args); err != nil {
+			t.Fatalf("lad %v: unexpected error: %v", args, err)
+		}
+	})
+	return *code, out
+}
+
+func TestAuto_InterruptHold(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	c, out := runAutoInterrupted(t, code)
+	if c != exitcode.Interrupted {
+		t.Fatalf("auto exit code = %d, want %d", c, exitcode.Interrupted)
+	}
+	if ok, version, weight := canaryState(t, fake); !ok || version != "2" || weight != 0.25 {
+		t.Errorf("canary = %v %s %v, want 25%% to version 2 held", ok, version, weight)
+	}
+	if !strings.Contains(out, "lad auto --env test --resume") {
+		t.Errorf("output should contain the resume command, got:\n%s", out)
+	}
+	if line := lastRollbackLog(t); !strings.Contains(line, "FROM_VERSION=2 TO_VERSION=1") || !strings.Contains(line, "灰度保持在 25%") || !strings.HasSuffix(line, "ACTION=interrupt") {
+		t.Errorf("rollback.log = %q, want an interrupt entry", line)
+	}
+
+	// 进度保留在 live 的描述中，之后的命令不受已取消的 ctx 影响
+	out = captureStdout(t, func() {
+		if c := runLad(t, code, "auto", "--env", "test", "--resume", "--dry-run"); c != exitcode.Success {
+			t.Errorf("auto --resume --dry-run exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	if !strings.Contains(out, "[2/3]  75% 流量到新版本") || !strings.Contains(out, "当前灰度 25%，继续等待 59m") {
+		t.Errorf("resume plan should continue from 25%%, got:\n%s", out)
+	}
+}
+
+func TestAuto_InterruptRevert(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	if c, _ := runAutoInterrupted(t, code, "--on-interrupt", "revert"); c != exitcode.Interrupted {
+		t.Fatalf("auto exit code = %d, want %d", c, exitcode.Interrupted)
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("auto --on-interrupt revert should clear the canary")
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+	if got := aliasVersion(t, fake, "latest"); got != "2" {
+		t.Errorf("latest = %q, want %q", got, "2")
+	}
+	if line := lastRollbackLog(t); !strings.Contains(line, "从 25% 恢复为 0%") || !strings.HasSuffix(line, "ACTION=interrupt") {
+		t.Errorf("rollback.log = %q, want an interrupt entry", line)
+	}
+}
+
+func TestAuto_InterruptRollbackFromSamconfig(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	samconfig := filepath.Join(t.TempDir(), "samconfig.toml")
+	if err := os.WriteFile(samconfig, []byte("[test.lad.parameters]\nauto_on_interrupt = \"rollback\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write samconfig.toml: %v", err)
+	}
+	cmd.SetSamconfigPath(samconfig)
+	t.Cleanup(func() { cmd.SetSamconfigPath("samconfig.toml") })
+
+	if c, _ := runAutoInterrupted(t, code); c != exitcode.Interrupted {
+		t.Fatalf("auto exit code = %d, want %d", c, exitcode.Interrupted)
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("auto_on_interrupt = rollback should clear the canary")
+	}
+	if got := aliasVersion(t, fake, "latest"); got != "1" {
+		t.Errorf("latest = %q, want %q", got, "1")
+	}
+	if line := lastRollbackLog(t); !strings.Contains(line, "自动灰度被中断") || !strings.HasSuffix(line, "ACTION=abort") {
+		t.Errorf("rollback.log = %q, want an abort entry", line)
+	}
+}
+
+func TestAuto_InvalidOnInterrupt(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	if c := runLad(t, code, "auto", "--env", "test", "--on-interrupt", "ignore"); c != exitcode.ParamError {
+		t.Errorf("auto --on-interrupt ignore exit code = %d, want %d", c, exitcode.ParamError)
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("auto with an invalid --on-interrupt should not start the canary")
+	}
+}
@@ -0,0 +1,268 @@
+package cmd_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// fakeSAM 是替代 SAM CLI 的脚本，记录每次调用的参数
+type fakeSAM struct {
+	path string
+	log  string
+}
+
+// newFakeSAM 创建 SAM CLI 脚本，failOn 指定的子命令以非零退出码退出
+func newFakeSAM(t *testing.T, failOn string) *fakeSAM {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake SAM CLI requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	sam := &fakeSAM{path: filepath.Join(dir, "sam"), log: filepath.Join(dir, "sam.log")}
+	script := "#!/bin/sh\necho \"$@\" >> " + sam.log + "\n"
+	if failOn != "" {
+		script += "if [ \"$1\" = \"" + failOn + "\" ]; then exit 3; fi\n"
+	}
+	if err := os.WriteFile(sam.path, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write fake SAM CLI: %v", err)
+	}
+	return sam
+}
+
+// calls 返回脚本被调用的参数列表
+func (s *fakeSAM) calls(t *testing.T) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(s.log)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("failed to read fake SAM CLI log: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+// samLambda 在 sam deploy 执行后的第一次 GetAlias 时调用 onDeploy，模拟 CloudFormation 部署结果
+type samLambda struct {
+	*aws.FakeLambda
+	t        *testing.T
+	sam      *fakeSAM
+	onDeploy func()
+	seen     int // 已处理的 SAM CLI 调用数
+}
+
+func (s *samLambda) GetAlias(ctx context.Context, params *lambda.GetAliasInput, optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error) {
+	calls := s.sam.calls(s.t)
+	for ; s.seen < len(calls); s.seen++ {
+		if strings.HasPrefix(calls[s.seen], "deploy") && s.onDeploy != nil {
+			s.onDeploy()
+		}
+	}
+	return s.FakeLambda.GetAlias(ctx, params, optFns...)
+}
+
+// setupDeploy 使用 fake SAM CLI 执行 deploy，sam deploy 之后调用 onDeploy
+func setupDeploy(t *testing.T, failOn string, onDeploy func(fake *aws.FakeLambda)) (*aws.FakeLambda, *fakeSAM, *int) {
+	t.Helper()
+
+	fake, code := setupFakeLambda(t)
+	sam := newFakeSAM(t, failOn)
+	api := &samLambda{FakeLambda: fake, t: t, sam: sam}
+	if onDeploy != nil {
+		api.onDeploy = func() { onDeploy(fake) }
+	}
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		return aws.NewClientWithAPI(api), nil
+	})
+	return fake, sam, code
+}
+
+// deployFunction 模拟在已打补丁的模板上部署，CloudFormation 把三个别名都移动到新版本
+func deployFunction(fake *aws.FakeLambda) {
+	fake.DeployStack(lifecycleFunction)
+}
+
+func TestDeploy_StableState(t *testing.T) {
+	fake, sam, code := setupDeploy(t, "", deployFunction)
+	fake.Deploy(lifecycleFunction)
+
+	if c := runLad(t, code, "deploy", "--env", "prod", "--sam-path", sam.path); c != exitcode.Success {
+		t.Fatalf("deploy exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	want := []string{
+		"build --config-env prod",
+		"deploy --config-env prod --no-confirm-changeset --no-fail-on-empty-changeset",
+	}
+	if got := sam.calls(t); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("sam calls = %q, want %q", got, want)
+	}
+	// CloudFormation 移动的 live 和 previous 按部署前记录恢复
+	for alias, want := range map[string]string{"live": "1", "previous": "1", "latest": "2"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("%s = %q, want %q", alias, got, want)
+		}
+	}
+}
+
+func TestDeploy_FirstDeploy(t *testing.T) {
+	fake, sam, code := setupDeploy(t, "", deployFunction)
+
+	if c := runLad(t, code, "deploy", "--env", "test", "--sam-path", sam.path, "--skip-build"); c != exitcode.Success {
+		t.Fatalf("deploy exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := sam.calls(t); len(got) != 1 || !strings.HasPrefix(got[0], "deploy") {
+		t.Errorf("sam calls = %q, want only deploy", got)
+	}
+	for _, alias := range []string{"live", "previous", "latest"} {
+		if got := aliasVersion(t, fake, alias); got != "1" {
+			t.Errorf("%s = %q, want %q", alias, got, "1")
+		}
+	}
+}
+
+func TestDeploy_Blocked(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, fake *aws.FakeLambda, code *int)
+	}{
+		{
+			name: "latest not promoted",
+			setup: func(t *testing.T, fake *aws.FakeLambda, code *int) {
+				fake.Deploy(lifecycleFunction)
+				fake.Deploy(lifecycleFunction)
+			},
+		},
+		{
+			name: "active canary",
+			setup: func(t *testing.T, fake *aws.FakeLambda, code *int) {
+				fake.Deploy(lifecycleFunction)
+				fake.Deploy(lifecycleFunction)
+				if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+					t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, sam, code := setupDeploy(t, "", deployFunction)
+			tt.setup(t, fake, code)
+
+			if c := runLad(t, code, "deploy", "--env", "test", "--sam-path", sam.path); c != exitcode.ParamError {
+				t.Fatalf("deploy exit code = %d, want %d", c, exitcode.ParamError)
+			}
+			if got := sam.calls(t); len(got) != 0 {
+				t.Errorf("sam should not be invoked, got calls %q", got)
+			}
+			if got := aliasVersion(t, fake, "latest"); got != "2" {
+				t.Errorf("latest = %q, want %q", got, "2")
+			}
+		})
+	}
+}
+
+func TestDeploy_SAMFailure(t *testing.T) {
+	fake, sam, code := setupDeploy(t, "build", deployFunction)
+	fake.Deploy(lifecycleFunction)
+
+	if c := runLad(t, code, "deploy", "--env", "test", "--sam-path", sam.path); c != exitcode.AWSError {
+		t.Fatalf("deploy exit code = %d, want %d", c, exitcode.AWSError)
+	}
+	// build 失败后不执行 deploy
+	if got := sam.calls(t); len(got) != 1 {
+		t.Errorf("sam calls = %q, want only build", got)
+	}
+}
+
+func TestDeploy_NoCodeChange(t *testing.T) {
+	fake, sam, code := setupDeploy(t, "", nil)
+	fake.Deploy(lifecycleFunction)
+
+	if c := runLad(t, code, "deploy", "--env", "test", "--sam-path", sam.path); c != exitcode.Success {
+		t.Fatalf("deploy exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := aliasVersion(t, fake, "latest"); got != "1" {
+		t.Errorf("latest = %q, want %q", got, "1")
+	}
+}
+
+func TestDeploy_UnexpectedAliasState(t *testing.T) {
+	// 部署前记录在 sam deploy 期间被删除，无法确认恢复的目标版本
+	dir := t.TempDir()
+	fake, sam, code := setupDeploy(t, "", func(fake *aws.FakeLambda) {
+		fake.DeployStack(lifecycleFunction)
+		os.Remove(filepath.Join(dir, "pre-deploy-test.json"))
+	})
+	cmd.SetRecordDir(dir)
+	fake.Deploy(lifecycleFunction)
+
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "deploy", "--env", "test", "--sam-path", sam.path); c != exitcode.StateChanged {
+			t.Fatalf("deploy exit code = %d, want %d", c, exitcode.StateChanged)
+		}
+	})
+	if !strings.Contains(out, "lad reconcile --env test") {
+		t.Errorf("output should suggest lad reconcile, got:\n%s", out)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "2" {
+		t.Errorf("live = %q, want %q", got, "2")
+	}
+}
+
+func TestDeploy_RestoresMovedAliases(t *testing.T) {
+	fake, sam, code := setupDeploy(t, "", deployFunction)
+	promoteVersions(t, fake, code, 1) // live 2, previous 1
+
+	if c := runLad(t, code, "deploy", "--env", "test", "--sam-path", sam.path, "--skip-build"); c != exitcode.Success {
+		t.Fatalf("deploy exit code = %d, want %d", c, exitcode.Success)
+	}
+	for alias, want := range map[string]string{"live": "2", "previous": "1", "latest": "3"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("%s = %q, want %q", alias, got, want)
+		}
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("live should not have a canary configured")
+	}
+}
+
+func TestGetSAMPath(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "samconfig.toml")
+	content := `version = 0.1
+[prod.lad.parameters]
+sam_path = "/opt/sam/bin/sam"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	cmd.SetSAMPath("")
+	cmd.SetSamconfigPath(configPath)
+	t.Cleanup(func() { cmd.SetSamconfigPath("samconfig.toml") })
+
+	if got := cmd.GetSAMPath("prod"); got != "/opt/sam/bin/sam" {
+		t.Errorf("GetSAMPath(prod) = %q, want %q", got, "/opt/sam/bin/sam")
+	}
+	if got := cmd.GetSAMPath("test"); got != "sam" {
+		t.Errorf("GetSAMPath(test) = %q, want %q", got, "sam")
+	}
+
+	// --sam-path 优先
+	cmd.SetSAMPath("./bin/sam")
+	t.Cleanup(func() { cmd.SetSAMPath("") })
+	if got := cmd.GetSAMPath("prod"); got != "./bin/sam" {
+		t.Errorf("GetSAMPath(prod) with flag = %q, want %q", got, "./bin/sam")
+	}
+}
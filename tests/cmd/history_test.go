@@ -0,0 +1,175 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/exitcode"
+)
+
+// runHistory 执行 history 命令，返回退出码和标准输出
+// 与 runLad 不同，不附加 --function，旧格式的回退日志没有函数名
+func runHistory(t *testing.T, code *int, args ...string) (int, string) {
+	t.Helper()
+
+	*code = exitcode.Success
+	out := captureStdout(t, func() {
+		if err := cmd.ExecuteArgs(append([]string{"history"}, args...)); err != nil {
+			t.Fatalf("lad history %v: unexpected error: %v", args, err)
+		}
+	})
+	return *code, out
+}
+
+// historyReport 执行 history --output json 并解析输出
+func historyReport(t *testing.T, code *int, args ...string) cmd.HistoryReport {
+	t.Helper()
+
+	c, out := runHistory(t, code, append([]string{"--output", "json"}, args...)...)
+	if c != exitcode.Success {
+		t.Fatalf("history exit code = %d, want %d", c, exitcode.Success)
+	}
+	var report cmd.HistoryReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("history output is not JSON: %v\n%s", err, out)
+	}
+	return report
+}
+
+func TestHistory_AuditLog(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	dir := t.TempDir()
+	cmd.SetRecordDir(dir)
+
+	for _, args := range [][]string{
+		{"canary", "--percent", "10"},
+		{"promote"},
+		{"switch", "--version", "42"},
+	} {
+		runLad(t, code, append(args, "--env", "test")...)
+	}
+
+	report := historyReport(t, code, "--command", "canary,promote,switch")
+	if len(report.Records) != 3 {
+		t.Fatalf("got %d records, want 3: %+v", len(report.Records), report.Records)
+	}
+	if report.Records[0].Command != "canary" || report.Records[2].ExitCode != exitcode.ResourceNotFound {
+		t.Errorf("records = %+v, want canary first and failed switch last", report.Records)
+	}
+	if len(report.Files) == 0 || report.Files[0] != filepath.Join(dir, "audit.log") {
+		t.Errorf("files = %v, want %s first", report.Files, filepath.Join(dir, "audit.log"))
+	}
+
+	// 过滤条件
+	if got := historyReport(t, code, "--command", "promote").Records; len(got) != 1 || got[0].After["live"].Version != "2" {
+		t.Errorf("--command promote = %+v, want one promote to version 2", got)
+	}
+	if got := historyReport(t, code, "--env", "prod", "--command", "canary").Records; len(got) != 0 {
+		t.Errorf("--env prod = %+v, want none", got)
+	}
+	if got := historyReport(t, code, "--operator", "nobody-else").Records; len(got) != 0 {
+		t.Errorf("--operator nobody-else = %+v, want none", got)
+	}
+	if got := historyReport(t, code, "--command", "canary", "--until", "1h").Records; len(got) != 0 {
+		t.Errorf("--until 1h = %+v, want none", got)
+	}
+	if got := historyReport(t, code, "--command", "canary,promote", "--limit", "1").Records; len(got) != 1 || got[0].Command != "promote" {
+		t.Errorf("--limit 1 = %+v, want the latest promote", got)
+	}
+
+	// 表格输出按环境显示版本变化
+	c, out := runHistory(t, code, "--command", "canary,promote")
+	if c != exitcode.Success {
+		t.Errorf("history exit code = %d, want %d", c, exitcode.Success)
+	}
+	for _, want := range []string{"环境: test", "live: 1 -> 1 (90%), 2 (10%)", "live: 1 (90%), 2 (10%) -> 2"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("history output should contain %q, got:\n%s", want, out)
+		}
+	}
+	// 没有变化的别名不显示
+	if strings.Contains(out, "previous: 1 -> 1") {
+		t.Errorf("history output should not list unchanged aliases:\n%s", out)
+	}
+}
+
+func TestHistory_LegacyRollbackLog(t *testing.T) {
+	_, code := setupFakeLambda(t)
+	path := filepath.Join(t.TempDir(), "rollback.log")
+	lines := []string{
+		(&cmd.RollbackLog{Timestamp: time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC), Env: "prod", FromVersion: "5", ToVersion: "4", Reason: "latency", Operator: "alice"}).Format(),
+		(&cmd.RollbackLog{Timestamp: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC), Env: "prod", FromVersion: "7", ToVersion: "6", Reason: "errors", Operator: "bob", Action: cmd.RollbackActionAbort}).Format(),
+		`{"timestamp":"2026-01-03T10:00:00Z","started_at":"2026-01-03T10:00:00Z","command":"promote","env":"test","operator":"carol","outcome":"success","exit_code":0}`,
+		"not a record",
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	report := historyReport(t, code, "--file", path, "--env", "prod")
+	if report.Total != 3 || report.Skipped != 1 {
+		t.Errorf("total/skipped = %d/%d, want 3/1", report.Total, report.Skipped)
+	}
+	if len(report.Records) != 2 || report.Records[0].Reason != "latency" || report.Records[1].Flags["action"] != "abort" {
+		t.Fatalf("records = %+v, want rollback then abort", report.Records)
+	}
+	if got := report.Records[0].After["live"].Version; got != "4" {
+		t.Errorf("rollback after live = %q, want %q", got, "4")
+	}
+	if got := historyReport(t, code, "--file", path, "--since", "2026-01-02T00:00:00Z", "--operator", "bob").Records; len(got) != 1 {
+		t.Errorf("--since --operator bob = %+v, want the abort", got)
+	}
+
+	// 旧格式的回退日志没有函数名，指定 --function 时同样显示
+	if got := historyReport(t, code, "--file", path, "--env", "prod", "--function", "demo-function").Records; len(got) != 2 {
+		t.Errorf("--function = %+v, want the legacy records", got)
+	}
+	if _, out := runHistory(t, code, "--file", path, "--env", "prod", "--function", "demo-function"); !strings.Contains(out, "其中 2 条记录没有函数名") {
+		t.Errorf("history --function output should note the records without function, got:\n%s", out)
+	}
+
+	_, out := runHistory(t, code, "--file", path, "--env", "prod")
+	for _, want := range []string{"环境: prod", "rollback (abort)", "live: 5 -> 4", "latest: 7 -> 6", "1 行无法解析"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("history output should contain %q, got:\n%s", want, out)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"--file", filepath.Join(t.TempDir(), "missing.log")},
+		{"--file", path, "--since", "yesterday"},
+		{"--file", path, "--output", "yaml"},
+	} {
+		if c, _ := runHistory(t, code, args...); c != exitcode.ParamError {
+			t.Errorf("history %v exit code = %d, want %d", args, c, exitcode.ParamError)
+		}
+	}
+}
+
+func TestHistory_RollbackNotDuplicated(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	promoteVersions(t, fake, code, 1) // live 2, previous 1
+	cmd.SetRecordDir(t.TempDir())
+	// 可执行文件所在目录可能有旧版本写入的 rollback.log，按操作人区分
+	t.Setenv("USER", "history-dedupe")
+
+	if c := runLad(t, code, "rollback", "--env", "test", "--reason", "errors"); c != exitcode.Success {
+		t.Fatalf("rollback exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	// rollback 同时写入审计日志和 rollback.log，历史中只显示一次
+	report := historyReport(t, code, "--operator", "history-dedupe")
+	if len(report.Records) != 1 || report.Records[0].Command != "rollback" || report.Records[0].Function != lifecycleFunction {
+		t.Fatalf("records = %+v, want a single rollback from the audit log", report.Records)
+	}
+	if got := report.Records[0].After["live"].Version; got != "1" {
+		t.Errorf("rollback after live = %q, want %q", got, "1")
+	}
+}
@@ -0,0 +1,198 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// setupUnmanagedFunction 创建未通过打补丁的模板部署的函数
+// 发布 versions 个版本，只创建 aliases 中的别名（指向版本 1）
+func setupUnmanagedFunction(t *testing.T, versions int, aliases ...string) (*aws.FakeLambda, *int) {
+	t.Helper()
+
+	fake, code := setupFakeLambda(t)
+	fake.CreateFunction(lifecycleFunction)
+	client := aws.NewClientWithAPI(fake)
+	ctx := context.Background()
+	for i := 0; i < versions; i++ {
+		fake.ChangeCode(lifecycleFunction)
+		if _, err := client.CreateVersion(ctx, lifecycleFunction, ""); err != nil {
+			t.Fatalf("CreateVersion() unexpected error: %v", err)
+		}
+	}
+	for _, alias := range aliases {
+		if err := client.CreateAlias(ctx, lifecycleFunction, alias, "1", ""); err != nil {
+			t.Fatalf("CreateAlias(%s) unexpected error: %v", alias, err)
+		}
+	}
+	return fake, code
+}
+
+// aliasExists 判断别名是否存在
+func aliasExists(t *testing.T, fake *aws.FakeLambda, alias string) bool {
+	t.Helper()
+
+	_, err := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, alias)
+	return err == nil
+}
+
+func TestInitAliases_FromLive(t *testing.T) {
+	fake, code := setupUnmanagedFunction(t, 2, "live")
+
+	// 缺失 live 以外的别名时，canary 提示使用 init-aliases 并返回 ResourceNotFound
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.ResourceNotFound {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.ResourceNotFound)
+	}
+
+	// 未指定版本时使用 live 的版本
+	if c := runLad(t, code, "init-aliases", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("init-aliases exit code = %d, want %d", c, exitcode.Success)
+	}
+	for _, alias := range []string{"live", "previous", "latest"} {
+		if got := aliasVersion(t, fake, alias); got != "1" {
+			t.Errorf("%s = %q, want %q", alias, got, "1")
+		}
+	}
+
+	// 再次执行不做任何修改
+	before, _ := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, "latest")
+	if c := runLad(t, code, "init-aliases", "--env", "test", "--version", "2"); c != exitcode.Success {
+		t.Fatalf("second init-aliases exit code = %d, want %d", c, exitcode.Success)
+	}
+	after, _ := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, "latest")
+	if after.Version != "1" || after.RevisionID != before.RevisionID {
+		t.Errorf("second init-aliases modified latest: %+v -> %+v", before, after)
+	}
+
+	// 初始化后可以正常走发布流程
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Fatalf("canary after init-aliases exit code = %d, want %d", c, exitcode.Success)
+	}
+}
+
+func TestInitAliases_ExistingAliasesKept(t *testing.T) {
+	fake, code := setupUnmanagedFunction(t, 3, "live", "previous")
+
+	if c := runLad(t, code, "init-aliases", "--env", "test", "--version", "3"); c != exitcode.Success {
+		t.Fatalf("init-aliases exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q (unchanged)", got, "1")
+	}
+	if got := aliasVersion(t, fake, "previous"); got != "1" {
+		t.Errorf("previous = %q, want %q (unchanged)", got, "1")
+	}
+	if got := aliasVersion(t, fake, "latest"); got != "3" {
+		t.Errorf("latest = %q, want %q", got, "3")
+	}
+}
+
+func TestInitAliases_Publish(t *testing.T) {
+	fake, code := setupUnmanagedFunction(t, 0)
+
+	// live 不存在时必须指定版本
+	if c := runLad(t, code, "init-aliases", "--env", "test"); c != exitcode.ParamError {
+		t.Fatalf("init-aliases without version exit code = %d, want %d", c, exitcode.ParamError)
+	}
+
+	// dry-run 不发布版本也不创建别名
+	if c := runLad(t, code, "init-aliases", "--env", "test", "--publish", "--dry-run"); c != exitcode.Success {
+		t.Fatalf("init-aliases --dry-run exit code = %d, want %d", c, exitcode.Success)
+	}
+	if aliasExists(t, fake, "live") {
+		t.Fatal("dry-run should not create aliases")
+	}
+	if got := publishedVersions(t, fake); got != "" {
+		t.Fatalf("dry-run published versions %q, want none", got)
+	}
+
+	if c := runLad(t, code, "init-aliases", "--env", "test", "--publish"); c != exitcode.Success {
+		t.Fatalf("init-aliases --publish exit code = %d, want %d", c, exitcode.Success)
+	}
+	for _, alias := range []string{"live", "previous", "latest"} {
+		if got := aliasVersion(t, fake, alias); got != "1" {
+			t.Errorf("%s = %q, want %q", alias, got, "1")
+		}
+	}
+}
+
+func TestInitAliases_InvalidFlags(t *testing.T) {
+	fake, code := setupUnmanagedFunction(t, 1)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"version and publish", []string{"--version", "1", "--publish"}, exitcode.ParamError},
+		{"latest qualifier", []string{"--version", "$LATEST"}, exitcode.ParamError},
+		{"unknown version", []string{"--version", "9"}, exitcode.ResourceNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"init-aliases", "--env", "test"}, tt.args...)
+			if c := runLad(t, code, args...); c != tt.want {
+				t.Errorf("exit code = %d, want %d", c, tt.want)
+			}
+		})
+	}
+	if aliasExists(t, fake, "live") {
+		t.Error("invalid flags should not create aliases")
+	}
+}
+
+func TestInitAliases_MissingFunction(t *testing.T) {
+	_, code := setupFakeLambda(t)
+
+	*code = exitcode.Success
+	if err := cmd.ExecuteArgs([]string{"init-aliases", "--env", "test", "--dry-run", "--function", "missing-function"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *code != exitcode.ResourceNotFound {
+		t.Errorf("exit code = %d, want %d", *code, exitcode.ResourceNotFound)
+	}
+}
+
+// creatingLambda 在 CreateAlias 之前由"其他操作"创建同名别名
+type creatingLambda struct {
+	*aws.FakeLambda
+	alias   string
+	version string
+}
+
+func (c *creatingLambda) CreateAlias(ctx context.Context, params *lambda.CreateAliasInput, optFns ...func(*lambda.Options)) (*lambda.CreateAliasOutput, error) {
+	if sdkaws.ToString(params.Name) == c.alias {
+		c.FakeLambda.CreateAlias(ctx, &lambda.CreateAliasInput{
+			FunctionName:    params.FunctionName,
+			Name:            params.Name,
+			FunctionVersion: sdkaws.String(c.version),
+		})
+	}
+	return c.FakeLambda.CreateAlias(ctx, params, optFns...)
+}
+
+func TestInitAliases_ConcurrentCreate(t *testing.T) {
+	fake, code := setupUnmanagedFunction(t, 2, "live")
+	racing := &creatingLambda{FakeLambda: fake, alias: "previous", version: "2"}
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		return aws.NewClientWithAPI(racing), nil
+	})
+
+	if c := runLad(t, code, "init-aliases", "--env", "test"); c != exitcode.StateChanged {
+		t.Fatalf("init-aliases exit code = %d, want %d", c, exitcode.StateChanged)
+	}
+	// 其他操作创建的别名不被覆盖，其余别名照常创建
+	if got := aliasVersion(t, fake, "previous"); got != "2" {
+		t.Errorf("previous = %q, want %q (created concurrently)", got, "2")
+	}
+	if got := aliasVersion(t, fake, "latest"); got != "1" {
+		t.Errorf("latest = %q, want %q", got, "1")
+	}
+}
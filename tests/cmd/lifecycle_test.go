@@ -0,0 +1,390 @@
+package cmd_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/lock"
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+)
+
+// =============================================================================
+// End-to-end lifecycle tests backed by FakeLambda
+// =============================================================================
+
+const lifecycleFunction = "demo-function"
+
+// setupFakeLambda 将命令的 Lambda 客户端替换为内存实现，并捕获退出码
+func setupFakeLambda(t *testing.T) (*aws.FakeLambda, *int) {
+	t.Helper()
+
+	fake := aws.NewFakeLambda()
+	code := exitcode.Success
+
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		return aws.NewClientWithAPI(fake), nil
+	})
+	cmd.SetExitFunc(func(c int) {
+		// 只记录第一次退出，后续代码不会再执行有意义的操作
+		if code == exitcode.Success {
+			code = c
+		}
+	})
+	// deploy 和 reconcile 的本地记录写入临时目录
+	cmd.SetRecordDir(t.TempDir())
+	// 部署锁写入后不等待回读，测试中只有一个操作者
+	lock.SetSettleDelay(0)
+	t.Cleanup(func() {
+		cmd.SetClientFactory(nil)
+		cmd.SetExitFunc(nil)
+		cmd.SetRecordDir(".lad")
+		lock.SetSettleDelay(-1)
+	})
+
+	return fake, &code
+}
+
+// runLad 执行 lad 命令，返回退出码
+func runLad(t *testing.T, code *int, args ...string) int {
+	t.Helper()
+
+	*code = exitcode.Success
+	args = append(args, "--function", lifecycleFunction)
+	if err := cmd.ExecuteArgs(args); err != nil {
+		t.Fatalf("lad %v: unexpected error: %v", args, err)
+	}
+	return *code
+}
+
+// aliasVersion 读取别名当前版本
+func aliasVersion(t *testing.T, fake *aws.FakeLambda, alias string) string {
+	t.Helper()
+
+	state, err := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, alias)
+	if err != nil {
+		t.Fatalf("GetAliasState(%s) unexpected error: %v", alias, err)
+	}
+	return state.Version
+}
+
+// canaryState 读取 live 别名的灰度配置
+func canaryState(t *testing.T, fake *aws.FakeLambda) (bool, string, float64) {
+	t.Helper()
+
+	state, err := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, "live")
+	if err != nil {
+		t.Fatalf("GetAliasState(live) unexpected error: %v", err)
+	}
+	version, weight, ok := state.Canary()
+	return ok, version, weight
+}
+
+func TestLifecycle_DeployCanaryPromoteRollback(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+
+	// 首次部署: v1
+	if _, err := fake.Deploy(lifecycleFunction); err != nil {
+		t.Fatalf("Deploy() unexpected error: %v", err)
+	}
+	// 新版本部署: latest -> v2
+	if _, err := fake.Deploy(lifecycleFunction); err != nil {
+		t.Fatalf("Deploy() unexpected error: %v", err)
+	}
+
+	// canary 10%
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+	active, version, weight := canaryState(t, fake)
+	if !active || version != "2" || weight != 0.1 {
+		t.Fatalf("after canary: (%v, %q, %v), want (true, \"2\", 0.1)", active, version, weight)
+	}
+
+	// promote
+	if c := runLad(t, code, "promote", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("promote exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "2" {
+		t.Errorf("after promote: live = %q, want %q", got, "2")
+	}
+	if got := aliasVersion(t, fake, "previous"); got != "1" {
+		t.Errorf("after promote: previous = %q, want %q", got, "1")
+	}
+	if active, _, _ := canaryState(t, fake); active {
+		t.Error("after promote: canary should be cleared")
+	}
+
+	// rollback
+	if c := runLad(t, code, "rollback", "--env", "test", "--reason", "lifecycle test"); c != exitcode.Success {
+		t.Fatalf("rollback exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("after rollback: live = %q, want %q", got, "1")
+	}
+	if got := aliasVersion(t, fake, "latest"); got != "1" {
+		t.Errorf("after rollback: latest = %q, want %q", got, "1")
+	}
+
+	// 再次 rollback: live == previous，没有可回退的版本
+	if c := runLad(t, code, "rollback", "--env", "test"); c != exitcode.ParamError {
+		t.Errorf("second rollback exit code = %d, want %d", c, exitcode.ParamError)
+	}
+}
+
+func TestLifecycle_CanaryWithoutNewVersion(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+
+	// live == latest 时跳过 canary，不修改路由配置
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+	if active, _, _ := canaryState(t, fake); active {
+		t.Error("canary should not be configured when live == latest")
+	}
+}
+
+func TestLifecycle_SwitchToUnknownVersion(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+
+	if c := runLad(t, code, "switch", "--env", "test", "--version", "42"); c != exitcode.ResourceNotFound {
+		t.Errorf("switch exit code = %d, want %d", c, exitcode.ResourceNotFound)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+}
+
+func TestLifecycle_MissingFunction(t *testing.T) {
+	_, code := setupFakeLambda(t)
+
+	if c := runLad(t, code, "promote", "--env", "test"); c != exitcode.ResourceNotFound {
+		t.Errorf("promote exit code = %d, want %d", c, exitcode.ResourceNotFound)
+	}
+}
+
+func TestLifecycle_ClientErrorExitCode(t *testing.T) {
+	_, code := setupFakeLambda(t)
+
+	// 客户端创建失败时使用 *aws.Error 中的退出码
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		return nil, &aws.Error{Op: "LoadConfig", Code: exitcode.Forbidden, Err: errors.New("profile not found")}
+	})
+
+	if c := runLad(t, code, "status", "--env", "test"); c != exitcode.Forbidden {
+		t.Errorf("status exit code = %d, want %d", c, exitcode.Forbidden)
+	}
+}
+
+func TestLifecycle_RetryFlags(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+
+	var got aws.RetryPolicy
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		got = opts.Retry
+		return aws.NewClientWithAPI(fake), nil
+	})
+
+	if c := runLad(t, code, "status", "--env", "test", "--retry-max-attempts", "2", "--retry-max-elapsed", "30s"); c != exitcode.Success {
+		t.Fatalf("status exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got.MaxAttempts != 2 || got.MaxElapsed != 30*time.Second {
+		t.Errorf("retry policy = {MaxAttempts: %d, MaxElapsed: %v}, want {2, 30s}", got.MaxAttempts, got.MaxElapsed)
+	}
+
+	if c := runLad(t, code, "status", "--env", "test", "--retry-max-attempts", "0"); c != exitcode.ParamError {
+		t.Errorf("status with --retry-max-attempts 0 exit code = %d, want %d", c, exitcode.ParamError)
+	}
+}
+
+// racingLambda 在第一次 UpdateAlias 前模拟另一个操作者修改 live 别名
+type racingLambda struct {
+	*aws.FakeLambda
+	raced bool
+}
+
+func (r *racingLambda) UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error) {
+	if !r.raced {
+		r.raced = true
+		_, err := r.FakeLambda.UpdateAlias(ctx, &lambda.UpdateAliasInput{
+			FunctionName:  sdkaws.String(lifecycleFunction),
+			Name:          sdkaws.String("live"),
+			RoutingConfig: &types.AliasRoutingConfiguration{AdditionalVersionWeights: map[string]float64{"2": 0.5}},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r.FakeLambda.UpdateAlias(ctx, params, optFns...)
+}
+
+func TestLifecycle_ConcurrentModification(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	racing := &racingLambda{FakeLambda: fake}
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		return aws.NewClientWithAPI(racing), nil
+	})
+
+	// canary 读取 live 后，另一个操作者把灰度改成了 50%
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.StateChanged {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.StateChanged)
+	}
+
+	// 另一个操作者的修改没有被覆盖
+	if active, version, weight := canaryState(t, fake); !active || version != "2" || weight != 0.5 {
+		t.Errorf("after conflicting canary: (%v, %q, %v), want (true, \"2\", 0.5)", active, version, weight)
+	}
+}
+
+// deniedLambda 对指定别名的 GetAlias 调用返回权限错误
+type deniedLambda struct {
+	*aws.FakeLambda
+	alias string
+}
+
+func (d *deniedLambda) GetAlias(ctx context.Context, params *lambda.GetAliasInput, optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error) {
+	if sdkaws.ToString(params.Name) == d.alias {
+		return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "User is not authorized to perform: lambda:GetAlias"}
+	}
+	return d.FakeLambda.GetAlias(ctx, params, optFns...)
+}
+
+func TestLifecycle_StatusUnreadableAlias(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		return aws.NewClientWithAPI(&deniedLambda{FakeLambda: fake, alias: "live"}), nil
+	})
+
+	// 无法读取的别名不再显示为"未配置"或"无灰度"
+	if c := runLad(t, code, "status", "--env", "test"); c != exitcode.Forbidden {
+		t.Errorf("status exit code = %d, want %d", c, exitcode.Forbidden)
+	}
+	if c := runLad(t, code, "promote", "--env", "test"); c != exitcode.Forbidden {
+		t.Errorf("promote exit code = %d, want %d", c, exitcode.Forbidden)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live after denied promote = %q, want %q", got, "1")
+	}
+}
+
+// updateDeniedLambda 对把指定别名更新到指定版本的 UpdateAlias 调用返回权限错误
+type updateDeniedLambda struct {
+	*aws.FakeLambda
+	alias   string
+	version string
+}
+
+func (d *updateDeniedLambda) UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error) {
+	if sdkaws.ToString(params.Name) == d.alias && sdkaws.ToString(params.FunctionVersion) == d.version {
+		return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "User is not authorized to perform: lambda:UpdateAlias"}
+	}
+	return d.FakeLambda.UpdateAlias(ctx, params, optFns...)
+}
+
+func TestLifecycle_PromoteLiveUpdateFails(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"promote", []string{"promote"}},
+		{"auto", []string{"auto", "--percent", "50", "--wait", "0s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, code := setupFakeLambda(t)
+			promoteVersions(t, fake, code, 1) // live 2, previous 1
+			fake.Deploy(lifecycleFunction)
+			cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+				return aws.NewClientWithAPI(&updateDeniedLambda{FakeLambda: fake, alias: "live", version: "3"}), nil
+			})
+
+			// previous 已更新后 live 更新失败，报告只更新了一半的状态
+			out := captureStdout(t, func() {
+				if c := runLad(t, code, append(tt.args, "--env", "test")...); c != exitcode.Forbidden {
+					t.Errorf("%s exit code = %d, want %d", tt.name, c, exitcode.Forbidden)
+				}
+			})
+			for _, want := range []string{"previous 别名已更新到版本 2，live 别名未更新", "重新执行 'lad promote --env test'"} {
+				if !strings.Contains(out, want) {
+					t.Errorf("output should contain %q, got:\n%s", want, out)
+				}
+			}
+			for alias, want := range map[string]string{"live": "2", "previous": "2", "latest": "3"} {
+				if got := aliasVersion(t, fake, alias); got != want {
+					t.Errorf("%s = %q, want %q", alias, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLifecycle_RoutingModifiedOutsideLad(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	// 在 lad 之外把灰度指向了不是 latest 的版本
+	_, err := fake.UpdateAlias(context.Background(), &lambda.UpdateAliasInput{
+		FunctionName:  sdkaws.String(lifecycleFunction),
+		Name:          sdkaws.String("live"),
+		RoutingConfig: &types.AliasRoutingConfiguration{AdditionalVersionWeights: map[string]float64{"2": 0.1}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateAlias() unexpected error: %v", err)
+	}
+
+	if c := runLad(t, code, "status", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("status exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	// promote 切换到 latest 并清除全部路由
+	if c := runLad(t, code, "promote", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("promote exit code = %d, want %d", c, exitcode.Success)
+	}
+	state, err := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, "live")
+	if err != nil {
+		t.Fatalf("GetAliasState(live) unexpected error: %v", err)
+	}
+	if state.Version != "3" || state.HasRouting() {
+		t.Errorf("live after promote = %+v, want version 3 without routing", state)
+	}
+	if got := aliasVersion(t, fake, "previous"); got != "1" {
+		t.Errorf("previous after promote = %q, want %q", got, "1")
+	}
+}
+
+func TestLifecycle_RegionAndEndpointFlags(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+
+	var got aws.ClientOptions
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		got = opts
+		return aws.NewClientWithAPI(fake), nil
+	})
+
+	if c := runLad(t, code, "status", "--env", "test", "--region", "eu-west-1", "--endpoint-url", "http://127.0.0.1:4575"); c != exitcode.Success {
+		t.Fatalf("status exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got.Region != "eu-west-1" || got.EndpointURL != "http://127.0.0.1:4575" {
+		t.Errorf("client options = {Region: %q, EndpointURL: %q}, want {eu-west-1, http://127.0.0.1:4575}", got.Region, got.EndpointURL)
+	}
+}
@@ -0,0 +1,191 @@
+package cmd_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/lock"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+// holdLock 以其他操作者的身份持有函数标签中的部署锁
+func holdLock(t *testing.T, fake *aws.FakeLambda) *lock.Lease {
+	t.Helper()
+
+	store := lock.NewTagStore(aws.NewClientWithAPI(fake), lifecycleFunction)
+	lease, err := lock.Acquire(context.Background(), store, "bob@laptop", "auto", "test", time.Hour)
+	if err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+	return lease
+}
+
+// lockTags 返回函数上部署锁使用的标签
+func lockTags(t *testing.T, fake *aws.FakeLambda) map[string]string {
+	t.Helper()
+
+	tags, err := aws.NewClientWithAPI(fake).FunctionTags(context.Background(), lifecycleFunction)
+	if err != nil {
+		t.Fatalf("FunctionTags() unexpected error: %v", err)
+	}
+	result := make(map[string]string)
+	for k, v := range tags {
+		if strings.HasPrefix(k, "lad:lock:") {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func TestLock_Contended(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	holdLock(t, fake)
+
+	for _, args := range [][]string{
+		{"canary", "--percent", "10"},
+		{"promote"},
+		{"rollback"},
+		{"switch", "--version", "2"},
+	} {
+		if c := runLad(t, code, append(args, "--env", "test", "--lock", "tags")...); c != exitcode.Locked {
+			t.Errorf("%s exit code = %d, want %d", args[0], c, exitcode.Locked)
+		}
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("live should not have a canary configured")
+	}
+
+	// 只读命令和 --lock none 不受锁影响
+	if c := runLad(t, code, "status", "--env", "test"); c != exitcode.Success {
+		t.Errorf("status exit code = %d, want %d", c, exitcode.Success)
+	}
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10", "--lock", "none"); c != exitcode.Success {
+		t.Errorf("canary --lock none exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	// 解除后可以执行，锁的持有者不变直到被解除
+	if tags := lockTags(t, fake); tags["lad:lock:holder"] != "bob@laptop" {
+		t.Errorf("lock holder = %q, want %q", tags["lad:lock:holder"], "bob@laptop")
+	}
+	if c := runLad(t, code, "lock", "break", "--env", "test", "--lock", "tags"); c != exitcode.Success {
+		t.Fatalf("lock break exit code = %d, want %d", c, exitcode.Success)
+	}
+	if c := runLad(t, code, "promote", "--env", "test", "--lock", "tags"); c != exitcode.Success {
+		t.Errorf("promote exit code = %d, want %d", c, exitcode.Success)
+	}
+}
+
+func TestLock_ReleasedAfterCommand(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10", "--lock", "tags"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+	if tags := lockTags(t, fake); len(tags) != 0 {
+		t.Errorf("lock tags after canary = %v, want none", tags)
+	}
+
+	// 命令失败退出时同样释放锁
+	if c := runLad(t, code, "switch", "--env", "test", "--version", "42", "--lock", "tags"); c != exitcode.ResourceNotFound {
+		t.Fatalf("switch exit code = %d, want %d", c, exitcode.ResourceNotFound)
+	}
+	if tags := lockTags(t, fake); len(tags) != 0 {
+		t.Errorf("lock tags after failed switch = %v, want none", tags)
+	}
+}
+
+func TestLock_FileBackend(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	dir := t.TempDir()
+	cmd.SetRecordDir(dir)
+
+	// 其他函数的锁不影响当前函数
+	other := lock.NewFileStore(filepath.Join(dir, "lock-test-other-function.json"))
+	if _, err := lock.Acquire(context.Background(), other, "ci@runner", "deploy", "test", time.Hour); err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+	store := lock.NewFileStore(filepath.Join(dir, "lock-test-"+lifecycleFunction+".json"))
+	if _, err := lock.Acquire(context.Background(), store, "ci@runner", "deploy", "test", time.Hour); err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+	if c := runLad(t, code, "promote", "--env", "test", "--lock", "file"); c != exitcode.Locked {
+		t.Errorf("promote exit code = %d, want %d", c, exitcode.Locked)
+	}
+	// 默认使用文件存储
+	if c := runLad(t, code, "promote", "--env", "test"); c != exitcode.Locked {
+		t.Errorf("promote without --lock exit code = %d, want %d", c, exitcode.Locked)
+	}
+
+	// 函数标签中没有锁，标签存储不受影响
+	if c := runLad(t, code, "lock", "status", "--env", "test", "--lock", "tags"); c != exitcode.Success {
+		t.Errorf("lock status exit code = %d, want %d", c, exitcode.Success)
+	}
+	if c := runLad(t, code, "lock", "break", "--env", "test", "--lock", "file"); c != exitcode.Success {
+		t.Fatalf("lock break exit code = %d, want %d", c, exitcode.Success)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "lock-test-"+lifecycleFunction+".json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file after break: %v, want not exist", err)
+	}
+	if c := runLad(t, code, "promote", "--env", "test", "--lock", "file"); c != exitcode.Success {
+		t.Errorf("promote exit code = %d, want %d", c, exitcode.Success)
+	}
+	if c := runLad(t, code, "promote", "--env", "test", "--lock", "redis"); c != exitcode.ParamError {
+		t.Errorf("promote --lock redis exit code = %d, want %d", c, exitcode.ParamError)
+	}
+}
+
+// breakingLambda 在第一次配置灰度后由"其他操作者"强制解除部署锁
+type breakingLambda struct {
+	*aws.FakeLambda
+	t      *testing.T
+	broken bool
+}
+
+func (b *breakingLambda) UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error) {
+	out, err := b.FakeLambda.UpdateAlias(ctx, params, optFns...)
+	if err == nil && !b.broken {
+		b.broken = true
+		if _, err := lock.Break(ctx, lock.NewTagStore(aws.NewClientWithAPI(b.FakeLambda), lifecycleFunction)); err != nil {
+			b.t.Fatalf("Break() unexpected error: %v", err)
+		}
+	}
+	return out, err
+}
+
+func TestLock_AutoStopsWhenLeaseLost(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	breaking := &breakingLambda{FakeLambda: fake, t: t}
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		return aws.NewClientWithAPI(breaking), nil
+	})
+
+	// 第一步灰度后续期失败，auto 停止且不执行 promote
+	if c := runLad(t, code, "auto", "--env", "test", "--percent", "50", "--wait", "0s", "--lock", "tags"); c != exitcode.Locked {
+		t.Fatalf("auto exit code = %d, want %d", c, exitcode.Locked)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+	if got := aliasVersion(t, fake, "previous"); got != "1" {
+		t.Errorf("previous = %q, want %q", got, "1")
+	}
+}
@@ -0,0 +1,133 @@
+package cmd_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+)
+
+// publishedVersions 返回函数当前的版本号（从新到旧，逗号分隔）
+func publishedVersions(t *testing.T, fake *aws.FakeLambda) string {
+	t.Helper()
+
+	versions, err := aws.NewClientWithAPI(fake).ListVersions(context.Background(), lifecycleFunction)
+	if err != nil {
+		t.Fatalf("ListVersions() unexpected error: %v", err)
+	}
+	var got []string
+	for _, v := range versions {
+		got = append(got, v.Version)
+	}
+	return strings.Join(got, ",")
+}
+
+// setupPruneFunction 创建 8 个版本：live=2 (灰度 3 10%)，previous=1，latest=8
+// 版本 1-5 创建于 10 天前，6-8 创建于 1 小时前
+func setupPruneFunction(t *testing.T) (*aws.FakeLambda, *int) {
+	t.Helper()
+
+	fake, code := setupFakeLambda(t)
+	now := time.Now()
+	fake.SetClock(func() time.Time { return now.Add(-240 * time.Hour) })
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	fake.SetClock(func() time.Time { return now.Add(-time.Hour) })
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	client := aws.NewClientWithAPI(fake)
+	if _, code := client.ConfigureCanary(context.Background(), lifecycleFunction, "live", "2", "3", 0.1, ""); code != exitcode.Success {
+		t.Fatalf("ConfigureCanary() exit code = %d", code)
+	}
+	return fake, code
+}
+
+func TestPrune_DryRun(t *testing.T) {
+	fake, code := setupPruneFunction(t)
+
+	var c int
+	out := captureStdout(t, func() {
+		c = runLad(t, code, "prune", "--env", "test", "--keep-last", "2", "--dry-run")
+	})
+	if c != exitcode.Success {
+		t.Fatalf("prune --dry-run exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := publishedVersions(t, fake); got != "8,7,6,5,4,3,2,1" {
+		t.Errorf("versions after dry-run = %s, want all versions kept", got)
+	}
+	for _, want := range []string{"dry-run 完成", "账户代码存储"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("prune --dry-run output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrune_RetentionRules(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		// 8 (latest)、7 (最近 2 个)、3 (灰度)、2 (live)、1 (previous)
+		{"keep last", []string{"--keep-last", "2"}, "8,7,3,2,1"},
+		// 同时保留 1 小时前创建的 6、7
+		{"keep newer", []string{"--keep-last", "1", "--keep-newer", "24h"}, "8,7,6,3,2,1"},
+		// 保留数量覆盖全部版本
+		{"keep all", []string{"--keep-last", "10"}, "8,7,6,5,4,3,2,1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, code := setupPruneFunction(t)
+			if c := runLad(t, code, append([]string{"prune", "--env", "test"}, tt.args...)...); c != exitcode.Success {
+				t.Fatalf("prune %v exit code = %d, want %d", tt.args, c, exitcode.Success)
+			}
+			if got := publishedVersions(t, fake); got != tt.want {
+				t.Errorf("versions after prune %v = %s, want %s", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrune_InvalidFlags(t *testing.T) {
+	_, code := setupPruneFunction(t)
+
+	for _, args := range [][]string{
+		{"--keep-last", "0"},
+		{"--keep-newer", "-1h"},
+	} {
+		if c := runLad(t, code, append([]string{"prune", "--env", "test"}, args...)...); c != exitcode.ParamError {
+			t.Errorf("prune %v exit code = %d, want %d", args, c, exitcode.ParamError)
+		}
+	}
+}
+
+func TestPrune_KeepsReleaseHistory(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	promoteVersions(t, fake, code, 4) // live 5, 发布历史 5 4 3 2 1
+	fake.Deploy(lifecycleFunction)    // latest 6
+
+	// 发布历史中的版本是 rollback --steps/--to 的回退目标，不被删除
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "prune", "--env", "test", "--keep-last", "1"); c != exitcode.Success {
+			t.Errorf("prune exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	if got := publishedVersions(t, fake); got != "6,5,4,3,2,1" {
+		t.Errorf("versions after prune = %s, want all versions in the release history kept", got)
+	}
+	if !strings.Contains(out, "版本 2: 发布历史") {
+		t.Errorf("prune output should explain the release history, got:\n%s", out)
+	}
+	if c := runLad(t, code, "rollback", "--env", "test", "--steps", "3"); c != exitcode.Success {
+		t.Errorf("rollback --steps 3 after prune exit code = %d, want %d", c, exitcode.Success)
+	}
+}
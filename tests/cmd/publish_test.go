@@ -0,0 +1,138 @@
+package cmd_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+)
+
+// setupPublish 部署版本 1（live = previous = latest = 1），函数更新需要两次查询才完成
+func setupPublish(t *testing.T) (*aws.FakeLambda, *int) {
+	t.Helper()
+
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.SetUpdateDelay(2)
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		client := aws.NewClientWithAPI(fake)
+		client.SetPollInterval(time.Millisecond)
+		return client, nil
+	})
+	return fake, code
+}
+
+// writeZip 写入部署包并返回路径
+func writeZip(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "function.zip")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write zip file: %v", err)
+	}
+	return path
+}
+
+func TestPublish_ZipFile(t *testing.T) {
+	fake, code := setupPublish(t)
+	zip := writeZip(t, "new code")
+
+	if c := runLad(t, code, "publish", "--env", "test", "--zip-file", zip, "--description", "abc1234"); c != exitcode.Success {
+		t.Fatalf("publish exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := aliasVersion(t, fake, "latest"); got != "2" {
+		t.Errorf("latest = %q, want %q", got, "2")
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+
+	versions, err := aws.NewClientWithAPI(fake).ListVersions(context.Background(), lifecycleFunction)
+	if err != nil {
+		t.Fatalf("ListVersions() unexpected error: %v", err)
+	}
+	if versions[0].Version != "2" || versions[0].Description != "abc1234" {
+		t.Errorf("published version = %+v, want version 2 with description abc1234", versions[0])
+	}
+
+	// 发布的版本可以继续灰度
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Errorf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+}
+
+func TestPublish_NoCodeChange(t *testing.T) {
+	fake, code := setupPublish(t)
+
+	// $LATEST 与版本 1 相同，PublishVersion 返回已有版本
+	if c := runLad(t, code, "publish", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("publish exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := publishedVersions(t, fake); got != "1" {
+		t.Errorf("versions = %q, want %q", got, "1")
+	}
+	if got := aliasVersion(t, fake, "latest"); got != "1" {
+		t.Errorf("latest = %q, want %q", got, "1")
+	}
+}
+
+func TestPublish_PendingRelease(t *testing.T) {
+	fake, code := setupPublish(t)
+	fake.Deploy(lifecycleFunction)
+
+	// latest (2) 尚未发布到 live (1)
+	if c := runLad(t, code, "publish", "--env", "test", "--zip-file", writeZip(t, "new code")); c != exitcode.ParamError {
+		t.Fatalf("publish exit code = %d, want %d", c, exitcode.ParamError)
+	}
+	if got := publishedVersions(t, fake); got != "2,1" {
+		t.Errorf("versions = %q, want %q", got, "2,1")
+	}
+}
+
+func TestPublish_UpdateFailed(t *testing.T) {
+	fake, code := setupPublish(t)
+	fake.SetUpdateFailure("InvalidImage: image manifest not found")
+
+	if c := runLad(t, code, "publish", "--env", "test", "--image-uri", "repo/app:missing"); c != exitcode.AWSError {
+		t.Fatalf("publish exit code = %d, want %d", c, exitcode.AWSError)
+	}
+	if got := publishedVersions(t, fake); got != "1" {
+		t.Errorf("versions = %q, want %q", got, "1")
+	}
+}
+
+func TestPublish_InvalidFlags(t *testing.T) {
+	_, code := setupPublish(t)
+	zip := writeZip(t, "code")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"zip and image", []string{"--zip-file", zip, "--image-uri", "repo/app:v2"}, exitcode.ParamError},
+		{"missing zip file", []string{"--zip-file", filepath.Join(t.TempDir(), "missing.zip")}, exitcode.ParamError},
+		{"zero wait timeout", []string{"--wait-timeout", "0s"}, exitcode.ParamError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"publish", "--env", "test"}, tt.args...)
+			if c := runLad(t, code, args...); c != tt.want {
+				t.Errorf("exit code = %d, want %d", c, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublish_MissingAliases(t *testing.T) {
+	_, code := setupUnmanagedFunction(t, 1)
+
+	if c := runLad(t, code, "publish", "--env", "test"); c != exitcode.ResourceNotFound {
+		t.Errorf("publish exit code = %d, want %d", c, exitcode.ResourceNotFound)
+	}
+}
@@ -0,0 +1,108 @@
+package cmd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+)
+
+// setupReady 部署两个版本（live = previous = 1，latest = 2），查询间隔为 1ms
+func setupReady(t *testing.T) (*aws.FakeLambda, *int) {
+	t.Helper()
+
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		client := aws.NewClientWithAPI(fake)
+		client.SetPollInterval(time.Millisecond)
+		return client, nil
+	})
+	return fake, code
+}
+
+func TestReady_PendingVersion(t *testing.T) {
+	fake, code := setupReady(t)
+	if err := fake.SetVersionPending(lifecycleFunction, "2", 3); err != nil {
+		t.Fatalf("SetVersionPending() unexpected error: %v", err)
+	}
+
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+	if ok, version, weight := canaryState(t, fake); !ok || version != "2" || weight != 0.1 {
+		t.Errorf("canary = (%v, %q, %v), want (true, \"2\", 0.1)", ok, version, weight)
+	}
+}
+
+func TestReady_FailedVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"canary", []string{"canary", "--percent", "10"}},
+		{"auto", []string{"auto", "--percent", "50", "--wait", "0s"}},
+		{"promote", []string{"promote"}},
+		{"switch", []string{"switch", "--version", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, code := setupReady(t)
+			if err := fake.SetVersionFailed(lifecycleFunction, "2", "SnapStart restore failed"); err != nil {
+				t.Fatalf("SetVersionFailed() unexpected error: %v", err)
+			}
+
+			args := append(tt.args, "--env", "test")
+			if c := runLad(t, code, args...); c != exitcode.VersionNotReady {
+				t.Fatalf("exit code = %d, want %d", c, exitcode.VersionNotReady)
+			}
+
+			// live 和 previous 都没有被修改
+			if got := aliasVersion(t, fake, "live"); got != "1" {
+				t.Errorf("live = %q, want %q", got, "1")
+			}
+			if got := aliasVersion(t, fake, "previous"); got != "1" {
+				t.Errorf("previous = %q, want %q", got, "1")
+			}
+			if ok, _, _ := canaryState(t, fake); ok {
+				t.Error("live should not have a canary configured")
+			}
+		})
+	}
+}
+
+func TestReady_Timeout(t *testing.T) {
+	fake, code := setupReady(t)
+	if err := fake.SetVersionPending(lifecycleFunction, "2", 1000); err != nil {
+		t.Fatalf("SetVersionPending() unexpected error: %v", err)
+	}
+
+	if c := runLad(t, code, "promote", "--env", "test", "--ready-timeout", "20ms"); c != exitcode.VersionNotReady {
+		t.Fatalf("promote exit code = %d, want %d", c, exitcode.VersionNotReady)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+}
+
+func TestReady_ClearCanaryIgnoresLatest(t *testing.T) {
+	fake, code := setupReady(t)
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+	if err := fake.SetVersionFailed(lifecycleFunction, "2", "SnapStart restore failed"); err != nil {
+		t.Fatalf("SetVersionFailed() unexpected error: %v", err)
+	}
+
+	// 清除灰度只涉及 live 当前版本，latest 失败时仍然可以执行
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "0"); c != exitcode.Success {
+		t.Fatalf("canary --percent 0 exit code = %d, want %d", c, exitcode.Success)
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("canary should be cleared")
+	}
+}
@@ -0,0 +1,173 @@
+package cmd_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aura-studio/lad/internal/patcher"
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/smithy-go"
+)
+
+// restoreDeniedLambda 让 deploy 恢复 live 别名时以权限不足失败
+type restoreDeniedLambda struct {
+	*samLambda
+}
+
+func (r *restoreDeniedLambda) UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error) {
+	if sdkaws.ToString(params.Name) == "live" {
+		return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform lambda:UpdateAlias"}
+	}
+	return r.samLambda.UpdateAlias(ctx, params, optFns...)
+}
+
+func TestReconcile_AfterDeploy(t *testing.T) {
+	fake, sam, code := setupDeploy(t, "", nil)
+	fake.Deploy(lifecycleFunction)
+	denied := &restoreDeniedLambda{&samLambda{FakeLambda: fake, t: t, sam: sam, onDeploy: func() { deployFunction(fake) }}}
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		return aws.NewClientWithAPI(denied), nil
+	})
+
+	// deploy 无法恢复 CloudFormation 移动的 live 时保留部署前记录
+	if c := runLad(t, code, "deploy", "--env", "test", "--sam-path", sam.path); c != exitcode.Forbidden {
+		t.Fatalf("deploy exit code = %d, want %d", c, exitcode.Forbidden)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "2" {
+		t.Fatalf("live after deploy = %q, want %q", got, "2")
+	}
+	cmd.SetClientFactory(func(ctx context.Context, opts aws.ClientOptions) (*aws.Client, error) {
+		return aws.NewClientWithAPI(fake), nil
+	})
+
+	// --dry-run 只报告差异
+	if c := runLad(t, code, "reconcile", "--env", "test", "--dry-run"); c != exitcode.Success {
+		t.Fatalf("reconcile --dry-run exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "2" {
+		t.Errorf("live after dry-run = %q, want %q", got, "2")
+	}
+
+	if c := runLad(t, code, "reconcile", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("reconcile exit code = %d, want %d", c, exitcode.Success)
+	}
+	for alias, want := range map[string]string{"live": "1", "previous": "1", "latest": "2"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("%s = %q, want %q", alias, got, want)
+		}
+	}
+
+	// 恢复后记录被删除，可以继续灰度发布
+	if c := runLad(t, code, "reconcile", "--env", "test"); c != exitcode.ParamError {
+		t.Errorf("reconcile without record exit code = %d, want %d", c, exitcode.ParamError)
+	}
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Errorf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+}
+
+func TestReconcile_CleanDeployRemovesRecord(t *testing.T) {
+	fake, sam, code := setupDeploy(t, "", deployFunction)
+	fake.Deploy(lifecycleFunction)
+
+	if c := runLad(t, code, "deploy", "--env", "test", "--sam-path", sam.path); c != exitcode.Success {
+		t.Fatalf("deploy exit code = %d, want %d", c, exitcode.Success)
+	}
+	// 别名状态符合预期时不保留记录，避免之后误恢复
+	if c := runLad(t, code, "reconcile", "--env", "test"); c != exitcode.ParamError {
+		t.Errorf("reconcile exit code = %d, want %d", c, exitcode.ParamError)
+	}
+}
+
+func TestReconcile_Record(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "promote", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("promote exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	// 直接执行 sam deploy 前记录当前状态
+	if c := runLad(t, code, "reconcile", "--env", "test", "--record"); c != exitcode.Success {
+		t.Fatalf("reconcile --record exit code = %d, want %d", c, exitcode.Success)
+	}
+	fake.DeployStack(lifecycleFunction)
+
+	if c := runLad(t, code, "reconcile", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("reconcile exit code = %d, want %d", c, exitcode.Success)
+	}
+	for alias, want := range map[string]string{"live": "2", "previous": "1", "latest": "3"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("%s = %q, want %q", alias, got, want)
+		}
+	}
+}
+
+func TestReconcile_ExplicitVersions(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.DeployStack(lifecycleFunction)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no record", nil, exitcode.ParamError},
+		{"only live", []string{"--live", "1"}, exitcode.ParamError},
+		{"latest qualifier", []string{"--live", "$LATEST", "--previous", "1"}, exitcode.ParamError},
+		{"record with versions", []string{"--record", "--live", "1"}, exitcode.ParamError},
+		{"unknown version", []string{"--live", "9", "--previous", "1"}, exitcode.ResourceNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"reconcile", "--env", "test"}, tt.args...)
+			if c := runLad(t, code, args...); c != tt.want {
+				t.Errorf("exit code = %d, want %d", c, tt.want)
+			}
+			if got := aliasVersion(t, fake, "live"); got != "2" {
+				t.Errorf("live = %q, want %q", got, "2")
+			}
+		})
+	}
+
+	if c := runLad(t, code, "reconcile", "--env", "test", "--live", "1", "--previous", "1"); c != exitcode.Success {
+		t.Fatalf("reconcile exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+}
+
+func TestReconcile_TemplateDrift(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.DeployStack(lifecycleFunction)
+
+	template := filepath.Join(t.TempDir(), "template.yaml")
+	content := "Resources:\n  Function:\n    Type: AWS::Serverless::Function\n" + patcher.GeneratePatchContent("Function")
+	if err := os.WriteFile(template, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	if c := runLad(t, code, "reconcile", "--env", "test", "--live", "1", "--previous", "1", "--template", template); c != exitcode.Success {
+		t.Fatalf("reconcile exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	// 恢复后 CloudFormation 仍认为 live 指向新版本
+	out := captureStdout(t, func() {
+		runLad(t, code, "reconcile", "--env", "test", "--live", "1", "--previous", "1", "--template", template, "--dry-run")
+	})
+	for _, want := range []string{"LiveAlias 的 FunctionVersion 为 !GetAtt FunctionVersion.Version", "CloudFormation 认为 live 指向版本 2，实际为版本 1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
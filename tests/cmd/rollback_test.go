@@ -1,13 +1,18 @@
 package cmd_test
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
 	"pgregory.net/rapid"
 )
 
@@ -100,6 +105,25 @@ func TestRollbackLog_Format(t *testing.T) {
 	}
 }
 
+func TestRollbackLog_Format_Action(t *testing.T) {
+	log := cmd.RollbackLog{
+		Timestamp:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Env:         "prod",
+		FromVersion: "6",
+		ToVersion:   "5",
+		Reason:      "error rate",
+		Operator:    "admin",
+	}
+	if result := log.Format(); strings.Contains(result, "ACTION=") {
+		t.Errorf("Format() without action = %q, should not contain ACTION", result)
+	}
+
+	log.Action = cmd.RollbackActionAbort
+	if result := log.Format(); !strings.HasSuffix(result, "OPERATOR=admin ACTION=abort") {
+		t.Errorf("Format() = %q, should end with %q", result, "OPERATOR=admin ACTION=abort")
+	}
+}
+
 func TestRollbackLog_Format_Structure(t *testing.T) {
 	log := cmd.RollbackLog{
 		Timestamp:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
@@ -455,3 +479,328 @@ func generateOperator(t *rapid.T) string {
 	}
 	return rapid.SampledFrom(operators).Draw(t, "operator")
 }
+
+// =============================================================================
+// Rollback 状态感知测试
+// =============================================================================
+
+// lastRollbackLog 读取本地记录目录中 rollback.log 的最后一行
+func lastRollbackLog(t *testing.T) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(cmd.RecordDir(), "rollback.log"))
+	if err != nil {
+		t.Fatalf("failed to read rollback.log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	return lines[len(lines)-1]
+}
+
+func TestRollback_RecordDirNotWritable(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	promoteVersions(t, fake, code, 1) // live 2, previous 1
+
+	// 本地记录目录无法创建时跳过 rollback.log，不输出警告
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	cmd.SetRecordDir(filepath.Join(blocked, ".lad"))
+
+	out := captureStdout(t, func() {
+		if c := runLad(t, code, "rollback", "--env", "test", "--lock", "none", "--audit-log", "none"); c != exitcode.Success {
+			t.Errorf("rollback exit code = %d, want %d", c, exitcode.Success)
+		}
+	})
+	if strings.Contains(out, "回退日志") {
+		t.Errorf("output should not mention the rollback log, got:\n%s", out)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+}
+
+func TestRollback_AbortCanary(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	if c := runLad(t, code, "rollback", "--env", "test", "--reason", "error rate"); c != exitcode.Success {
+		t.Fatalf("rollback exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	// live 保持稳定版本且没有灰度，latest 重置并标记版本 2
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("canary should be cleared")
+	}
+	for alias, want := range map[string]string{"live": "1", "previous": "1", "latest": "1"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("%s = %q, want %q", alias, got, want)
+		}
+	}
+	latest, err := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, "latest")
+	if err != nil {
+		t.Fatalf("GetAliasState(latest) unexpected error: %v", err)
+	}
+	if rejected, ok := latest.RejectedVersion(); !ok || rejected != "2" {
+		t.Errorf("RejectedVersion() = (%q, %v), want (\"2\", true); description %q", rejected, ok, latest.Description)
+	}
+
+	log := lastRollbackLog(t)
+	for _, want := range []string{"FROM_VERSION=2", "TO_VERSION=1", `REASON="error rate"`, "ACTION=abort"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("rollback log %q should contain %q", log, want)
+		}
+	}
+
+	// 修复后重新部署可以继续发布
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Errorf("canary after redeploy exit code = %d, want %d", c, exitcode.Success)
+	}
+}
+
+func TestRollback_AbortLongReason(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	// 原因超过别名描述的长度限制时截断，latest 仍然重置
+	if c := runLad(t, code, "rollback", "--env", "test", "--reason", strings.Repeat("x", 300)); c != exitcode.Success {
+		t.Fatalf("rollback exit code = %d, want %d", c, exitcode.Success)
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("canary should be cleared")
+	}
+	latest, err := aws.NewClientWithAPI(fake).GetAliasState(context.Background(), lifecycleFunction, "latest")
+	if err != nil {
+		t.Fatalf("GetAliasState(latest) unexpected error: %v", err)
+	}
+	if rejected, ok := latest.RejectedVersion(); latest.Version != "1" || !ok || rejected != "2" {
+		t.Errorf("latest = %q, RejectedVersion() = (%q, %v), want version 1 rejecting 2", latest.Version, rejected, ok)
+	}
+}
+
+func TestRollback_AbortPending(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "promote", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("promote exit code = %d, want %d", c, exitcode.Success)
+	}
+	fake.Deploy(lifecycleFunction)
+
+	if c := runLad(t, code, "rollback", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("rollback exit code = %d, want %d", c, exitcode.Success)
+	}
+	for alias, want := range map[string]string{"live": "2", "previous": "1", "latest": "2"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("%s = %q, want %q", alias, got, want)
+		}
+	}
+
+	// 稳定态再次 rollback 回退到 previous
+	if c := runLad(t, code, "rollback", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("second rollback exit code = %d, want %d", c, exitcode.Success)
+	}
+	if got := aliasVersion(t, fake, "live"); got != "1" {
+		t.Errorf("live = %q, want %q", got, "1")
+	}
+	if log := lastRollbackLog(t); !strings.Contains(log, "FROM_VERSION=2 TO_VERSION=1") || !strings.Contains(log, "ACTION=rollback") {
+		t.Errorf("rollback log %q should record rollback from 2 to 1", log)
+	}
+}
+
+func TestRollback_ToPreviousDuringCanary(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "promote", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("promote exit code = %d, want %d", c, exitcode.Success)
+	}
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	if c := runLad(t, code, "rollback", "--env", "test", "--to-previous"); c != exitcode.Success {
+		t.Fatalf("rollback --to-previous exit code = %d, want %d", c, exitcode.Success)
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("canary should be cleared")
+	}
+	for alias, want := range map[string]string{"live": "1", "latest": "1"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("%s = %q, want %q", alias, got, want)
+		}
+	}
+}
+
+// promoteVersions 依次部署并 promote count 个新版本，第一个版本为 1
+func promoteVersions(t *testing.T, fake *aws.FakeLambda, code *int, count int) {
+	t.Helper()
+
+	fake.Deploy(lifecycleFunction)
+	for i := 0; i < count; i++ {
+		fake.Deploy(lifecycleFunction)
+		if c := runLad(t, code, "promote", "--env", "test"); c != exitcode.Success {
+			t.Fatalf("promote exit code = %d, want %d", c, exitcode.Success)
+		}
+	}
+}
+
+func TestRollback_MultiLevel(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	promoteVersions(t, fake, code, 3) // live 4, previous 3
+
+	// 每次回退后 previous 指向更早的版本，可以继续回退
+	if c := runLad(t, code, "rollback", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("rollback exit code = %d, want %d", c, exitcode.Success)
+	}
+	for alias, want := range map[string]string{"live": "3", "latest": "3", "previous": "2"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("after rollback %s = %q, want %q", alias, got, want)
+		}
+	}
+	if c := runLad(t, code, "rollback", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("second rollback exit code = %d, want %d", c, exitcode.Success)
+	}
+	for alias, want := range map[string]string{"live": "2", "latest": "2", "previous": "1"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("after second rollback %s = %q, want %q", alias, got, want)
+		}
+	}
+	if c := runLad(t, code, "rollback", "--env", "test"); c != exitcode.Success {
+		t.Fatalf("third rollback exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	// 发布历史用完后回到回退态
+	if got := aliasVersion(t, fake, "previous"); got != "1" {
+		t.Errorf("previous = %q, want %q", got, "1")
+	}
+	if c := runLad(t, code, "rollback", "--env", "test"); c != exitcode.ParamError {
+		t.Errorf("rollback without history exit code = %d, want %d", c, exitcode.ParamError)
+	}
+	if c := runLad(t, code, "rollback", "--env", "test", "--steps", "1"); c != exitcode.ParamError {
+		t.Errorf("rollback --steps 1 without history exit code = %d, want %d", c, exitcode.ParamError)
+	}
+}
+
+func TestRollback_StepsAndTo(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	promoteVersions(t, fake, code, 4) // live 5, 发布历史 5 4 3 2 1
+
+	if c := runLad(t, code, "rollback", "--env", "test", "--steps", "2"); c != exitcode.Success {
+		t.Fatalf("rollback --steps 2 exit code = %d, want %d", c, exitcode.Success)
+	}
+	for alias, want := range map[string]string{"live": "3", "latest": "3", "previous": "2"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("after --steps 2 %s = %q, want %q", alias, got, want)
+		}
+	}
+
+	// 回退掉的版本从发布历史中移除
+	if c := runLad(t, code, "rollback", "--env", "test", "--to", "4"); c != exitcode.ParamError {
+		t.Errorf("rollback --to 4 exit code = %d, want %d", c, exitcode.ParamError)
+	}
+	if c := runLad(t, code, "rollback", "--env", "test", "--to", "1"); c != exitcode.Success {
+		t.Fatalf("rollback --to 1 exit code = %d, want %d", c, exitcode.Success)
+	}
+	for alias, want := range map[string]string{"live": "1", "latest": "1", "previous": "1"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("after --to 1 %s = %q, want %q", alias, got, want)
+		}
+	}
+	if log := lastRollbackLog(t); !strings.Contains(log, "FROM_VERSION=3 TO_VERSION=1") {
+		t.Errorf("rollback log %q should record 3 -> 1", log)
+	}
+
+	for _, args := range [][]string{
+		{"--steps", "0"},
+		{"--to", ""},
+		{"--steps", "1", "--to", "2"},
+		{"--steps", "1", "--to-previous"},
+		{"--to", "1"},
+	} {
+		if c := runLad(t, code, append([]string{"rollback", "--env", "test"}, args...)...); c != exitcode.ParamError {
+			t.Errorf("rollback %v exit code = %d, want %d", args, c, exitcode.ParamError)
+		}
+	}
+}
+
+func TestRollback_DeletedTarget(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	promoteVersions(t, fake, code, 4) // live 5, previous 4, 发布历史 5 4 3 2 1
+	client := aws.NewClientWithAPI(fake)
+	for _, version := range []string{"2", "3"} {
+		if err := client.DeleteVersion(context.Background(), lifecycleFunction, version); err != nil {
+			t.Fatalf("DeleteVersion(%s) unexpected error: %v", version, err)
+		}
+	}
+
+	// 回退目标已被删除时不修改任何别名
+	if c := runLad(t, code, "rollback", "--env", "test", "--to", "3"); c != exitcode.ResourceNotFound {
+		t.Errorf("rollback --to 3 exit code = %d, want %d", c, exitcode.ResourceNotFound)
+	}
+	for alias, want := range map[string]string{"live": "5", "latest": "5", "previous": "4"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("after failed rollback %s = %q, want %q", alias, got, want)
+		}
+	}
+
+	// 回退后的 previous 已被删除时指向回退目标
+	if c := runLad(t, code, "rollback", "--env", "test", "--steps", "1"); c != exitcode.Success {
+		t.Fatalf("rollback --steps 1 exit code = %d, want %d", c, exitcode.Success)
+	}
+	for alias, want := range map[string]string{"live": "4", "latest": "4", "previous": "4"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("after rollback %s = %q, want %q", alias, got, want)
+		}
+	}
+}
+
+func TestRollback_StepsDuringCanary(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	promoteVersions(t, fake, code, 2) // live 3, previous 2
+	fake.Deploy(lifecycleFunction)
+	if c := runLad(t, code, "canary", "--env", "test", "--percent", "10"); c != exitcode.Success {
+		t.Fatalf("canary exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	// --steps 在灰度中同样回退，清除灰度配置
+	if c := runLad(t, code, "rollback", "--env", "test", "--steps", "2"); c != exitcode.Success {
+		t.Fatalf("rollback --steps 2 exit code = %d, want %d", c, exitcode.Success)
+	}
+	if ok, _, _ := canaryState(t, fake); ok {
+		t.Error("canary should be cleared")
+	}
+	for alias, want := range map[string]string{"live": "1", "latest": "1", "previous": "1"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("%s = %q, want %q", alias, got, want)
+		}
+	}
+}
+
+func TestRollback_HistoryAfterSwitch(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	promoteVersions(t, fake, code, 2) // live 3, previous 2
+
+	// switch 同样记录发布历史
+	if c := runLad(t, code, "switch", "--env", "test", "--version", "1"); c != exitcode.Success {
+		t.Fatalf("switch exit code = %d, want %d", c, exitcode.Success)
+	}
+	if c := runLad(t, code, "rollback", "--env", "test", "--steps", "1"); c != exitcode.Success {
+		t.Fatalf("rollback --steps 1 exit code = %d, want %d", c, exitcode.Success)
+	}
+	for alias, want := range map[string]string{"live": "3", "previous": "2"} {
+		if got := aliasVersion(t, fake, alias); got != want {
+			t.Errorf("%s = %q, want %q", alias, got, want)
+		}
+	}
+}
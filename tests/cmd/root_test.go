@@ -247,6 +247,72 @@ profile = "prod-profile"
 	})
 }
 
+func TestGetRegion(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("region flag takes priority", func(t *testing.T) {
+		cmd.SetRegion("eu-west-1")
+		defer cmd.SetRegion("")
+		samconfigPath := filepath.Join(tmpDir, "samconfig_flag.toml")
+		cmd.SetSamconfigPath(samconfigPath)
+
+		samconfigContent := `
+version = 0.1
+
+[test.deploy.parameters]
+stack_name = "my-stack"
+region = "us-east-1"
+`
+		if err := os.WriteFile(samconfigPath, []byte(samconfigContent), 0644); err != nil {
+			t.Fatalf("Failed to write samconfig.toml: %v", err)
+		}
+
+		result := cmd.GetRegion("test")
+		if result != "eu-west-1" {
+			t.Errorf("GetRegion() = %q, want %q", result, "eu-west-1")
+		}
+	})
+
+	t.Run("reads region per environment from samconfig", func(t *testing.T) {
+		cmd.SetRegion("")
+		samconfigPath := filepath.Join(tmpDir, "samconfig_env.toml")
+		cmd.SetSamconfigPath(samconfigPath)
+
+		samconfigContent := `
+version = 0.1
+
+[test.deploy.parameters]
+stack_name = "my-stack-test"
+region = "us-west-2"
+
+[prod.global.parameters]
+region = "ap-northeast-1"
+
+[prod.deploy.parameters]
+stack_name = "my-stack-prod"
+`
+		if err := os.WriteFile(samconfigPath, []byte(samconfigContent), 0644); err != nil {
+			t.Fatalf("Failed to write samconfig.toml: %v", err)
+		}
+
+		if result := cmd.GetRegion("test"); result != "us-west-2" {
+			t.Errorf("GetRegion(test) = %q, want %q", result, "us-west-2")
+		}
+		if result := cmd.GetRegion("prod"); result != "ap-northeast-1" {
+			t.Errorf("GetRegion(prod) = %q, want %q", result, "ap-northeast-1")
+		}
+	})
+
+	t.Run("returns empty string when samconfig not found", func(t *testing.T) {
+		cmd.SetRegion("")
+		cmd.SetSamconfigPath(filepath.Join(tmpDir, "nonexistent.toml"))
+
+		if result := cmd.GetRegion("test"); result != "" {
+			t.Errorf("GetRegion() = %q, want empty string", result)
+		}
+	})
+}
+
 // =============================================================================
 // Property-Based Tests
 // =============================================================================
@@ -0,0 +1,76 @@
+package cmd_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aura-studio/lad/internal/exitcode"
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// statusOutput 执行 status 并返回标准输出
+func statusOutput(t *testing.T, code *int) string {
+	t.Helper()
+
+	var c int
+	out := captureStdout(t, func() {
+		c = runLad(t, code, "status", "--env", "test")
+	})
+	if c != exitcode.Success {
+		t.Fatalf("status exit code = %d, want %d", c, exitcode.Success)
+	}
+	return out
+}
+
+func TestStatus_DeploymentState(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	steps := []struct {
+		args []string
+		want string
+	}{
+		{nil, "部署状态: 待验证态 (pending)"},
+		{[]string{"canary", "--env", "test", "--percent", "10"}, "部署状态: 灰度态 (canary)"},
+		{[]string{"promote", "--env", "test"}, "部署状态: 稳定态 (stable)"},
+		{[]string{"rollback", "--env", "test"}, "部署状态: 回退态 (rolled-back)"},
+	}
+	for _, step := range steps {
+		if step.args != nil {
+			if c := runLad(t, code, step.args...); c != exitcode.Success {
+				t.Fatalf("%s exit code = %d, want %d", step.args[0], c, exitcode.Success)
+			}
+		}
+		if out := statusOutput(t, code); !strings.Contains(out, step.want) {
+			t.Errorf("status output does not contain %q:\n%s", step.want, out)
+		}
+	}
+}
+
+func TestStatus_Anomalies(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	// 在 lad 之外把灰度指向了不是 latest 的版本
+	_, err := fake.UpdateAlias(context.Background(), &lambda.UpdateAliasInput{
+		FunctionName:  sdkaws.String(lifecycleFunction),
+		Name:          sdkaws.String("live"),
+		RoutingConfig: &types.AliasRoutingConfiguration{AdditionalVersionWeights: map[string]float64{"2": 0.1}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateAlias() unexpected error: %v", err)
+	}
+
+	out := statusOutput(t, code)
+	for _, want := range []string{"状态异常: 灰度版本 2 不是 latest 版本 3", "状态异常: 灰度进行中，但 live 与 previous 都指向版本 1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("status output does not contain %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/cmd"
+	"github.com/aura-studio/lad/internal/exitcode"
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// captureStdout 捕获 fn 执行期间写入 stdout 的内容
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() unexpected error: %v", err)
+	}
+	old := os.Stdout
+	os.Stdout = w
+
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		done <- buf.String()
+	}()
+
+	fn()
+
+	w.Close()
+	os.Stdout = old
+	return <-done
+}
+
+func TestVersions_JSON(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	now := time.Now()
+	fake.SetClock(func() time.Time { return now.Add(-240 * time.Hour) })
+	fake.Deploy(lifecycleFunction)
+	fake.SetClock(func() time.Time { return now.Add(-time.Hour) })
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	// live: 1 (80%) + 3 (20%)
+	_, err := fake.UpdateAlias(context.Background(), &lambda.UpdateAliasInput{
+		FunctionName:  sdkaws.String(lifecycleFunction),
+		Name:          sdkaws.String("live"),
+		RoutingConfig: &types.AliasRoutingConfiguration{AdditionalVersionWeights: map[string]float64{"3": 0.2}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateAlias() unexpected error: %v", err)
+	}
+
+	var c int
+	out := captureStdout(t, func() {
+		c = runLad(t, code, "versions", "--env", "test", "--output", "json")
+	})
+	if c != exitcode.Success {
+		t.Fatalf("versions exit code = %d, want %d", c, exitcode.Success)
+	}
+
+	var report cmd.VersionsReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("versions --output json is not valid JSON: %v\n%s", err, out)
+	}
+	if report.Function != lifecycleFunction || report.Total != 3 || len(report.Versions) != 3 {
+		t.Fatalf("report = %+v, want 3 versions of %s", report, lifecycleFunction)
+	}
+
+	aliases := func(entry cmd.VersionEntry) string {
+		var names []string
+		for _, p := range entry.Aliases {
+			names = append(names, p.String())
+		}
+		return strings.Join(names, ", ")
+	}
+	want := map[string]string{
+		"3": "latest, live (20%)",
+		"2": "",
+		"1": "live (80%), previous",
+	}
+	for _, entry := range report.Versions {
+		if got := aliases(entry); got != want[entry.Version] {
+			t.Errorf("version %s aliases = %q, want %q", entry.Version, got, want[entry.Version])
+		}
+		if entry.CodeSha256 == "" || entry.Created == "" || entry.Runtime == "" {
+			t.Errorf("version %s = %+v, want metadata", entry.Version, entry)
+		}
+	}
+	if report.Versions[0].Version != "3" {
+		t.Errorf("first version = %s, want newest (3)", report.Versions[0].Version)
+	}
+}
+
+func TestVersions_Filters(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	now := time.Now()
+	fake.SetClock(func() time.Time { return now.Add(-240 * time.Hour) })
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+	fake.SetClock(func() time.Time { return now.Add(-time.Hour) })
+	fake.Deploy(lifecycleFunction)
+	fake.Deploy(lifecycleFunction)
+
+	versionsOf := func(args ...string) []string {
+		t.Helper()
+		var c int
+		out := captureStdout(t, func() {
+			c = runLad(t, code, append([]string{"versions", "--env", "test", "--output", "json"}, args...)...)
+		})
+		if c != exitcode.Success {
+			t.Fatalf("versions %v exit code = %d, want %d", args, c, exitcode.Success)
+		}
+		var report cmd.VersionsReport
+		if err := json.Unmarshal([]byte(out), &report); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		var got []string
+		for _, entry := range report.Versions {
+			got = append(got, entry.Version)
+		}
+		return got
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "4,3,2,1"},
+		{[]string{"--limit", "2"}, "4,3"},
+		{[]string{"--aliased"}, "4,1"},
+		{[]string{"--since", "24h"}, "4,3"},
+		{[]string{"--since", "24h", "--aliased"}, "4"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(versionsOf(tt.args...), ","); got != tt.want {
+			t.Errorf("versions %v = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestVersions_Table(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+
+	var c int
+	out := captureStdout(t, func() {
+		c = runLad(t, code, "versions", "--env", "test")
+	})
+	if c != exitcode.Success {
+		t.Fatalf("versions exit code = %d, want %d", c, exitcode.Success)
+	}
+	for _, want := range []string{"CodeSha256", "latest, live, previous", "provided.al2023", "arm64"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("versions table missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestVersions_InvalidFlags(t *testing.T) {
+	fake, code := setupFakeLambda(t)
+	fake.Deploy(lifecycleFunction)
+
+	for _, args := range [][]string{
+		{"--output", "yaml"},
+		{"--limit", "-1"},
+	} {
+		if c := runLad(t, code, append([]string{"versions", "--env", "test"}, args...)...); c != exitcode.ParamError {
+			t.Errorf("versions %v exit code = %d, want %d", args, c, exitcode.ParamError)
+		}
+	}
+
+	// 函数不存在（runLad 会追加 --function，这里直接执行）
+	*code = exitcode.Success
+	if err := cmd.ExecuteArgs([]string{"versions", "--env", "test", "--function", "missing"}); err != nil {
+		t.Fatalf("versions: unexpected error: %v", err)
+	}
+	if *code != exitcode.ResourceNotFound {
+		t.Errorf("versions for missing function exit code = %d, want %d", *code, exitcode.ResourceNotFound)
+	}
+}
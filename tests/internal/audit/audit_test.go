@@ -0,0 +1,183 @@
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/audit"
+)
+
+// sampleRecord 返回一条 canary 的审计记录
+func sampleRecord() *audit.Record {
+	return &audit.Record{
+		Timestamp: time.Date(2026, 1, 1, 12, 0, 5, 0, time.UTC),
+		StartedAt: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		Command:   "canary",
+		Flags:     map[string]string{"percent": "10"},
+		Function:  "demo-function",
+		Env:       "prod",
+		Operator:  "alice",
+		Before:    map[string]audit.Alias{"live": {Version: "1"}},
+		After:     map[string]audit.Alias{"live": {Version: "1", Weights: map[string]float64{"2": 0.1}}},
+		Outcome:   audit.Success,
+	}
+}
+
+// readRecords 读取 JSON lines 文件中的所有记录
+func readRecords(t *testing.T, path string) []audit.Record {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer f.Close()
+
+	var records []audit.Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record audit.Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %q is not a JSON record: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestFileSink_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.log")
+	sink := audit.NewFileSink(path)
+
+	first := sampleRecord()
+	second := sampleRecord()
+	second.Command = "promote"
+	for _, record := range []*audit.Record{first, second} {
+		if err := sink.Write(context.Background(), record); err != nil {
+			t.Fatalf("Write() unexpected error: %v", err)
+		}
+	}
+
+	records := readRecords(t, path)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].Command != "canary" || records[1].Command != "promote" {
+		t.Errorf("commands = %q, %q, want canary, promote", records[0].Command, records[1].Command)
+	}
+	if got := records[0].After["live"].Weights["2"]; got != 0.1 {
+		t.Errorf("after live weight = %v, want 0.1", got)
+	}
+	if !records[0].StartedAt.Equal(first.StartedAt) || records[0].Outcome != audit.Success {
+		t.Errorf("record = %+v, want %+v", records[0], *first)
+	}
+}
+
+func TestParseSink(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "stdout", want: "stdout"},
+		{spec: "file:/var/log/lad/audit.log", want: "/var/log/lad/audit.log"},
+		{spec: "audit.log", want: "audit.log"},
+		{spec: "webhook:https://hooks.example.com/lad?token=secret", want: "webhook https://hooks.example.com"},
+		{spec: "http://127.0.0.1:8080/audit", want: "webhook http://127.0.0.1:8080"},
+		{spec: "", wantErr: true},
+		{spec: "file:", wantErr: true},
+		{spec: "webhook:ftp://example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		sink, err := audit.ParseSink(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseSink(%q) expected error, got %v", tt.spec, sink)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSink(%q) unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if got := sink.String(); got != tt.want {
+			t.Errorf("ParseSink(%q).String() = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received []audit.Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s, want POST application/json", r.Method, r.Header.Get("Content-Type"))
+		}
+		var record audit.Record
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			t.Errorf("request body is not a JSON record: %v", err)
+		}
+		received = append(received, record)
+		if strings.HasSuffix(r.URL.Path, "/fail") {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	ok, err := audit.NewWebhookSink(server.URL + "/ok")
+	if err != nil {
+		t.Fatalf("NewWebhookSink() unexpected error: %v", err)
+	}
+	failing, err := audit.NewWebhookSink(server.URL + "/fail")
+	if err != nil {
+		t.Fatalf("NewWebhookSink() unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	// 某个输出失败时其他输出仍然写入
+	err = audit.Write(context.Background(), []audit.Sink{failing, ok, audit.NewFileSink(path)}, sampleRecord())
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Write() error = %v, want 500 from failing webhook", err)
+	}
+	if len(received) != 2 || received[1].Function != "demo-function" {
+		t.Errorf("webhook received %+v, want 2 records for demo-function", received)
+	}
+	if records := readRecords(t, path); len(records) != 1 {
+		t.Errorf("file sink got %d records, want 1", len(records))
+	}
+}
+
+func TestWebhookSink_RedactsToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	const token = "s3cr3t-t0ken"
+
+	rejected, err := audit.NewWebhookSink(server.URL + "/hooks/" + token + "?token=" + token)
+	if err != nil {
+		t.Fatalf("NewWebhookSink() unexpected error: %v", err)
+	}
+	err = audit.Write(context.Background(), []audit.Sink{rejected}, sampleRecord())
+	if err == nil || !strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), token) {
+		t.Errorf("Write() error = %v, want 403 without the token", err)
+	}
+
+	// 连接失败时 *url.Error 中包含完整 URL
+	server.Close()
+	unreachable, err := audit.NewWebhookSink(server.URL + "/hooks/" + token + "?token=" + token)
+	if err != nil {
+		t.Fatalf("NewWebhookSink() unexpected error: %v", err)
+	}
+	err = audit.Write(context.Background(), []audit.Sink{unreachable}, sampleRecord())
+	if err == nil || strings.Contains(err.Error(), token) {
+		t.Errorf("Write() error = %v, want an error without the token", err)
+	}
+}
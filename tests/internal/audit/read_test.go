@@ -0,0 +1,177 @@
+package audit_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/audit"
+)
+
+func TestParseRollbackLine(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		wantEnv    string
+		wantReason string
+		wantAction string
+		wantBefore map[string]string
+		wantAfter  map[string]string
+	}{
+		{
+			name:       "rollback without action",
+			line:       `[2024-01-15T10:30:00Z] ENV=prod FROM_VERSION=5 TO_VERSION=4 REASON="Bug fix" OPERATOR=admin`,
+			wantEnv:    "prod",
+			wantReason: "Bug fix",
+			wantBefore: map[string]string{"live": "5"},
+			wantAfter:  map[string]string{"live": "4"},
+		},
+		{
+			name:       "abort with quoted reason",
+			line:       `[2024-01-15T10:30:00+08:00] ENV=test FROM_VERSION=7 TO_VERSION=6 REASON="say "hi" OPERATOR=x" OPERATOR=bob ACTION=abort`,
+			wantEnv:    "test",
+			wantReason: `say "hi" OPERATOR=x`,
+			wantAction: "abort",
+			wantBefore: map[string]string{"live": "6", "latest": "7"},
+			wantAfter:  map[string]string{"live": "6", "latest": "6"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := audit.ParseRollbackLine(tt.line)
+			if err != nil {
+				t.Fatalf("ParseRollbackLine() unexpected error: %v", err)
+			}
+			if record.Command != "rollback" || record.Env != tt.wantEnv || record.Reason != tt.wantReason || record.Flags["action"] != tt.wantAction {
+				t.Errorf("ParseRollbackLine() = %+v", record)
+			}
+			if record.Outcome != audit.Success || record.Timestamp.IsZero() {
+				t.Errorf("outcome/timestamp = %s/%v, want success with time", record.Outcome, record.Timestamp)
+			}
+			for name, want := range tt.wantBefore {
+				if got := record.Before[name].Version; got != want {
+					t.Errorf("before %s = %q, want %q", name, got, want)
+				}
+			}
+			for name, want := range tt.wantAfter {
+				if got := record.After[name].Version; got != want {
+					t.Errorf("after %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+
+	for _, line := range []string{"", "not a log line", `[yesterday] ENV=prod FROM_VERSION=2 TO_VERSION=1 REASON="x" OPERATOR=a`} {
+		if _, err := audit.ParseRollbackLine(line); err == nil {
+			t.Errorf("ParseRollbackLine(%q) expected error", line)
+		}
+	}
+}
+
+func TestParseRollbackLine_Interrupt(t *testing.T) {
+	line := `[2026-10-16T08:30:00Z] ENV=prod FROM_VERSION=8 TO_VERSION=7 REASON="自动灰度被中断 (收到信号 interrupt)，灰度保持在 25%" OPERATOR=alice ACTION=interrupt`
+	record, err := audit.ParseRollbackLine(line)
+	if err != nil {
+		t.Fatalf("ParseRollbackLine() unexpected error: %v", err)
+	}
+	if record.Command != "auto" || record.Flags["action"] != "interrupt" || record.Outcome != audit.Failure {
+		t.Errorf("ParseRollbackLine() = %+v, want an interrupted auto", record)
+	}
+	for _, aliases := range []map[string]audit.Alias{record.Before, record.After} {
+		if aliases["live"].Version != "7" || aliases["latest"].Version != "8" {
+			t.Errorf("aliases = %+v, want live 7 and latest 8", aliases)
+		}
+	}
+}
+
+func TestRead_MixedFormats(t *testing.T) {
+	input := strings.Join([]string{
+		`{"timestamp":"2026-01-01T12:00:00Z","started_at":"2026-01-01T11:59:00Z","command":"canary","env":"prod","operator":"alice","outcome":"success","exit_code":0}`,
+		``,
+		`[2026-01-02T08:00:00Z] ENV=prod FROM_VERSION=2 TO_VERSION=1 REASON="errors" OPERATOR=bob`,
+		`garbage`,
+		`{"timestamp": broken`,
+	}, "\n")
+
+	records, skipped, err := audit.Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+	if skipped != 2 {
+		t.Errorf("skipped = %d, want 2", skipped)
+	}
+	if len(records) != 2 || records[0].Command != "canary" || records[1].Operator != "bob" {
+		t.Errorf("Read() = %+v, want canary by alice and rollback by bob", records)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 1, 1, hour, 0, 0, 0, time.UTC) }
+	records := []audit.Record{
+		{Timestamp: at(12), Command: "promote", Env: "prod", Function: "f", Operator: "alice"},
+		{Timestamp: at(10), Command: "canary", Env: "prod", Function: "f", Operator: "alice"},
+		{Timestamp: at(11), Command: "canary", Env: "test", Function: "f", Operator: "bob"},
+		{Timestamp: at(13), Command: "auto pause", Env: "prod", Function: "g", Operator: "bob"},
+		{Timestamp: at(14), Command: "rollback", Env: "prod", Operator: "bob"},
+	}
+
+	tests := []struct {
+		name   string
+		filter audit.Filter
+		want   []string
+	}{
+		{name: "all sorted by time", filter: audit.Filter{}, want: []string{"canary", "canary", "promote", "auto pause", "rollback"}},
+		{name: "env", filter: audit.Filter{Env: "prod"}, want: []string{"canary", "promote", "auto pause", "rollback"}},
+		{name: "function includes records without function", filter: audit.Filter{Function: "f"}, want: []string{"canary", "canary", "promote", "rollback"}},
+		{name: "commands match subcommands", filter: audit.Filter{Commands: []string{"auto", "rollback"}}, want: []string{"auto pause", "rollback"}},
+		{name: "operator", filter: audit.Filter{Operator: "alice"}, want: []string{"canary", "promote"}},
+		{name: "time range", filter: audit.Filter{Since: at(11), Until: at(13)}, want: []string{"canary", "promote"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range audit.Select(records, tt.filter) {
+				got = append(got, r.Command)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Select() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	input := strings.Join([]string{
+		// rollback 同时写入的 JSON 记录和 rollback.log 记录
+		`{"timestamp":"2026-01-02T08:00:01.5Z","started_at":"2026-01-02T07:59:59Z","command":"rollback","function":"f","env":"prod","operator":"bob","before":{"live":{"version":"2"}},"after":{"live":{"version":"1"}},"outcome":"success","exit_code":0}`,
+		`[2026-01-02T08:00:01Z] ENV=prod FROM_VERSION=2 TO_VERSION=1 REASON="errors" OPERATOR=bob`,
+		// auto 取消灰度时 JSON 记录的命令是 auto
+		`{"timestamp":"2026-01-02T09:00:03Z","started_at":"2026-01-02T08:30:00Z","command":"auto","function":"f","env":"prod","operator":"bob","before":{"live":{"version":"1"},"latest":{"version":"3"}},"after":{"live":{"version":"1"},"latest":{"version":"1"}},"outcome":"failure","exit_code":11}`,
+		`[2026-01-02T09:00:01Z] ENV=prod FROM_VERSION=3 TO_VERSION=1 REASON="health" OPERATOR=bob ACTION=abort`,
+		// 没有对应 JSON 记录的旧记录保留
+		`[2025-12-01T08:00:00Z] ENV=prod FROM_VERSION=2 TO_VERSION=1 REASON="old" OPERATOR=bob`,
+		`[2026-01-02T08:00:30Z] ENV=prod FROM_VERSION=2 TO_VERSION=1 REASON="later" OPERATOR=bob`,
+		`[2026-01-02T08:00:01Z] ENV=test FROM_VERSION=2 TO_VERSION=1 REASON="other env" OPERATOR=bob`,
+	}, "\n")
+
+	records, _, err := audit.Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read() unexpected error: %v", err)
+	}
+	deduped := audit.Dedupe(records)
+
+	var got []string
+	for _, r := range deduped {
+		if r.Legacy {
+			got = append(got, r.Reason)
+		} else {
+			got = append(got, r.Command)
+		}
+	}
+	want := []string{"rollback", "auto", "old", "later", "other env"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Dedupe() = %v, want %v", got, want)
+	}
+}
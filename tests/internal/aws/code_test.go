@@ -0,0 +1,147 @@
+package aws_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+)
+
+// newCodeClient 创建只有 $LATEST 的函数，查询间隔为 1ms
+func newCodeClient(t *testing.T) (*aws.Client, *aws.FakeLambda) {
+	t.Helper()
+
+	fake := aws.NewFakeLambda()
+	fake.CreateFunction("demo")
+	client := aws.NewClientWithAPI(fake)
+	client.SetRetryPolicy(fastPolicy(1))
+	client.SetPollInterval(time.Millisecond)
+	return client, fake
+}
+
+func TestUpdateFunctionCode_WaitAndPublish(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newCodeClient(t)
+	fake.SetUpdateDelay(3)
+
+	codeSha256, err := client.UpdateFunctionCode(ctx, "demo", aws.CodeSource{ZipFile: []byte("v1")})
+	if err != nil {
+		t.Fatalf("UpdateFunctionCode() unexpected error: %v", err)
+	}
+
+	// 更新完成前不能发布版本
+	if _, err := client.CreateVersion(ctx, "demo", ""); aws.ClassifyError(err) != exitcode.Conflict {
+		t.Errorf("CreateVersion() during update: error %v, want exit code %d", err, exitcode.Conflict)
+	}
+
+	if err := client.WaitForUpdate(ctx, "demo", time.Second); err != nil {
+		t.Fatalf("WaitForUpdate() unexpected error: %v", err)
+	}
+	status, err := client.GetFunctionStatus(ctx, "demo", "")
+	if err != nil {
+		t.Fatalf("GetFunctionStatus() unexpected error: %v", err)
+	}
+	if status.CodeSha256 != codeSha256 || status.LastUpdateStatus != "Successful" || status.Version != "$LATEST" {
+		t.Errorf("GetFunctionStatus() = %+v, want $LATEST with code hash %s and Successful", status, codeSha256)
+	}
+
+	version, err := client.CreateVersion(ctx, "demo", "abc1234")
+	if err != nil || version != "1" {
+		t.Fatalf("CreateVersion() = %q (err %v), want \"1\"", version, err)
+	}
+
+	// 相同的代码不产生新版本
+	fake.SetUpdateDelay(0)
+	if _, err := client.UpdateFunctionCode(ctx, "demo", aws.CodeSource{ZipFile: []byte("v1")}); err != nil {
+		t.Fatalf("UpdateFunctionCode() unexpected error: %v", err)
+	}
+	if again, err := client.CreateVersion(ctx, "demo", ""); err != nil || again != "1" {
+		t.Errorf("CreateVersion() without code change = %q (err %v), want \"1\"", again, err)
+	}
+
+	// 镜像 URI 视为新代码
+	if _, err := client.UpdateFunctionCode(ctx, "demo", aws.CodeSource{ImageURI: "repo/app:v2"}); err != nil {
+		t.Fatalf("UpdateFunctionCode(image) unexpected error: %v", err)
+	}
+	if next, err := client.CreateVersion(ctx, "demo", ""); err != nil || next != "2" {
+		t.Errorf("CreateVersion() after image update = %q (err %v), want \"2\"", next, err)
+	}
+}
+
+func TestWaitForUpdate_Failed(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newCodeClient(t)
+	fake.SetUpdateDelay(1)
+	fake.SetUpdateFailure("InvalidImage: image manifest not found")
+
+	if _, err := client.UpdateFunctionCode(ctx, "demo", aws.CodeSource{ImageURI: "repo/app:missing"}); err != nil {
+		t.Fatalf("UpdateFunctionCode() unexpected error: %v", err)
+	}
+	err := client.WaitForUpdate(ctx, "demo", time.Second)
+	if aws.ClassifyError(err) != exitcode.AWSError || !strings.Contains(err.Error(), "image manifest not found") {
+		t.Errorf("WaitForUpdate() error = %v, want AWSError with failure reason", err)
+	}
+}
+
+func TestWaitForUpdate_Timeout(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newCodeClient(t)
+	fake.SetUpdateDelay(1000)
+
+	if _, err := client.UpdateFunctionCode(ctx, "demo", aws.CodeSource{ZipFile: []byte("slow")}); err != nil {
+		t.Fatalf("UpdateFunctionCode() unexpected error: %v", err)
+	}
+	err := client.WaitForUpdate(ctx, "demo", 20*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "超时") {
+		t.Errorf("WaitForUpdate() error = %v, want timeout", err)
+	}
+}
+
+func TestUpdateFunctionCode_NoSource(t *testing.T) {
+	client, _ := newCodeClient(t)
+
+	_, err := client.UpdateFunctionCode(context.Background(), "demo", aws.CodeSource{})
+	if aws.ClassifyError(err) != exitcode.AWSError {
+		t.Errorf("UpdateFunctionCode() without source: error %v, want exit code %d", err, exitcode.AWSError)
+	}
+}
+
+func TestWaitForVersionReady(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newCodeClient(t)
+	if version, err := client.CreateVersion(ctx, "demo", ""); err != nil || version != "1" {
+		t.Fatalf("CreateVersion() = %q (err %v), want \"1\"", version, err)
+	}
+
+	// Pending 的版本在就绪后返回
+	if err := fake.SetVersionPending("demo", "1", 3); err != nil {
+		t.Fatalf("SetVersionPending() unexpected error: %v", err)
+	}
+	if err := client.WaitForVersionReady(ctx, "demo", "1", time.Second); err != nil {
+		t.Fatalf("WaitForVersionReady() unexpected error: %v", err)
+	}
+
+	// 超时
+	fake.SetVersionPending("demo", "1", 1000)
+	err := client.WaitForVersionReady(ctx, "demo", "1", 20*time.Millisecond)
+	if aws.ClassifyError(err) != exitcode.VersionNotReady || !strings.Contains(err.Error(), "超时") {
+		t.Errorf("WaitForVersionReady() error = %v, want VersionNotReady timeout", err)
+	}
+
+	// Failed 的版本立即返回
+	fake.SetVersionPending("demo", "1", 0)
+	fake.SetVersionFailed("demo", "1", "SnapStart restore failed")
+	err = client.WaitForVersionReady(ctx, "demo", "1", time.Second)
+	if aws.ClassifyError(err) != exitcode.VersionNotReady || !strings.Contains(err.Error(), "SnapStart restore failed") {
+		t.Errorf("WaitForVersionReady() error = %v, want VersionNotReady with failure reason", err)
+	}
+
+	// 版本不存在
+	err = client.WaitForVersionReady(ctx, "demo", "9", time.Second)
+	if aws.ClassifyError(err) != exitcode.ResourceNotFound {
+		t.Errorf("WaitForVersionReady() unknown version: error %v, want exit code %d", err, exitcode.ResourceNotFound)
+	}
+}
@@ -0,0 +1,362 @@
+package aws_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+func TestFakeLambda_Deploy(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+
+	// 首次部署创建三个别名，都指向版本 1
+	version, err := fake.Deploy("demo")
+	if err != nil {
+		t.Fatalf("Deploy() unexpected error: %v", err)
+	}
+	if version != "1" {
+		t.Fatalf("Deploy() = %q, want %q", version, "1")
+	}
+	for _, name := range []string{"live", "previous", "latest"} {
+		out, err := fake.GetAlias(ctx, &lambda.GetAliasInput{FunctionName: sdkaws.String("demo"), Name: sdkaws.String(name)})
+		if err != nil {
+			t.Fatalf("GetAlias(%s) unexpected error: %v", name, err)
+		}
+		if got := sdkaws.ToString(out.FunctionVersion); got != "1" {
+			t.Errorf("GetAlias(%s) version = %q, want %q", name, got, "1")
+		}
+	}
+
+	// 之后的部署只移动 latest
+	version, err = fake.Deploy("demo")
+	if err != nil {
+		t.Fatalf("Deploy() unexpected error: %v", err)
+	}
+	if version != "2" {
+		t.Fatalf("Deploy() = %q, want %q", version, "2")
+	}
+	client := aws.NewClientWithAPI(fake)
+	if state, err := client.GetAliasState(ctx, "demo", "latest"); err != nil || state.Version != "2" {
+		t.Errorf("latest = %+v (err %v), want version %q", state, err, "2")
+	}
+	if state, err := client.GetAliasState(ctx, "demo", "live"); err != nil || state.Version != "1" {
+		t.Errorf("live = %+v (err %v), want version %q", state, err, "1")
+	}
+}
+
+func TestFakeLambda_PublishVersionWithoutChange(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+	if _, err := fake.Deploy("demo"); err != nil {
+		t.Fatalf("Deploy() unexpected error: %v", err)
+	}
+
+	// 代码没有变化时返回上次发布的版本
+	out, err := fake.PublishVersion(ctx, &lambda.PublishVersionInput{FunctionName: sdkaws.String("demo")})
+	if err != nil {
+		t.Fatalf("PublishVersion() unexpected error: %v", err)
+	}
+	if got := sdkaws.ToString(out.Version); got != "1" {
+		t.Errorf("PublishVersion() without change = %q, want %q", got, "1")
+	}
+
+	// 代码变化后发布新版本
+	if err := fake.ChangeCode("demo"); err != nil {
+		t.Fatalf("ChangeCode() unexpected error: %v", err)
+	}
+	out, err = fake.PublishVersion(ctx, &lambda.PublishVersionInput{FunctionName: sdkaws.String("demo")})
+	if err != nil {
+		t.Fatalf("PublishVersion() unexpected error: %v", err)
+	}
+	if got := sdkaws.ToString(out.Version); got != "2" {
+		t.Errorf("PublishVersion() after change = %q, want %q", got, "2")
+	}
+}
+
+func TestFakeLambda_RoutingConfig(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+	fake.Deploy("demo")
+	client := aws.NewClientWithAPI(fake)
+
+	if _, code := client.ConfigureCanary(ctx, "demo", "live", "1", "2", 0.25, ""); code != exitcode.Success {
+		t.Fatalf("ConfigureCanary() exit code = %d, want %d", code, exitcode.Success)
+	}
+	state, err := client.GetAliasState(ctx, "demo", "live")
+	if err != nil {
+		t.Fatalf("GetAliasState() unexpected error: %v", err)
+	}
+	if version, weight, ok := state.Canary(); !ok || version != "2" || weight != 0.25 {
+		t.Errorf("Canary() = (%q, %v, %v), want (\"2\", 0.25, true)", version, weight, ok)
+	}
+
+	// 清除路由配置
+	if _, code := client.UpdateAlias(ctx, "demo", "live", "2", ""); code != exitcode.Success {
+		t.Fatalf("UpdateAlias() exit code = %d, want %d", code, exitcode.Success)
+	}
+	if state, err := client.GetAliasState(ctx, "demo", "live"); err != nil || state.HasRouting() {
+		t.Errorf("GetAliasState() = %+v (err %v), want no routing after UpdateAlias", state, err)
+	}
+}
+
+func TestGetAliasState_FullRouting(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+	fake.Deploy("demo")
+	fake.Deploy("demo")
+
+	_, err := fake.UpdateAlias(ctx, &lambda.UpdateAliasInput{
+		FunctionName:  sdkaws.String("demo"),
+		Name:          sdkaws.String("live"),
+		Description:   sdkaws.String("manual"),
+		RoutingConfig: &types.AliasRoutingConfiguration{AdditionalVersionWeights: map[string]float64{"3": 0.2}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateAlias() unexpected error: %v", err)
+	}
+
+	state, err := aws.NewClientWithAPI(fake).GetAliasState(ctx, "demo", "live")
+	if err != nil {
+		t.Fatalf("GetAliasState() unexpected error: %v", err)
+	}
+	if state.Name != "live" || state.Version != "1" || state.Description != "manual" || state.RevisionID == "" {
+		t.Errorf("GetAliasState() = %+v, want live -> 1 with description and revision", state)
+	}
+	if len(state.Weights) != 1 || state.Weights["3"] != 0.2 {
+		t.Errorf("Weights = %v, want map[3:0.2]", state.Weights)
+	}
+	if got := state.RoutingSummary(); got != "1 (80%), 3 (20%)" {
+		t.Errorf("RoutingSummary() = %q, want %q", got, "1 (80%), 3 (20%)")
+	}
+}
+
+func TestAliasState_MultipleRoutedVersions(t *testing.T) {
+	state := &aws.AliasState{Version: "1", Weights: map[string]float64{"10": 0.2, "2": 0.1}}
+
+	// 多个附加版本不是单一灰度
+	if _, _, ok := state.Canary(); ok {
+		t.Error("Canary() should not report a single canary with two routed versions")
+	}
+	if got := state.RoutingSummary(); got != "1 (70%), 2 (10%), 10 (20%)" {
+		t.Errorf("RoutingSummary() = %q, want %q", got, "1 (70%), 2 (10%), 10 (20%)")
+	}
+}
+
+func TestGetAliasState_Error(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+	client := aws.NewClientWithAPI(fake)
+
+	state, err := client.GetAliasState(ctx, "demo", "missing")
+	if err == nil {
+		t.Fatalf("GetAliasState(missing) = %+v, want error", state)
+	}
+	var awsErr *aws.Error
+	if !errors.As(err, &awsErr) || awsErr.Code != exitcode.ResourceNotFound {
+		t.Errorf("GetAliasState(missing) error = %v, want *aws.Error with code %d", err, exitcode.ResourceNotFound)
+	}
+}
+
+func TestCreateAlias(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+	fake.CreateFunction("demo")
+	client := aws.NewClientWithAPI(fake)
+	// 别名已存在时不应重试
+	client.SetRetryPolicy(fastPolicy(5))
+
+	version, err := client.CreateVersion(ctx, "demo", "")
+	if err != nil {
+		t.Fatalf("CreateVersion() unexpected error: %v", err)
+	}
+	if err := client.CreateAlias(ctx, "demo", "live", version, "initial"); err != nil {
+		t.Fatalf("CreateAlias(live) unexpected error: %v", err)
+	}
+
+	state, err := client.GetAliasState(ctx, "demo", "live")
+	if err != nil {
+		t.Fatalf("GetAliasState(live) unexpected error: %v", err)
+	}
+	if state.Version != version || state.HasRouting() || state.Description != "initial" {
+		t.Errorf("GetAliasState(live) = %+v, want version %s without routing", state, version)
+	}
+
+	tests := []struct {
+		name    string
+		alias   string
+		version string
+		want    int
+	}{
+		{"alias already exists", "live", version, exitcode.StateChanged},
+		{"version not found", "latest", "99", exitcode.ResourceNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.CreateAlias(ctx, "demo", tt.alias, tt.version, "")
+			if got := aws.ClassifyError(err); got != tt.want {
+				t.Errorf("CreateAlias() error = %v (code %d), want code %d", err, got, tt.want)
+			}
+		})
+	}
+
+	// 已存在的别名保持不变
+	after, _ := client.GetAliasState(ctx, "demo", "live")
+	if after.RevisionID != state.RevisionID {
+		t.Errorf("existing alias was modified: RevisionId %s -> %s", state.RevisionID, after.RevisionID)
+	}
+}
+
+func TestFakeLambda_Errors(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+
+	tests := []struct {
+		name string
+		call func() error
+		want interface{}
+	}{
+		{
+			name: "unknown function",
+			call: func() error {
+				_, err := fake.GetAlias(ctx, &lambda.GetAliasInput{FunctionName: sdkaws.String("missing"), Name: sdkaws.String("live")})
+				return err
+			},
+			want: &types.ResourceNotFoundException{},
+		},
+		{
+			name: "unknown alias",
+			call: func() error {
+				_, err := fake.GetAlias(ctx, &lambda.GetAliasInput{FunctionName: sdkaws.String("demo"), Name: sdkaws.String("missing")})
+				return err
+			},
+			want: &types.ResourceNotFoundException{},
+		},
+		{
+			name: "unknown version",
+			call: func() error {
+				_, err := fake.UpdateAlias(ctx, &lambda.UpdateAliasInput{FunctionName: sdkaws.String("demo"), Name: sdkaws.String("live"), FunctionVersion: sdkaws.String("99")})
+				return err
+			},
+			want: &types.ResourceNotFoundException{},
+		},
+		{
+			name: "routing to primary version",
+			call: func() error {
+				_, err := fake.UpdateAlias(ctx, &lambda.UpdateAliasInput{
+					FunctionName:    sdkaws.String("demo"),
+					Name:            sdkaws.String("live"),
+					FunctionVersion: sdkaws.String("1"),
+					RoutingConfig:   &types.AliasRoutingConfiguration{AdditionalVersionWeights: map[string]float64{"1": 0.5}},
+				})
+				return err
+			},
+			want: &types.InvalidParameterValueException{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			switch tt.want.(type) {
+			case *types.ResourceNotFoundException:
+				var target *types.ResourceNotFoundException
+				if !errors.As(err, &target) {
+					t.Errorf("error = %T, want ResourceNotFoundException", err)
+				}
+			case *types.InvalidParameterValueException:
+				var target *types.InvalidParameterValueException
+				if !errors.As(err, &target) {
+					t.Errorf("error = %T, want InvalidParameterValueException", err)
+				}
+			}
+		})
+	}
+}
+
+func TestFakeLambda_RevisionId(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+	fake.Deploy("demo")
+	client := aws.NewClientWithAPI(fake)
+
+	state, err := client.GetAliasState(ctx, "demo", "live")
+	if err != nil || state.Version != "1" || state.RevisionID == "" {
+		t.Fatalf("GetAliasState() = %+v (err %v), want version 1 with a revision", state, err)
+	}
+	revision := state.RevisionID
+
+	// 使用读取到的 RevisionId 修改成功，并返回新的 RevisionId
+	newRevision, code := client.ConfigureCanary(ctx, "demo", "live", "1", "2", 0.1, revision)
+	if code != exitcode.Success {
+		t.Fatalf("ConfigureCanary() exit code = %d, want %d", code, exitcode.Success)
+	}
+	if newRevision == "" || newRevision == revision {
+		t.Errorf("ConfigureCanary() revision = %q, want a new revision", newRevision)
+	}
+
+	// 使用过期的 RevisionId 修改失败，别名保持不变
+	if _, code := client.UpdateAlias(ctx, "demo", "live", "2", revision); code != exitcode.StateChanged {
+		t.Errorf("UpdateAlias(stale revision) exit code = %d, want %d", code, exitcode.StateChanged)
+	}
+	state, err = client.GetAliasState(ctx, "demo", "live")
+	if err != nil {
+		t.Fatalf("GetAliasState() unexpected error: %v", err)
+	}
+	if canaryVersion, weight, ok := state.Canary(); !ok || canaryVersion != "2" || weight != 0.1 {
+		t.Errorf("Canary() = (%q, %v, %v), want (\"2\", 0.1, true)", canaryVersion, weight, ok)
+	}
+
+	// 使用最新的 RevisionId 修改成功
+	if _, code := client.UpdateAlias(ctx, "demo", "live", "2", newRevision); code != exitcode.Success {
+		t.Errorf("UpdateAlias(current revision) exit code = %d, want %d", code, exitcode.Success)
+	}
+}
+
+func TestRejectedDescription_Truncated(t *testing.T) {
+	// CloudWatch 告警的原因可能很长，描述不能超过 Lambda 的 256 个字符限制
+	for _, reason := range []string{strings.Repeat("x", 300), strings.Repeat("错误率过高", 60)} {
+		description := aws.RejectedDescription("7", reason)
+		if len(description) > 256 {
+			t.Errorf("RejectedDescription() length = %d, want <= 256", len(description))
+		}
+		if !utf8.ValidString(description) || !strings.HasPrefix(description, "lad rejected: 7 (") {
+			t.Errorf("RejectedDescription() = %q, want a valid truncated description for version 7", description)
+		}
+	}
+}
+
+func TestAliasState_RejectedVersion(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+		ok          bool
+	}{
+		{aws.RejectedDescription("7", "error rate"), "7", true},
+		{aws.RejectedDescription("8", strings.Repeat("x", 300)), "8", true},
+		{"", "", false},
+		{"production traffic", "", false},
+	}
+	for _, tt := range tests {
+		state := &aws.AliasState{Description: tt.description}
+		if got, ok := state.RejectedVersion(); got != tt.want || ok != tt.ok {
+			t.Errorf("RejectedVersion(%q) = (%q, %v), want (%q, %v)", tt.description, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package aws_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+)
+
+// flakyLambda 在前 failures 次 UpdateAlias 调用时返回 err
+type flakyLambda struct {
+	*aws.FakeLambda
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyLambda) UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return f.FakeLambda.UpdateAlias(ctx, params, optFns...)
+}
+
+// newFlakyClient 创建使用快速重试策略的客户端
+func newFlakyClient(t *testing.T, failures int, err error, policy aws.RetryPolicy) (*aws.Client, *flakyLambda) {
+	t.Helper()
+
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+	fake.Deploy("demo")
+
+	flaky := &flakyLambda{FakeLambda: fake, failures: failures, err: err}
+	client := aws.NewClientWithAPI(flaky)
+	client.SetRetryPolicy(policy)
+	return client, flaky
+}
+
+func fastPolicy(maxAttempts int) aws.RetryPolicy {
+	return aws.RetryPolicy{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    2 * time.Millisecond,
+	}
+}
+
+func TestRetry_ThrottledThenSuccess(t *testing.T) {
+	throttled := &types.TooManyRequestsException{Message: stringPtr("Rate exceeded")}
+	client, flaky := newFlakyClient(t, 2, throttled, fastPolicy(5))
+
+	if _, code := client.ConfigureCanary(context.Background(), "demo", "live", "1", "2", 0.2, ""); code != exitcode.Success {
+		t.Fatalf("ConfigureCanary() exit code = %d, want %d", code, exitcode.Success)
+	}
+	if flaky.calls != 3 {
+		t.Errorf("UpdateAlias calls = %d, want 3", flaky.calls)
+	}
+	state, err := client.GetAliasState(context.Background(), "demo", "live")
+	if err != nil {
+		t.Fatalf("GetAliasState() unexpected error: %v", err)
+	}
+	if version, weight, ok := state.Canary(); !ok || version != "2" || weight != 0.2 {
+		t.Errorf("Canary() = (%q, %v, %v), want (\"2\", 0.2, true)", version, weight, ok)
+	}
+}
+
+func TestRetry_MaxAttemptsExhausted(t *testing.T) {
+	conflict := &types.ResourceConflictException{Message: stringPtr("The operation cannot be performed at this time")}
+	client, flaky := newFlakyClient(t, 10, conflict, fastPolicy(3))
+
+	if _, code := client.UpdateAlias(context.Background(), "demo", "live", "2", ""); code != exitcode.Conflict {
+		t.Errorf("UpdateAlias() exit code = %d, want %d", code, exitcode.Conflict)
+	}
+	if flaky.calls != 3 {
+		t.Errorf("UpdateAlias calls = %d, want 3", flaky.calls)
+	}
+}
+
+func TestRetry_MaxElapsed(t *testing.T) {
+	throttled := &types.TooManyRequestsException{Message: stringPtr("Rate exceeded")}
+	policy := aws.RetryPolicy{
+		MaxAttempts: 10,
+		MaxElapsed:  5 * time.Millisecond,
+		BaseDelay:   20 * time.Millisecond,
+		MaxDelay:    20 * time.Millisecond,
+	}
+	client, flaky := newFlakyClient(t, 10, throttled, policy)
+
+	if _, code := client.UpdateAlias(context.Background(), "demo", "live", "2", ""); code != exitcode.Throttled {
+		t.Errorf("UpdateAlias() exit code = %d, want %d", code, exitcode.Throttled)
+	}
+	// 第一次等待就会超过 MaxElapsed，不进行重试
+	if flaky.calls != 1 {
+		t.Errorf("UpdateAlias calls = %d, want 1", flaky.calls)
+	}
+}
+
+func TestRetry_NonRetryableErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"invalid parameter", &types.InvalidParameterValueException{Message: stringPtr("Invalid parameter")}},
+		{"precondition failed", &types.PreconditionFailedException{Message: stringPtr("The RevisionId provided does not match")}},
+		{"access denied", &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, flaky := newFlakyClient(t, 10, tt.err, fastPolicy(5))
+			if _, code := client.UpdateAlias(context.Background(), "demo", "live", "2", ""); code == exitcode.Success {
+				t.Fatal("UpdateAlias() should fail")
+			}
+			if flaky.calls != 1 {
+				t.Errorf("UpdateAlias calls = %d, want 1", flaky.calls)
+			}
+		})
+	}
+}
+
+func TestRetry_ContextCanceled(t *testing.T) {
+	throttled := &types.TooManyRequestsException{Message: stringPtr("Rate exceeded")}
+	policy := aws.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	client, flaky := newFlakyClient(t, 10, throttled, policy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, code := client.UpdateAlias(ctx, "demo", "live", "2", ""); code != exitcode.Throttled {
+		t.Errorf("UpdateAlias() exit code = %d, want %d", code, exitcode.Throttled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("UpdateAlias() took %v, should stop waiting when the context is done", elapsed)
+	}
+	if flaky.calls != 1 {
+		t.Errorf("UpdateAlias calls = %d, want 1", flaky.calls)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"throttled", operationError(&types.TooManyRequestsException{}), true},
+		{"conflict", operationError(&types.ResourceConflictException{}), true},
+		{"service error", operationError(&types.ServiceException{}), true},
+		{"precondition failed", operationError(&types.PreconditionFailedException{}), false},
+		{"not found", operationError(&types.ResourceNotFoundException{}), false},
+		{"invalid parameter", operationError(&types.InvalidParameterValueException{}), false},
+		{"credentials", &aws.CredentialsError{Err: errors.New("no credentials")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aws.IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package aws_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/exitcode"
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+func TestListVersions_Pagination(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.SetClock(func() time.Time { return created })
+
+	// 超过一页（50 项）的版本
+	for i := 0; i < 60; i++ {
+		if _, err := fake.Deploy("demo"); err != nil {
+			t.Fatalf("Deploy() unexpected error: %v", err)
+		}
+	}
+
+	versions, err := aws.NewClientWithAPI(fake).ListVersions(ctx, "demo")
+	if err != nil {
+		t.Fatalf("ListVersions() unexpected error: %v", err)
+	}
+	if len(versions) != 60 {
+		t.Fatalf("ListVersions() returned %d versions, want 60", len(versions))
+	}
+
+	// 按版本号降序，不包含 $LATEST
+	if versions[0].Version != "60" || versions[59].Version != "1" {
+		t.Errorf("ListVersions() order = %s ... %s, want 60 ... 1", versions[0].Version, versions[59].Version)
+	}
+
+	v := versions[0]
+	if !v.Created.Equal(created) {
+		t.Errorf("Created = %v, want %v", v.Created, created)
+	}
+	if v.CodeSha256 == "" || v.CodeSize == 0 || v.Runtime == "" || len(v.Architectures) != 1 {
+		t.Errorf("ListVersions()[0] = %+v, want code hash, size, runtime and architecture", v)
+	}
+}
+
+func TestListAliases(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+	fake.Deploy("demo")
+
+	_, err := fake.UpdateAlias(ctx, &lambda.UpdateAliasInput{
+		FunctionName:  sdkaws.String("demo"),
+		Name:          sdkaws.String("live"),
+		RoutingConfig: &types.AliasRoutingConfiguration{AdditionalVersionWeights: map[string]float64{"2": 0.3}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateAlias() unexpected error: %v", err)
+	}
+
+	aliases, err := aws.NewClientWithAPI(fake).ListAliases(ctx, "demo")
+	if err != nil {
+		t.Fatalf("ListAliases() unexpected error: %v", err)
+	}
+
+	got := make(map[string]*aws.AliasState)
+	for _, alias := range aliases {
+		got[alias.Name] = alias
+	}
+	if len(aliases) != 3 || aliases[0].Name != "latest" || aliases[2].Name != "previous" {
+		t.Fatalf("ListAliases() = %d aliases, want latest, live, previous in order", len(aliases))
+	}
+	if got["latest"].Version != "2" || got["previous"].Version != "1" {
+		t.Errorf("latest = %s, previous = %s, want 2 and 1", got["latest"].Version, got["previous"].Version)
+	}
+	if version, weight, ok := got["live"].Canary(); !ok || version != "2" || weight != 0.3 {
+		t.Errorf("live Canary() = (%q, %v, %v), want (\"2\", 0.3, true)", version, weight, ok)
+	}
+}
+
+func TestListVersions_FunctionNotFound(t *testing.T) {
+	_, err := aws.NewClientWithAPI(aws.NewFakeLambda()).ListVersions(context.Background(), "missing")
+	if err == nil {
+		t.Fatal("ListVersions() on missing function should return error")
+	}
+}
+
+func TestDeleteVersion(t *testing.T) {
+	ctx := context.Background()
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+	fake.Deploy("demo")
+	fake.Deploy("demo")
+	client := aws.NewClientWithAPI(fake)
+	client.SetRetryPolicy(fastPolicy(1))
+
+	// live 的灰度路由引用版本 2
+	if _, code := client.ConfigureCanary(ctx, "demo", "live", "1", "2", 0.1, ""); code != exitcode.Success {
+		t.Fatalf("ConfigureCanary() exit code = %d, want %d", code, exitcode.Success)
+	}
+
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{"1", exitcode.Conflict},          // live、previous 指向
+		{"2", exitcode.Conflict},          // live 的灰度版本
+		{"3", exitcode.Conflict},          // latest 指向
+		{"$LATEST", exitcode.AWSError},    // 不能单独删除
+		{"42", exitcode.ResourceNotFound}, // 不存在
+	}
+	for _, tt := range tests {
+		if code := aws.ClassifyError(client.DeleteVersion(ctx, "demo", tt.version)); code != tt.want {
+			t.Errorf("DeleteVersion(%s) exit code = %d, want %d", tt.version, code, tt.want)
+		}
+	}
+
+	// 清除灰度后版本 2 可以删除
+	if _, code := client.UpdateAlias(ctx, "demo", "live", "1", ""); code != exitcode.Success {
+		t.Fatalf("UpdateAlias() exit code = %d, want %d", code, exitcode.Success)
+	}
+	before, err := client.GetCodeStorage(ctx)
+	if err != nil {
+		t.Fatalf("GetCodeStorage() unexpected error: %v", err)
+	}
+	if err := client.DeleteVersion(ctx, "demo", "2"); err != nil {
+		t.Fatalf("DeleteVersion(2) unexpected error: %v", err)
+	}
+
+	versions, err := client.ListVersions(ctx, "demo")
+	if err != nil || len(versions) != 2 || versions[0].Version != "3" || versions[1].Version != "1" {
+		t.Errorf("ListVersions() after delete = %+v (err %v), want 3, 1", versions, err)
+	}
+	after, err := client.GetCodeStorage(ctx)
+	if err != nil {
+		t.Fatalf("GetCodeStorage() unexpected error: %v", err)
+	}
+	if after.Limit != before.Limit || after.Used >= before.Used {
+		t.Errorf("GetCodeStorage() = %+v after delete, before %+v", after, before)
+	}
+}
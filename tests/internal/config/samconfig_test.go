@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/aura-studio/lad/internal/config"
@@ -134,3 +135,112 @@ func TestLoadSAMConfig_InvalidTOML(t *testing.T) {
 		t.Error("LoadSAMConfig should return error for invalid TOML")
 	}
 }
+
+// TestLoadSAMConfig_Region tests that region is read from deploy parameters,
+// falling back to global parameters
+func TestLoadSAMConfig_Region(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "samconfig.toml")
+
+	content := `version = 0.1
+[test.global.parameters]
+region = "us-east-1"
+
+[test.deploy.parameters]
+stack_name = "my-stack"
+region = "eu-central-1"
+
+[prod.global.parameters]
+region = "ap-southeast-1"
+
+[prod.deploy.parameters]
+stack_name = "my-stack"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := config.LoadSAMConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadSAMConfig failed: %v", err)
+	}
+
+	if got := cfg.GetRegion("test"); got != "eu-central-1" {
+		t.Errorf("GetRegion(test) = %q, want %q", got, "eu-central-1")
+	}
+	if got := cfg.GetRegion("prod"); got != "ap-southeast-1" {
+		t.Errorf("GetRegion(prod) = %q, want %q", got, "ap-southeast-1")
+	}
+	if got := cfg.GetRegion("staging"); got != "" {
+		t.Errorf("GetRegion(staging) = %q, want empty string", got)
+	}
+}
+
+// TestLoadSAMConfig_LadParameters tests parsing of the [env.lad.parameters] section
+func TestLoadSAMConfig_LadParameters(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "samconfig.toml")
+
+	content := `version = 0.1
+[test.deploy.parameters]
+stack_name = "my-stack"
+
+[test.lad.parameters]
+retry_max_attempts = 8
+retry_max_elapsed = "90s"
+sam_path = "/opt/sam/bin/sam"
+audit_log = ["stdout", "https://hooks.example.com/lad"]
+auto_strategy = "1%:10m,25%:30m"
+auto_on_interrupt = "revert"
+
+[prod.lad.parameters]
+audit_log = "/var/log/lad/audit.log"
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := config.LoadSAMConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadSAMConfig failed: %v", err)
+	}
+
+	if got := cfg.GetRetryMaxAttempts("test"); got != 8 {
+		t.Errorf("GetRetryMaxAttempts(test) = %d, want 8", got)
+	}
+	if got := cfg.GetRetryMaxElapsed("test"); got != "90s" {
+		t.Errorf("GetRetryMaxElapsed(test) = %q, want %q", got, "90s")
+	}
+	if got := cfg.GetSAMPath("test"); got != "/opt/sam/bin/sam" {
+		t.Errorf("GetSAMPath(test) = %q, want %q", got, "/opt/sam/bin/sam")
+	}
+	if got := cfg.GetAuditLog("test"); !reflect.DeepEqual(got, []string{"stdout", "https://hooks.example.com/lad"}) {
+		t.Errorf("GetAuditLog(test) = %q, want [stdout https://hooks.example.com/lad]", got)
+	}
+	if got := cfg.GetAuditLog("prod"); !reflect.DeepEqual(got, []string{"/var/log/lad/audit.log"}) {
+		t.Errorf("GetAuditLog(prod) = %q, want [/var/log/lad/audit.log]", got)
+	}
+	if got := cfg.GetAutoStrategy("test"); got != "1%:10m,25%:30m" {
+		t.Errorf("GetAutoStrategy(test) = %q, want %q", got, "1%:10m,25%:30m")
+	}
+	if got := cfg.GetAutoOnInterrupt("test"); got != "revert" {
+		t.Errorf("GetAutoOnInterrupt(test) = %q, want %q", got, "revert")
+	}
+
+	// 未配置的环境返回零值
+	if got := cfg.GetRetryMaxAttempts("prod"); got != 0 {
+		t.Errorf("GetRetryMaxAttempts(prod) = %d, want 0", got)
+	}
+	if got := cfg.GetRetryMaxElapsed("prod"); got != "" {
+		t.Errorf("GetRetryMaxElapsed(prod) = %q, want empty string", got)
+	}
+	if got := cfg.GetSAMPath("prod"); got != "" {
+		t.Errorf("GetSAMPath(prod) = %q, want empty string", got)
+	}
+	if got := cfg.GetAutoStrategy("prod"); got != "" {
+		t.Errorf("GetAutoStrategy(prod) = %q, want empty string", got)
+	}
+	if got := cfg.GetAutoOnInterrupt("prod"); got != "" {
+		t.Errorf("GetAutoOnInterrupt(prod) = %q, want empty string", got)
+	}
+}
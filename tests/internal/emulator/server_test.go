@@ -0,0 +1,335 @@
+package emulator_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/emulator"
+	"github.com/aura-studio/lad/internal/exitcode"
+)
+
+// setupServer 启动部署了两个版本的模拟服务
+func setupServer(t *testing.T, persist func() error) (*httptest.Server, *aws.FakeLambda) {
+	t.Helper()
+
+	store := aws.NewFakeLambda()
+	store.Deploy("demo")
+	store.Deploy("demo")
+
+	server := httptest.NewServer(emulator.New(store, persist))
+	t.Cleanup(server.Close)
+	return server, store
+}
+
+// do 发送请求并解析 JSON 响应
+func do(t *testing.T, method, url string, body interface{}, out interface{}) *http.Response {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("marshal request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+	}
+	return resp
+}
+
+type alias struct {
+	Name            string
+	FunctionVersion string
+	RevisionId      string
+	RoutingConfig   *struct {
+		AdditionalVersionWeights map[string]float64
+	}
+}
+
+type apiError struct {
+	Type    string
+	Message string
+}
+
+func TestServer_GetAndUpdateAlias(t *testing.T) {
+	server, _ := setupServer(t, nil)
+	url := server.URL + "/2015-03-31/functions/demo/aliases/live"
+
+	var live alias
+	if resp := do(t, http.MethodGet, url, nil, &live); resp.StatusCode != http.StatusOK {
+		t.Fatalf("GetAlias status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if live.FunctionVersion != "1" || live.RoutingConfig != nil {
+		t.Fatalf("GetAlias = %+v, want version 1 without routing", live)
+	}
+
+	// 配置 10% 灰度
+	var updated alias
+	resp := do(t, http.MethodPut, url, map[string]interface{}{
+		"FunctionVersion": "1",
+		"RoutingConfig":   map[string]interface{}{"AdditionalVersionWeights": map[string]float64{"2": 0.1}},
+	}, &updated)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("UpdateAlias status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if updated.RoutingConfig == nil || updated.RoutingConfig.AdditionalVersionWeights["2"] != 0.1 {
+		t.Errorf("UpdateAlias routing = %+v, want {2: 0.1}", updated.RoutingConfig)
+	}
+	if updated.RevisionId == live.RevisionId {
+		t.Error("UpdateAlias should change RevisionId")
+	}
+
+	// 清除路由配置
+	var cleared alias
+	resp = do(t, http.MethodPut, url, map[string]interface{}{
+		"FunctionVersion": "2",
+		"RoutingConfig":   map[string]interface{}{"AdditionalVersionWeights": map[string]float64{}},
+	}, &cleared)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("UpdateAlias status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if cleared.FunctionVersion != "2" || cleared.RoutingConfig != nil {
+		t.Errorf("UpdateAlias = %+v, want version 2 without routing", cleared)
+	}
+}
+
+func TestServer_CreateAliasAndList(t *testing.T) {
+	server, _ := setupServer(t, nil)
+	base := server.URL + "/2015-03-31/functions/demo"
+
+	var created alias
+	resp := do(t, http.MethodPost, base+"/aliases", map[string]string{"Name": "staging", "FunctionVersion": "2"}, &created)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("CreateAlias status = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if created.Name != "staging" || created.FunctionVersion != "2" {
+		t.Errorf("CreateAlias = %+v, want staging -> 2", created)
+	}
+
+	// 重复创建返回 409
+	var conflict apiError
+	resp = do(t, http.MethodPost, base+"/aliases", map[string]string{"Name": "staging", "FunctionVersion": "2"}, &conflict)
+	if resp.StatusCode != http.StatusConflict || resp.Header.Get("X-Amzn-ErrorType") != "ResourceConflictException" {
+		t.Errorf("duplicate CreateAlias = %d %q, want 409 ResourceConflictException", resp.StatusCode, resp.Header.Get("X-Amzn-ErrorType"))
+	}
+
+	var aliases struct{ Aliases []alias }
+	do(t, http.MethodGet, base+"/aliases?FunctionVersion=2", nil, &aliases)
+	names := map[string]bool{}
+	for _, a := range aliases.Aliases {
+		names[a.Name] = true
+	}
+	if len(aliases.Aliases) != 2 || !names["latest"] || !names["staging"] {
+		t.Errorf("ListAliases(FunctionVersion=2) = %+v, want latest and staging", aliases.Aliases)
+	}
+
+	type versionPage struct {
+		Versions   []struct{ Version string }
+		NextMarker *string
+	}
+	var versions versionPage
+	do(t, http.MethodGet, base+"/versions?MaxItems=2", nil, &versions)
+	if len(versions.Versions) != 2 || versions.Versions[0].Version != "$LATEST" || versions.NextMarker == nil {
+		t.Fatalf("ListVersionsByFunction page 1 = %+v", versions)
+	}
+	marker := *versions.NextMarker
+	versions = versionPage{}
+	do(t, http.MethodGet, base+"/versions?MaxItems=2&Marker="+marker, nil, &versions)
+	if len(versions.Versions) != 1 || versions.Versions[0].Version != "2" || versions.NextMarker != nil {
+		t.Errorf("ListVersionsByFunction page 2 = %+v", versions)
+	}
+}
+
+func TestServer_PublishVersionAndGetFunction(t *testing.T) {
+	persisted := 0
+	server, store := setupServer(t, func() error {
+		persisted++
+		return nil
+	})
+	base := server.URL + "/2015-03-31/functions/demo"
+
+	store.ChangeCode("demo")
+	var published struct{ Version string }
+	resp := do(t, http.MethodPost, base+"/versions", map[string]string{}, &published)
+	if resp.StatusCode != http.StatusCreated || published.Version != "3" {
+		t.Fatalf("PublishVersion = %d %+v, want 201 version 3", resp.StatusCode, published)
+	}
+	if persisted != 1 {
+		t.Errorf("persist called %d times, want 1", persisted)
+	}
+
+	var function struct {
+		Configuration struct{ Version string }
+	}
+	do(t, http.MethodGet, base+"?Qualifier=latest", nil, &function)
+	if function.Configuration.Version != "2" {
+		t.Errorf("GetFunction(latest) version = %q, want %q", function.Configuration.Version, "2")
+	}
+
+	// 管理接口模拟 sam deploy
+	var deployed struct{ Version string }
+	do(t, http.MethodPost, server.URL+"/_emulator/functions/demo/deploy", nil, &deployed)
+	if deployed.Version != "4" {
+		t.Errorf("deploy version = %q, want %q", deployed.Version, "4")
+	}
+	do(t, http.MethodGet, base+"?Qualifier=latest", nil, &function)
+	if function.Configuration.Version != "4" {
+		t.Errorf("GetFunction(latest) after deploy = %q, want %q", function.Configuration.Version, "4")
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	server, _ := setupServer(t, func() error { return errors.New("disk full") })
+	base := server.URL + "/2015-03-31/functions"
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       interface{}
+		wantStatus int
+		wantType   string
+	}{
+		{"unknown function", http.MethodGet, base + "/missing/aliases/live", nil, http.StatusNotFound, "ResourceNotFoundException"},
+		{"unknown alias", http.MethodGet, base + "/demo/aliases/missing", nil, http.StatusNotFound, "ResourceNotFoundException"},
+		{"unknown version", http.MethodPut, base + "/demo/aliases/live", map[string]string{"FunctionVersion": "99"}, http.StatusNotFound, "ResourceNotFoundException"},
+		{"invalid weight", http.MethodPut, base + "/demo/aliases/live", map[string]interface{}{
+			"RoutingConfig": map[string]interface{}{"AdditionalVersionWeights": map[string]float64{"2": 1.5}},
+		}, http.StatusBadRequest, "InvalidParameterValueException"},
+		{"invalid MaxItems", http.MethodGet, base + "/demo/versions?MaxItems=abc", nil, http.StatusBadRequest, "InvalidParameterValueException"},
+		{"delete aliased version", http.MethodDelete, base + "/demo?Qualifier=1", nil, http.StatusConflict, "ResourceConflictException"},
+		{"persist failure", http.MethodPut, base + "/demo/aliases/live", map[string]string{"FunctionVersion": "2"}, http.StatusInternalServerError, "ServiceException"},
+		{"unknown operation", http.MethodDelete, base + "/demo/aliases/live", nil, http.StatusNotFound, "UnknownOperationException"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body apiError
+			resp := do(t, tt.method, tt.url, tt.body, &body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("X-Amzn-ErrorType"); got != tt.wantType {
+				t.Errorf("X-Amzn-ErrorType = %q, want %q", got, tt.wantType)
+			}
+			if body.Message == "" {
+				t.Error("error response should include a message")
+			}
+		})
+	}
+}
+
+// TestServer_SDKClient 通过 --endpoint-url 使用真实的 SDK 客户端访问模拟服务
+func TestServer_SDKClient(t *testing.T) {
+	server, _ := setupServer(t, nil)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	ctx := context.Background()
+	client, err := aws.NewClient(ctx, aws.ClientOptions{EndpointURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() unexpected error: %v", err)
+	}
+
+	if state, err := client.GetAliasState(ctx, "demo", "latest"); err != nil || state.Version != "2" {
+		t.Fatalf("GetAliasState(latest) = %+v (err %v), want version \"2\"", state, err)
+	}
+	if _, code := client.ConfigureCanary(ctx, "demo", "live", "1", "2", 0.1, ""); code != exitcode.Success {
+		t.Fatalf("ConfigureCanary() exit code = %d, want %d", code, exitcode.Success)
+	}
+	state, err := client.GetAliasState(ctx, "demo", "live")
+	if err != nil {
+		t.Fatalf("GetAliasState(live) unexpected error: %v", err)
+	}
+	if version, weight, ok := state.Canary(); !ok || version != "2" || weight != 0.1 {
+		t.Errorf("Canary() = (%q, %v, %v), want (\"2\", 0.1, true)", version, weight, ok)
+	}
+	if _, code := client.UpdateAlias(ctx, "demo", "live", "2", "stale-revision"); code != exitcode.StateChanged {
+		t.Errorf("UpdateAlias(stale revision) exit code = %d, want %d", code, exitcode.StateChanged)
+	}
+	if code := client.VerifyVersionExists(ctx, "demo", "42"); code != exitcode.ResourceNotFound {
+		t.Errorf("VerifyVersionExists(42) exit code = %d, want %d", code, exitcode.ResourceNotFound)
+	}
+
+	// 删除版本和代码存储用量
+	before, err := client.GetCodeStorage(ctx)
+	if err != nil || before.Used == 0 || before.Limit == 0 {
+		t.Fatalf("GetCodeStorage() = %+v (err %v), want usage and limit", before, err)
+	}
+	if _, code := client.UpdateAlias(ctx, "demo", "live", "1", ""); code != exitcode.Success {
+		t.Fatalf("UpdateAlias() exit code = %d, want %d", code, exitcode.Success)
+	}
+	client.SetRetryPolicy(aws.RetryPolicy{MaxAttempts: 1})
+	if err := client.DeleteVersion(ctx, "demo", "2"); aws.ClassifyError(err) != exitcode.Conflict {
+		t.Errorf("DeleteVersion(2) referenced by latest: error %v, want exit code %d", err, exitcode.Conflict)
+	}
+	if _, code := client.UpdateAlias(ctx, "demo", "latest", "1", ""); code != exitcode.Success {
+		t.Fatalf("UpdateAlias() exit code = %d, want %d", code, exitcode.Success)
+	}
+	if err := client.DeleteVersion(ctx, "demo", "2"); err != nil {
+		t.Fatalf("DeleteVersion(2) unexpected error: %v", err)
+	}
+	if code := client.VerifyVersionExists(ctx, "demo", "2"); code != exitcode.ResourceNotFound {
+		t.Errorf("VerifyVersionExists(2) after delete exit code = %d, want %d", code, exitcode.ResourceNotFound)
+	}
+	after, err := client.GetCodeStorage(ctx)
+	if err != nil || after.Used >= before.Used {
+		t.Errorf("GetCodeStorage() after delete = %+v (err %v), want less than %d", after, err, before.Used)
+	}
+
+	// 上传代码、等待更新完成并发布版本
+	codeSha256, err := client.UpdateFunctionCode(ctx, "demo", aws.CodeSource{ZipFile: []byte("zip content")})
+	if err != nil || codeSha256 == "" {
+		t.Fatalf("UpdateFunctionCode() = %q (err %v), want code hash", codeSha256, err)
+	}
+	if err := client.WaitForUpdate(ctx, "demo", time.Second); err != nil {
+		t.Fatalf("WaitForUpdate() unexpected error: %v", err)
+	}
+	status, err := client.GetFunctionStatus(ctx, "demo", "")
+	if err != nil || status.CodeSha256 != codeSha256 || status.LastUpdateStatus != "Successful" {
+		t.Errorf("GetFunctionStatus() = %+v (err %v), want code hash %s and Successful", status, err, codeSha256)
+	}
+	if version, err := client.CreateVersion(ctx, "demo", "uploaded"); err != nil || version != "3" {
+		t.Errorf("CreateVersion() = %q (err %v), want \"3\"", version, err)
+	}
+
+	// 函数标签
+	if err := client.TagFunction(ctx, "demo", map[string]string{"lad:lock:holder": "ci@runner-1", "team": "payments"}); err != nil {
+		t.Fatalf("TagFunction() unexpected error: %v", err)
+	}
+	if err := client.UntagFunction(ctx, "demo", []string{"team"}); err != nil {
+		t.Fatalf("UntagFunction() unexpected error: %v", err)
+	}
+	tags, err := client.FunctionTags(ctx, "demo")
+	if err != nil || len(tags) != 1 || tags["lad:lock:holder"] != "ci@runner-1" {
+		t.Errorf("FunctionTags() = %v (err %v), want only lad:lock:holder", tags, err)
+	}
+}
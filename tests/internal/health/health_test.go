@@ -0,0 +1,169 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/health"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		spec string
+		want health.Threshold
+	}{
+		{"Errors>0", health.Threshold{Metric: "Errors", Stat: "Sum", Op: ">", Value: 0}},
+		{"Duration:p99>=1000", health.Threshold{Metric: "Duration", Stat: "p99", Op: ">=", Value: 1000}},
+		{" Throttles : Maximum > 2.5 ", health.Threshold{Metric: "Throttles", Stat: "Maximum", Op: ">", Value: 2.5}},
+		{"Invocations:SampleCount<1", health.Threshold{Metric: "Invocations", Stat: "SampleCount", Op: "<", Value: 1}},
+		{"Duration:p99.9<=300", health.Threshold{Metric: "Duration", Stat: "p99.9", Op: "<=", Value: 300}},
+	}
+	for _, tt := range tests {
+		got, err := health.ParseThreshold(tt.spec)
+		if err != nil {
+			t.Errorf("ParseThreshold(%q) unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if *got != tt.want {
+			t.Errorf("ParseThreshold(%q) = %+v, want %+v", tt.spec, *got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", "Errors", ">1", "Errors>abc", "Errors:Median>1", "Errors:p101>1", "Bad Name>1"} {
+		if _, err := health.ParseThreshold(spec); err == nil {
+			t.Errorf("ParseThreshold(%q) expected error", spec)
+		}
+	}
+}
+
+func TestThreshold_Breached(t *testing.T) {
+	tests := []struct {
+		spec  string
+		value float64
+		want  bool
+	}{
+		{"Errors>0", 0, false},
+		{"Errors>0", 1, true},
+		{"Errors>=5", 5, true},
+		{"Invocations<1", 0, true},
+		{"Invocations<=1", 2, false},
+	}
+	for _, tt := range tests {
+		threshold, err := health.ParseThreshold(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseThreshold(%q) unexpected error: %v", tt.spec, err)
+		}
+		if got := threshold.Breached(tt.value); got != tt.want {
+			t.Errorf("%s Breached(%v) = %v, want %v", tt.spec, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePeriod(t *testing.T) {
+	for _, period := range []time.Duration{time.Minute, 5 * time.Minute} {
+		if err := health.ValidatePeriod(period); err != nil {
+			t.Errorf("ValidatePeriod(%v) unexpected error: %v", period, err)
+		}
+	}
+	for _, period := range []time.Duration{0, 30 * time.Second, 90 * time.Second} {
+		if err := health.ValidatePeriod(period); err == nil {
+			t.Errorf("ValidatePeriod(%v) expected error", period)
+		}
+	}
+}
+
+func TestGate_Preflight(t *testing.T) {
+	ctx := context.Background()
+	cw := aws.NewFakeCloudWatch()
+	cw.SetAlarm("api-errors", aws.AlarmStateOK, "")
+
+	// 告警不存在时返回错误
+	gate := health.NewGate(cw, []string{"api-errors", "api-latency"}, nil, time.Minute)
+	if _, err := gate.Preflight(ctx); err == nil || !strings.Contains(err.Error(), "api-latency") {
+		t.Errorf("Preflight() error = %v, want missing api-latency", err)
+	}
+
+	cw.SetAlarm("api-latency", aws.AlarmStateInsufficientData, "")
+	if breach, err := gate.Preflight(ctx); err != nil || breach != nil {
+		t.Errorf("Preflight() = %v, %v, want healthy", breach, err)
+	}
+
+	cw.SetAlarm("api-latency", aws.AlarmStateAlarm, "Threshold Crossed")
+	breach, err := gate.Preflight(ctx)
+	if err != nil || breach == nil || breach.Source != "告警 api-latency" || breach.Detail != "Threshold Crossed" {
+		t.Errorf("Preflight() = %+v, %v, want api-latency breach", breach, err)
+	}
+
+	// 开始灰度前不检查指标
+	cw.SetMetric("Errors", 10)
+	thresholds := mustThresholds(t, "Errors>0")
+	if breach, err := health.NewGate(cw, nil, thresholds, time.Minute).Preflight(ctx); err != nil || breach != nil {
+		t.Errorf("Preflight() with metrics only = %v, %v, want healthy", breach, err)
+	}
+}
+
+func TestGate_Check(t *testing.T) {
+	ctx := context.Background()
+	cw := aws.NewFakeCloudWatch()
+	cw.SetAlarm("api-errors", aws.AlarmStateOK, "")
+	target := health.Target{Function: "demo-function", Alias: "live", Version: "7"}
+	gate := health.NewGate(cw, []string{"api-errors"}, mustThresholds(t, "Errors>0", "Duration:p99>=1000"), 2*time.Minute)
+
+	// 没有数据视为正常
+	if breach, err := gate.Check(ctx, target); err != nil || breach != nil {
+		t.Fatalf("Check() = %v, %v, want healthy", breach, err)
+	}
+	queries := cw.Queries()
+	if len(queries) != 2 {
+		t.Fatalf("got %d metric queries, want 2", len(queries))
+	}
+	q := queries[1]
+	if q.Namespace != "AWS/Lambda" || q.MetricName != "Duration" || q.Stat != "p99" || q.Period != 2*time.Minute {
+		t.Errorf("query = %+v", q)
+	}
+	if q.Dimensions["FunctionName"] != "demo-function" || q.Dimensions["Resource"] != "demo-function:live" || q.Dimensions["ExecutedVersion"] != "7" {
+		t.Errorf("query dimensions = %v", q.Dimensions)
+	}
+	if q.End.Sub(q.Start) != 2*time.Minute {
+		t.Errorf("query range = %v, want %v", q.End.Sub(q.Start), 2*time.Minute)
+	}
+
+	cw.SetMetric("Duration", 800, 1200)
+	breach, err := gate.Check(ctx, target)
+	if err != nil || breach == nil || breach.Source != "指标 Duration:p99>=1000" {
+		t.Fatalf("Check() = %+v, %v, want Duration breach", breach, err)
+	}
+	if !strings.Contains(breach.String(), "1200") {
+		t.Errorf("breach = %q, should contain the value", breach.String())
+	}
+
+	// 告警优先于指标
+	cw.SetAlarm("api-errors", aws.AlarmStateAlarm, "")
+	if breach, _ := gate.Check(ctx, target); breach == nil || breach.String() != "告警 api-errors" {
+		t.Errorf("Check() = %v, want api-errors breach", breach)
+	}
+
+	cw.SetError(errors.New("connection reset"))
+	if _, err := gate.Check(ctx, target); err == nil {
+		t.Error("Check() expected error")
+	}
+}
+
+// mustThresholds 解析指标阈值
+func mustThresholds(t *testing.T, specs ...string) []*health.Threshold {
+	t.Helper()
+
+	var thresholds []*health.Threshold
+	for _, spec := range specs {
+		threshold, err := health.ParseThreshold(spec)
+		if err != nil {
+			t.Fatalf("ParseThreshold(%q) unexpected error: %v", spec, err)
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds
+}
@@ -0,0 +1,204 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/lock"
+)
+
+// stores 返回测试用的文件存储和函数标签存储
+func stores(t *testing.T) map[string]lock.Store {
+	t.Helper()
+
+	lock.SetSettleDelay(0)
+	t.Cleanup(func() { lock.SetSettleDelay(-1) })
+
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+	return map[string]lock.Store{
+		"file": lock.NewFileStore(filepath.Join(t.TempDir(), "lock-test.json")),
+		"tags": lock.NewTagStore(aws.NewClientWithAPI(fake), "demo"),
+	}
+}
+
+// setClock 将锁使用的时间固定为 now，测试结束后恢复
+func setClock(t *testing.T, now *time.Time) {
+	t.Helper()
+
+	lock.SetClock(func() time.Time { return *now })
+	t.Cleanup(func() { lock.SetClock(nil) })
+}
+
+func TestAcquire_Contended(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			lease, err := lock.Acquire(ctx, store, "alice@ci", "auto", "prod", time.Minute)
+			if err != nil {
+				t.Fatalf("Acquire() unexpected error: %v", err)
+			}
+
+			got, err := store.Load(ctx)
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+			want := lease.Lock()
+			if got == nil || got.Holder != "alice@ci" || got.Command != "auto" || got.Env != "prod" || got.Token != want.Token || !got.ExpiresAt.Equal(want.ExpiresAt) {
+				t.Errorf("Load() = %+v, want %+v", got, want)
+			}
+
+			// 其他操作者无法获取未到期的锁
+			_, err = lock.Acquire(ctx, store, "bob@laptop", "promote", "prod", time.Minute)
+			var held *lock.HeldError
+			if !errors.As(err, &held) || held.Lock.Holder != "alice@ci" {
+				t.Fatalf("second Acquire() error = %v, want HeldError by alice@ci", err)
+			}
+
+			// 释放后可以再次获取
+			if err := lease.Release(ctx); err != nil {
+				t.Fatalf("Release() unexpected error: %v", err)
+			}
+			if got, _ := store.Load(ctx); got != nil {
+				t.Errorf("Load() after release = %+v, want nil", got)
+			}
+			if _, err := lock.Acquire(ctx, store, "bob@laptop", "promote", "prod", time.Minute); err != nil {
+				t.Errorf("Acquire() after release unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAcquire_Concurrent(t *testing.T) {
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			// 标签存储需要等待另一方写入完成后回读
+			lock.SetSettleDelay(100 * time.Millisecond)
+			ctx := context.Background()
+
+			holders := []string{"alice@ci", "bob@laptop"}
+			errs := make([]error, len(holders))
+			start := make(chan struct{})
+			var wg sync.WaitGroup
+			for i, holder := range holders {
+				wg.Add(1)
+				go func(i int, holder string) {
+					defer wg.Done()
+					<-start
+					_, errs[i] = lock.Acquire(ctx, store, holder, "auto", "prod", time.Minute)
+				}(i, holder)
+			}
+			close(start)
+			wg.Wait()
+
+			succeeded := 0
+			for i, err := range errs {
+				var held *lock.HeldError
+				switch {
+				case err == nil:
+					succeeded++
+				case !errors.As(err, &held):
+					t.Errorf("Acquire(%s) error = %v, want HeldError", holders[i], err)
+				}
+			}
+			if succeeded != 1 {
+				t.Fatalf("Acquire() succeeded %d times, want exactly 1 (errors: %v)", succeeded, errs)
+			}
+		})
+	}
+}
+
+func TestAcquire_Expired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	setClock(t, &now)
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			stale, err := lock.Acquire(ctx, store, "alice@ci", "auto", "prod", time.Minute)
+			if err != nil {
+				t.Fatalf("Acquire() unexpected error: %v", err)
+			}
+
+			// 租约到期后其他操作者可以获取锁，原持有者续期时发现锁已被获取
+			now = now.Add(2 * time.Minute)
+			if _, err := lock.Acquire(ctx, store, "bob@laptop", "promote", "prod", time.Minute); err != nil {
+				t.Fatalf("Acquire() after expiry unexpected error: %v", err)
+			}
+			var held *lock.HeldError
+			if err := stale.Renew(ctx, time.Minute); !errors.As(err, &held) || held.Lock.Holder != "bob@laptop" {
+				t.Errorf("Renew() error = %v, want HeldError by bob@laptop", err)
+			}
+
+			// 原持有者释放时不会删除别人的锁
+			if err := stale.Release(ctx); err != nil {
+				t.Fatalf("Release() unexpected error: %v", err)
+			}
+			if got, _ := store.Load(ctx); got == nil || got.Holder != "bob@laptop" {
+				t.Errorf("Load() after stale release = %+v, want lock by bob@laptop", got)
+			}
+		})
+	}
+}
+
+func TestLease_RenewAndBreak(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	setClock(t, &now)
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			lease, err := lock.Acquire(ctx, store, "alice@ci", "auto", "prod", time.Minute)
+			if err != nil {
+				t.Fatalf("Acquire() unexpected error: %v", err)
+			}
+
+			now = now.Add(30 * time.Second)
+			if err := lease.Renew(ctx, 10*time.Minute); err != nil {
+				t.Fatalf("Renew() unexpected error: %v", err)
+			}
+			got, _ := store.Load(ctx)
+			if want := now.Add(10 * time.Minute); got == nil || !got.ExpiresAt.Equal(want) {
+				t.Errorf("ExpiresAt after renew = %+v, want %v", got, want)
+			}
+
+			broken, err := lock.Break(ctx, store)
+			if err != nil || broken == nil || broken.Holder != "alice@ci" {
+				t.Fatalf("Break() = %+v (err %v), want lock by alice@ci", broken, err)
+			}
+			if err := lease.Renew(ctx, time.Minute); !errors.Is(err, lock.ErrLost) {
+				t.Errorf("Renew() after break error = %v, want ErrLost", err)
+			}
+			if broken, err := lock.Break(ctx, store); err != nil || broken != nil {
+				t.Errorf("Break() without lock = %+v (err %v), want nil", broken, err)
+			}
+		})
+	}
+}
+
+func TestTagStore_SanitizesValues(t *testing.T) {
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+	client := aws.NewClientWithAPI(fake)
+	store := lock.NewTagStore(client, "demo")
+	lock.SetSettleDelay(0)
+	t.Cleanup(func() { lock.SetSettleDelay(-1) })
+
+	// 标签值不允许的字符被替换，写入不会被 Lambda 拒绝
+	ctx := context.Background()
+	if _, err := lock.Acquire(ctx, store, "李雷 <ci#1>", "auto", "prod", time.Minute); err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+	tags, err := client.FunctionTags(ctx, "demo")
+	if err != nil {
+		t.Fatalf("FunctionTags() unexpected error: %v", err)
+	}
+	if got := tags["lad:lock:holder"]; got != "李雷 _ci_1_" {
+		t.Errorf("holder tag = %q, want %q", got, "李雷 _ci_1_")
+	}
+}
@@ -0,0 +1,58 @@
+package patcher_test
+
+import (
+	"testing"
+
+	"github.com/aura-studio/lad/internal/patcher"
+)
+
+func TestParseAliasTargets(t *testing.T) {
+	content := `Resources:
+  Function:
+    Type: AWS::Serverless::Function
+` + patcher.GeneratePatchContent("Api") + `
+  PinnedAlias:
+    Type: AWS::Lambda::Alias
+    Properties:
+      FunctionName: !Ref Api
+      FunctionVersion: "3" # 固定版本
+      Name: pinned
+
+  ListAlias:
+    Type: AWS::Lambda::Alias
+    Properties:
+      Name: list
+      FunctionVersion:
+        Fn::GetAtt:
+          - ApiVersion
+          - Version
+`
+
+	targets := patcher.ParseAliasTargets(content)
+	want := []struct {
+		resource, name, version string
+		follows                 bool
+	}{
+		{"LiveAlias", "live", "!GetAtt ApiVersion.Version", true},
+		{"PreviousAlias", "previous", "!GetAtt ApiVersion.Version", true},
+		{"LatestAlias", "latest", "!GetAtt ApiVersion.Version", true},
+		{"PinnedAlias", "pinned", "3", false},
+		{"ListAlias", "list", "Fn::GetAtt: - ApiVersion - Version", true},
+	}
+	if len(targets) != len(want) {
+		t.Fatalf("ParseAliasTargets() returned %d targets, want %d: %+v", len(targets), len(want), targets)
+	}
+	for i, w := range want {
+		got := targets[i]
+		if got.Resource != w.resource || got.Name != w.name || got.FunctionVersion != w.version {
+			t.Errorf("targets[%d] = %+v, want {%s %s %s}", i, got, w.resource, w.name, w.version)
+		}
+		if got.FollowsNewVersion() != w.follows {
+			t.Errorf("targets[%d].FollowsNewVersion() = %v, want %v", i, got.FollowsNewVersion(), w.follows)
+		}
+	}
+
+	if targets := patcher.ParseAliasTargets("Resources:\n  Function:\n    Type: AWS::Serverless::Function\n"); len(targets) != 0 {
+		t.Errorf("ParseAliasTargets() without aliases = %+v, want none", targets)
+	}
+}
@@ -0,0 +1,102 @@
+package releases_test
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/releases"
+)
+
+func TestHistory_Push(t *testing.T) {
+	h := releases.History{"3", "2", "1"}
+	if got := h.Push("4"); !reflect.DeepEqual(got, releases.History{"4", "3", "2", "1"}) {
+		t.Errorf("Push(4) = %v, want [4 3 2 1]", got)
+	}
+	// 重新发布历史中的版本时移到最前
+	if got := h.Push("2"); !reflect.DeepEqual(got, releases.History{"2", "3", "1"}) {
+		t.Errorf("Push(2) = %v, want [2 3 1]", got)
+	}
+
+	// 最多保存 MaxVersions 个版本
+	var long releases.History
+	for i := 1; i <= releases.MaxVersions+5; i++ {
+		long = long.Push(strconv.Itoa(i))
+	}
+	if len(long) != releases.MaxVersions || long[0] != strconv.Itoa(releases.MaxVersions+5) {
+		t.Errorf("len = %d, first = %s, want %d versions starting with the latest", len(long), long[0], releases.MaxVersions)
+	}
+}
+
+func TestHistory_Chain(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  releases.History
+		live     string
+		previous string
+		want     releases.History
+	}{
+		{name: "live at head", history: releases.History{"4", "3", "2"}, live: "4", previous: "3", want: releases.History{"4", "3", "2"}},
+		{name: "live in the middle", history: releases.History{"4", "3", "2"}, live: "3", previous: "3", want: releases.History{"3", "2"}},
+		{name: "live changed outside lad", history: releases.History{"4", "3"}, live: "7", previous: "4", want: releases.History{"7", "4", "3"}},
+		{name: "no history uses previous", history: nil, live: "4", previous: "3", want: releases.History{"4", "3"}},
+		{name: "no history and rolled back", history: nil, live: "4", previous: "4", want: releases.History{"4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.history.Chain(tt.live, tt.previous); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Chain(%s, %s) = %v, want %v", tt.live, tt.previous, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistory_StepAndRollbackTo(t *testing.T) {
+	chain := releases.History{"5", "4", "3", "2"}
+
+	if got, err := chain.Step(2); err != nil || got != "3" {
+		t.Errorf("Step(2) = %q (err %v), want 3", got, err)
+	}
+	for _, steps := range []int{0, 4} {
+		if _, err := chain.Step(steps); err == nil {
+			t.Errorf("Step(%d) expected error", steps)
+		}
+	}
+
+	remaining := chain.RollbackTo("3")
+	if !reflect.DeepEqual(remaining, releases.History{"3", "2"}) || remaining.Previous() != "2" {
+		t.Errorf("RollbackTo(3) = %v (previous %s), want [3 2] with previous 2", remaining, remaining.Previous())
+	}
+	if last := chain.RollbackTo("2"); last.Previous() != "2" {
+		t.Errorf("RollbackTo(2).Previous() = %s, want 2", last.Previous())
+	}
+	if got := chain.RollbackTo("9"); !reflect.DeepEqual(got, releases.History{"9"}) {
+		t.Errorf("RollbackTo(9) = %v, want [9]", got)
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	fake := aws.NewFakeLambda()
+	fake.Deploy("demo")
+	client := aws.NewClientWithAPI(fake)
+	ctx := context.Background()
+
+	h, err := releases.Load(ctx, client, "demo")
+	if err != nil || len(h) != 0 {
+		t.Fatalf("Load() without history = %v (err %v), want empty", h, err)
+	}
+	if err := releases.Save(ctx, client, "demo", releases.History{"3", "2", "1"}); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	tags, _ := client.FunctionTags(ctx, "demo")
+	if got := tags[releases.TagKey]; got != "3 2 1" {
+		t.Errorf("tag %s = %q, want %q", releases.TagKey, got, "3 2 1")
+	}
+	if h, err := releases.Load(ctx, client, "demo"); err != nil || strings.Join(h, ",") != "3,2,1" {
+		t.Errorf("Load() = %v (err %v), want [3 2 1]", h, err)
+	}
+}
@@ -0,0 +1,175 @@
+package state_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aura-studio/lad/internal/aws"
+	"github.com/aura-studio/lad/internal/state"
+)
+
+// alias 创建别名状态，weights 为灰度版本及权重
+func alias(name, version string, weights map[string]float64) *aws.AliasState {
+	return &aws.AliasState{Name: name, Version: version, Weights: weights}
+}
+
+func TestCompute_State(t *testing.T) {
+	tests := []struct {
+		name                   string
+		live, previous, latest string
+		routing                map[string]float64
+		want                   state.State
+	}{
+		{"stable", "2", "1", "2", nil, state.Stable},
+		{"pending", "2", "1", "3", nil, state.Pending},
+		{"canary", "2", "1", "3", map[string]float64{"3": 0.1}, state.Canary},
+		{"rolled back", "1", "1", "1", nil, state.RolledBack},
+		{"first deploy pending", "1", "1", "2", nil, state.Pending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := state.Compute(alias("live", tt.live, tt.routing), alias("previous", tt.previous, nil), alias("latest", tt.latest, nil))
+			if snapshot.State != tt.want {
+				t.Errorf("State = %q, want %q", snapshot.State, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute_WithoutPrevious(t *testing.T) {
+	// 没有 previous 时无法识别回退态
+	snapshot := state.Compute(alias("live", "1", nil), nil, alias("latest", "1", nil))
+	if snapshot.State != state.Stable {
+		t.Errorf("State = %q, want %q", snapshot.State, state.Stable)
+	}
+	if len(snapshot.Anomalies) != 0 {
+		t.Errorf("Anomalies = %v, want none", snapshot.Anomalies)
+	}
+}
+
+func TestCompute_Anomalies(t *testing.T) {
+	tests := []struct {
+		name     string
+		live     *aws.AliasState
+		previous *aws.AliasState
+		latest   *aws.AliasState
+		want     string // 期望异常包含的内容，为空表示没有异常
+	}{
+		{
+			name:     "lad canary",
+			live:     alias("live", "2", map[string]float64{"3": 0.1}),
+			previous: alias("previous", "1", nil),
+			latest:   alias("latest", "3", nil),
+		},
+		{
+			name:     "canary not latest",
+			live:     alias("live", "2", map[string]float64{"4": 0.1}),
+			previous: alias("previous", "1", nil),
+			latest:   alias("latest", "3", nil),
+			want:     "灰度版本 4 不是 latest 版本 3",
+		},
+		{
+			name:     "multiple routed versions",
+			live:     alias("live", "2", map[string]float64{"3": 0.1, "4": 0.1}),
+			previous: alias("previous", "1", nil),
+			latest:   alias("latest", "3", nil),
+			want:     "多个灰度版本",
+		},
+		{
+			name:     "canary with live equal to previous",
+			live:     alias("live", "2", map[string]float64{"3": 0.1}),
+			previous: alias("previous", "2", nil),
+			latest:   alias("latest", "3", nil),
+			want:     "live 与 previous 都指向版本 2",
+		},
+		{
+			name:     "latest older than live",
+			live:     alias("live", "3", nil),
+			previous: alias("previous", "1", nil),
+			latest:   alias("latest", "2", nil),
+			want:     "latest 版本 2 早于 live 版本 3",
+		},
+		{
+			name:     "routing on previous",
+			live:     alias("live", "2", nil),
+			previous: alias("previous", "1", map[string]float64{"2": 0.5}),
+			latest:   alias("latest", "2", nil),
+			want:     "previous 别名配置了流量路由",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := state.Compute(tt.live, tt.previous, tt.latest)
+			if tt.want == "" {
+				if len(snapshot.Anomalies) != 0 {
+					t.Errorf("Anomalies = %v, want none", snapshot.Anomalies)
+				}
+				return
+			}
+			found := false
+			for _, anomaly := range snapshot.Anomalies {
+				if strings.Contains(anomaly, tt.want) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Anomalies = %v, want one containing %q", snapshot.Anomalies, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck_TransitionTable(t *testing.T) {
+	tests := []struct {
+		command state.Command
+		from    state.State
+		outcome state.Outcome
+		next    state.State
+	}{
+		{state.Deploy, state.Stable, state.Allowed, state.Pending},
+		{state.Deploy, state.Pending, state.Blocked, ""},
+		{state.Deploy, state.Canary, state.Blocked, ""},
+		{state.Deploy, state.RolledBack, state.Allowed, state.Pending},
+		{state.StartCanary, state.Stable, state.NoOp, ""},
+		{state.StartCanary, state.Pending, state.Allowed, state.Canary},
+		{state.StartCanary, state.Canary, state.Allowed, state.Canary},
+		{state.ClearCanary, state.Pending, state.NoOp, ""},
+		{state.ClearCanary, state.Canary, state.Allowed, state.Pending},
+		{state.Auto, state.Pending, state.Allowed, state.Stable},
+		{state.Promote, state.Stable, state.NoOp, ""},
+		{state.Promote, state.Canary, state.Allowed, state.Stable},
+		{state.Rollback, state.Stable, state.Allowed, ""},
+		{state.Rollback, state.RolledBack, state.Blocked, ""},
+		{state.RollbackTo, state.RolledBack, state.Allowed, ""},
+		{state.Abort, state.Stable, state.NoOp, ""},
+		{state.Abort, state.Canary, state.Allowed, state.Stable},
+		{state.Switch, state.RolledBack, state.Allowed, ""},
+		{state.Command("unknown"), state.Stable, state.Blocked, ""},
+	}
+	for _, tt := range tests {
+		rule := state.Check(tt.command, tt.from)
+		if rule.Outcome != tt.outcome || rule.Next != tt.next {
+			t.Errorf("Check(%s, %s) = (%v, %q), want (%v, %q)", tt.command, tt.from, rule.Outcome, rule.Next, tt.outcome, tt.next)
+		}
+		if rule.Outcome != state.Allowed && rule.Reason == "" {
+			t.Errorf("Check(%s, %s) should explain why it is not allowed", tt.command, tt.from)
+		}
+	}
+}
+
+func TestSnapshotCheck(t *testing.T) {
+	// 灰度路由到非 latest 版本且 live == latest：没有新版本可以发布，但可以取消
+	snapshot := state.Compute(alias("live", "3", map[string]float64{"2": 0.1}), alias("previous", "1", nil), alias("latest", "3", nil))
+	if rule := snapshot.Check(state.Promote); rule.Outcome != state.NoOp {
+		t.Errorf("Check(promote) = %v, want NoOp", rule.Outcome)
+	}
+	if rule := snapshot.Check(state.Abort); rule.Outcome != state.Allowed {
+		t.Errorf("Check(abort) = %v, want Allowed", rule.Outcome)
+	}
+
+	// 首次发布的灰度：live == previous，没有可回退的历史版本
+	snapshot = state.Compute(alias("live", "1", map[string]float64{"2": 0.1}), alias("previous", "1", nil), alias("latest", "2", nil))
+	if rule := snapshot.Check(state.Rollback); rule.Outcome != state.Blocked {
+		t.Errorf("Check(rollback) = %v, want Blocked", rule.Outcome)
+	}
+}
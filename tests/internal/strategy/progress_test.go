@@ -0,0 +1,79 @@
+package strategy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/strategy"
+)
+
+func TestProgress_RoundTrip(t *testing.T) {
+	started := time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC)
+	progress := &strategy.Progress{
+		Strategy: "1%:10m,5%:10m,25%:30m,50%:1h",
+		Step:     3,
+		Steps:    4,
+		Version:  "12",
+		Started:  started,
+		Operator: "alice smith",
+	}
+	description := progress.Description()
+	if len(description) > strategy.MaxDescription {
+		t.Errorf("description length = %d, want <= %d", len(description), strategy.MaxDescription)
+	}
+
+	got, ok := strategy.ParseProgress(description)
+	if !ok {
+		t.Fatalf("ParseProgress(%q) failed", description)
+	}
+	if *got != *progress {
+		t.Errorf("ParseProgress() = %+v, want %+v", *got, *progress)
+	}
+}
+
+func TestProgress_Control(t *testing.T) {
+	progress := &strategy.Progress{
+		Strategy: "linear:10%:5m0s",
+		Step:     2,
+		Steps:    9,
+		Version:  "3",
+		Started:  time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC),
+		Operator: "bob",
+	}
+	for _, control := range []string{strategy.ControlPause, strategy.ControlAbort, ""} {
+		progress.Control = control
+		got, ok := strategy.ParseProgress(progress.Description())
+		if !ok || got.Control != control || got.Operator != "bob" {
+			t.Errorf("ParseProgress(%q) = %+v, %v, want control %q", progress.Description(), got, ok, control)
+		}
+	}
+}
+
+func TestParseProgress_Invalid(t *testing.T) {
+	for _, description := range []string{
+		"",
+		"managed by sam",
+		"lad rejected: 3 (error rate)",
+		"lad auto: strategy=linear:10%:5m0s step=3 version=2 started=2026-10-16T08:30:00Z operator=bob",
+		"lad auto: strategy=linear:10%:5m0s step=10/9 version=2 started=2026-10-16T08:30:00Z operator=bob",
+		"lad auto: strategy=linear:10%:5m0s step=1/9 version=2 started=yesterday operator=bob",
+		"lad auto: step=1/9 version=2 started=2026-10-16T08:30:00Z operator=bob",
+		"lad auto: strategy=linear:10%:5m0s step=1/9 version=2 started=2026-10-16T08:30:00Z control=stop operator=bob",
+	} {
+		if _, ok := strategy.ParseProgress(description); ok {
+			t.Errorf("ParseProgress(%q) should fail", description)
+		}
+	}
+}
+
+func TestProgress_Remaining(t *testing.T) {
+	started := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	progress := &strategy.Progress{Started: started}
+
+	if got := progress.Remaining(30*time.Minute, started.Add(10*time.Minute)); got != 20*time.Minute {
+		t.Errorf("Remaining() = %v, want 20m", got)
+	}
+	if got := progress.Remaining(30*time.Minute, started.Add(time.Hour)); got != 0 {
+		t.Errorf("Remaining() after the wait = %v, want 0", got)
+	}
+}
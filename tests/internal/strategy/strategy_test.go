@@ -0,0 +1,116 @@
+package strategy_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aura-studio/lad/internal/strategy"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []strategy.Step
+	}{
+		{"1%:10m,5%:10m,25%:30m,50%:1h", []strategy.Step{
+			{Percent: 1, Wait: 10 * time.Minute},
+			{Percent: 5, Wait: 10 * time.Minute},
+			{Percent: 25, Wait: 30 * time.Minute},
+			{Percent: 50, Wait: time.Hour},
+		}},
+		{"10%:5m, 50%:0s, 100%", []strategy.Step{
+			{Percent: 10, Wait: 5 * time.Minute},
+			{Percent: 50, Wait: 0},
+		}},
+		{"linear:25%:1h", []strategy.Step{
+			{Percent: 25, Wait: time.Hour},
+			{Percent: 50, Wait: time.Hour},
+			{Percent: 75, Wait: time.Hour},
+		}},
+		{"exponential:5%:10m", []strategy.Step{
+			{Percent: 5, Wait: 10 * time.Minute},
+			{Percent: 10, Wait: 10 * time.Minute},
+			{Percent: 20, Wait: 10 * time.Minute},
+			{Percent: 40, Wait: 10 * time.Minute},
+			{Percent: 80, Wait: 10 * time.Minute},
+		}},
+		{"exponential:1%:1m:1.5", []strategy.Step{
+			{Percent: 1, Wait: time.Minute},
+			{Percent: 2, Wait: time.Minute},
+			{Percent: 3, Wait: time.Minute},
+			{Percent: 5, Wait: time.Minute},
+			{Percent: 8, Wait: time.Minute},
+			{Percent: 11, Wait: time.Minute},
+			{Percent: 17, Wait: time.Minute},
+			{Percent: 26, Wait: time.Minute},
+			{Percent: 38, Wait: time.Minute},
+			{Percent: 58, Wait: time.Minute},
+			{Percent: 86, Wait: time.Minute},
+		}},
+		{"Canary10Percent5Minutes", []strategy.Step{{Percent: 10, Wait: 5 * time.Minute}}},
+		{"LambdaCanary10Percent30Minutes", []strategy.Step{{Percent: 10, Wait: 30 * time.Minute}}},
+		{"Linear20PercentEvery2Minutes", []strategy.Step{
+			{Percent: 20, Wait: 2 * time.Minute},
+			{Percent: 40, Wait: 2 * time.Minute},
+			{Percent: 60, Wait: 2 * time.Minute},
+			{Percent: 80, Wait: 2 * time.Minute},
+		}},
+		{"lambdalinear50percentevery1minute", []strategy.Step{{Percent: 50, Wait: time.Minute}}},
+		{"AllAtOnce", nil},
+	}
+	for _, tt := range tests {
+		plan, err := strategy.Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q) unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(plan.Steps, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.spec, plan.Steps, tt.want)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"10%",                 // 缺少等待时间
+		"10:5m",               // 缺少 %
+		"10%:5m,5%:5m",        // 不递增
+		"10%:5m,100%,50%:5m",  // 100% 不是最后一步
+		"10%:5m,100%:1m",      // 100% 指定了等待时间
+		"0%:5m",               // 超出范围
+		"10%:soon",            // 等待时间无效
+		"10%:-5m",             // 等待时间为负数
+		"linear:10%",          // 缺少等待时间
+		"exponential:1%:5m:1", // 倍数必须大于 1
+		"exponential:100%:5m", // 起始比例超出范围
+		"Canary100Percent5Minutes",
+		"Linear0PercentEvery1Minute",
+	} {
+		if _, err := strategy.Parse(spec); err == nil {
+			t.Errorf("Parse(%q) expected error", spec)
+		}
+	}
+}
+
+func TestLinear(t *testing.T) {
+	plan, err := strategy.Linear(30, time.Minute)
+	if err != nil {
+		t.Fatalf("Linear() unexpected error: %v", err)
+	}
+	if got := plan.Percents(); !reflect.DeepEqual(got, []int{30, 60, 90}) {
+		t.Errorf("Percents() = %v, want [30 60 90]", got)
+	}
+	if got := plan.Duration(); got != 3*time.Minute {
+		t.Errorf("Duration() = %v, want 3m", got)
+	}
+
+	// 100% 直接 promote
+	if plan, err := strategy.Linear(100, time.Minute); err != nil || len(plan.Steps) != 0 {
+		t.Errorf("Linear(100) = %+v, %v, want no steps", plan, err)
+	}
+	if _, err := strategy.Linear(0, time.Minute); err == nil {
+		t.Error("Linear(0) expected error")
+	}
+}